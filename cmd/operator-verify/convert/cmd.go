@@ -0,0 +1,48 @@
+package convert
+
+import (
+	"fmt"
+
+	"github.com/operator-framework/api/pkg/manifests"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func NewCmd() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "convert",
+		Short: "Converts a package manifest directory to bundle directories",
+		Long: `'operator-verify convert' converts the package manifest directory supplied
+into registry+v1 bundle directories, one per CSV, written to the --output-dir
+directory along with the bundle.Dockerfile building their bundle image.
+
+The channels of each bundle are the channels of the package manifest whose
+current CSV is, or replaces or skips, directly or not, the CSV of the bundle.
+The default channel of the package manifest is the default channel of every
+bundle.`,
+		Args:         cobra.ExactArgs(1),
+		RunE:         convertFunc,
+		SilenceUsage: true,
+	}
+
+	rootCmd.Flags().String("output-dir", "bundles", "directory the bundle directories are written to")
+
+	return rootCmd
+}
+
+func convertFunc(cmd *cobra.Command, args []string) error {
+	outputDir, err := cmd.Flags().GetString("output-dir")
+	if err != nil {
+		log.Fatalf("Unable to parse output-dir parameter: %v", err)
+	}
+
+	dirs, err := manifests.ConvertPackageManifestDir(args[0], outputDir)
+	for _, dir := range dirs {
+		fmt.Fprintln(cmd.OutOrStdout(), dir)
+	}
+	if err != nil {
+		return fmt.Errorf("error converting package manifest directory: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package fix
+
+import (
+	"fmt"
+
+	"github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/validation"
+	"github.com/operator-framework/api/pkg/validation/fix"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func NewCmd() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "fix",
+		Short: "Fixes the manifests of a bundle directory",
+		Long: `'operator-verify fix' validates a bundle in the supplied directory with
+the default and good practices validators, and applies the fixes attached to
+their findings to the manifests of the directory:
+  - annotations with a wrong case are renamed;
+  - the properties of olm.properties annotations are moved to
+    metadata/properties.yaml;
+  - the images of the deployments missing from spec.relatedImages are added;
+  - missing resource requests are set;
+  - empty descriptions of owned CRDs are set from their schema.
+The diff of each changed manifest is printed.
+
+Findings without a fix are left to be resolved by hand, and can be listed with
+'operator-verify manifests'. Channel names not following the naming convention
+are not fixed: renaming the channel of a published package breaks the
+subscriptions to it, and the new name is a choice of its authors.`,
+		Args:         cobra.ExactArgs(1),
+		RunE:         fixFunc,
+		SilenceUsage: true,
+	}
+
+	rootCmd.Flags().Bool("dry-run", false, "print the diff of the fixes without writing them")
+
+	return rootCmd
+}
+
+func fixFunc(cmd *cobra.Command, args []string) error {
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		log.Fatalf("Unable to parse dry-run parameter: %v", err)
+	}
+
+	bundle, err := manifests.GetBundleFromDir(args[0])
+	if err != nil {
+		return fmt.Errorf("error generating bundle from directory: %v", err)
+	}
+
+	validators := validation.DefaultBundleValidators.WithValidators(validation.GoodPracticesValidator)
+	fixes := fix.Fixes(validators.ValidateContext(cmd.Context(), bundle.ObjectsToValidate()...))
+	if err := cmd.Context().Err(); err != nil {
+		return fmt.Errorf("validation did not complete: %v", err)
+	}
+
+	changes, planErr := fix.Plan(args[0], fixes)
+	for _, change := range changes {
+		fmt.Fprint(cmd.OutOrStdout(), change.Diff())
+		for _, f := range change.Fixes {
+			log.Infof("%s: %s", change.Path, f.Description)
+		}
+	}
+	if planErr != nil {
+		log.Errorf("Some fixes were not applied: %v", planErr)
+	}
+	if len(changes) == 0 {
+		log.Info("No fixes to apply")
+	} else if !dryRun {
+		if err := fix.Write(changes); err != nil {
+			return fmt.Errorf("error writing fixes: %v", err)
+		}
+	}
+	return planErr
+}
@@ -1,24 +1,87 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 
+	"github.com/operator-framework/api/cmd/operator-verify/convert"
+	"github.com/operator-framework/api/cmd/operator-verify/fix"
 	manifests "github.com/operator-framework/api/cmd/operator-verify/manifests"
 
 	"github.com/spf13/cobra"
 )
 
 func main() {
+	os.Exit(run())
+}
+
+// run executes the command and returns the exit code of the process.
+func run() int {
 	rootCmd := &cobra.Command{
 		Use:   "operator-verify",
 		Short: "Operator manifest validation tool",
 		Long:  `operator-verify is a CLI tool that calls functions in pkg/validation.`,
+		// Errors are printed to stderr by run, keeping the output of the
+		// commands on stdout parseable, and run also handles the exit code.
+		SilenceErrors: true,
 	}
 
+	// Interrupting the process cancels the validation in progress.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	rootCmd.AddCommand(manifests.NewCmd())
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	rootCmd.AddCommand(fix.NewCmd())
+	rootCmd.AddCommand(convert.NewCmd())
+	markCommandErrors(rootCmd)
+
+	err := rootCmd.ExecuteContext(ctx)
+	var exitErr *manifests.ExitError
+	var cmdErr commandError
+	switch {
+	case err == nil:
+		return manifests.ExitCodeSuccess
+	case errors.As(err, &exitErr):
+		if exitErr.Err != nil {
+			fmt.Fprintln(os.Stderr, exitErr.Err)
+		}
+		return exitErr.Code
+	case errors.As(err, &cmdErr):
+		fmt.Fprintln(os.Stderr, err)
+		return manifests.ExitCodeErrors
+	default:
+		// cobra returns the errors of the flags, the arguments and the
+		// subcommand names before running a command.
+		fmt.Fprintln(os.Stderr, err)
+		return manifests.ExitCodeUsage
+	}
+}
+
+// commandError is an error returned by a command once it runs, as opposed to
+// an error of its command line.
+type commandError struct {
+	error
+}
+
+func (e commandError) Unwrap() error {
+	return e.error
+}
+
+// markCommandErrors wraps the errors returned by cmd and its subcommands in
+// commandError.
+func markCommandErrors(cmd *cobra.Command) {
+	if runE := cmd.RunE; runE != nil {
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			if err := runE(cmd, args); err != nil {
+				return commandError{err}
+			}
+			return nil
+		}
+	}
+	for _, c := range cmd.Commands() {
+		markCommandErrors(c)
 	}
 }
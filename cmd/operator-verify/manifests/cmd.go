@@ -1,9 +1,16 @@
 package manifests
 
 import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/operator-framework/api/pkg/manifests"
 	"github.com/operator-framework/api/pkg/validation"
-	"github.com/operator-framework/api/pkg/validation/errors"
+	"github.com/operator-framework/api/pkg/validation/config"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -13,63 +20,166 @@ func NewCmd() *cobra.Command {
 	rootCmd := &cobra.Command{
 		Use:   "manifests",
 		Short: "Validates all manifests in a directory",
-		Long: `'operator-verify manifests' validates a bundle in the supplied directory
-and prints errors and warnings corresponding to each manifest found to be
+		Long: `'operator-verify manifests' validates a bundle in the supplied directory,
+or .tar or .tar.gz archive, or the bundle image of an OCI image layout
+directory or docker save archive, and prints errors and warnings corresponding to each manifest found to be
 invalid. Manifests are only validated if a validator for that manifest
 type/kind, ex. CustomResourceDefinition, is implemented in the Operator
-validation library.`,
-		Run: manifestsFunc,
+validation library.
+
+The command exits with one of the following codes:
+  0: no findings at or above the --fail-on level
+  1: errors were found
+  2: only warnings were found and --fail-on=warning
+  3: the bundle could not be loaded
+  4: validation was interrupted or exceeded --timeout
+  64: the command line or the --config file is invalid`,
+		Args:         cobra.ExactArgs(1),
+		RunE:         manifestsFunc,
+		SilenceUsage: true,
 	}
 
 	rootCmd.Flags().Bool("operatorhub_validate", false, "enable optional UI validation for operatorhub.io")
 	rootCmd.Flags().Bool("object_validate", false, "enable optional bundle object validation")
+	rootCmd.Flags().StringSlice("select", []string{"default"},
+		fmt.Sprintf("comma-separated validators or groups of validators to run, from: %s", strings.Join(selectionNames(), ", ")))
+	rootCmd.Flags().StringToString("optional-values", nil,
+		fmt.Sprintf("optional key=value pairs passed to the selected validators, e.g. k8s-version=1.22,container-tools=podman, with keys from: %s",
+			strings.Join(validation.OptionKeys(), ", ")))
+	rootCmd.Flags().String("config", "",
+		"path to a validation config file suppressing findings or overriding their level")
+	rootCmd.Flags().Duration("timeout", 0, "maximum duration of the validation, e.g. 5m, unlimited if 0")
+	rootCmd.Flags().StringP("output", "o", outputText,
+		fmt.Sprintf("output format for the validation results, one of: %s", strings.Join(outputFormats, ", ")))
+	rootCmd.Flags().String("fail-on", failOnError,
+		fmt.Sprintf("lowest severity of the findings which result in a non-zero exit code, one of: %s", strings.Join(failOnLevels, ", ")))
 
 	return rootCmd
 }
 
-func manifestsFunc(cmd *cobra.Command, args []string) {
-	bundle, err := manifests.GetBundleFromDir(args[0])
+// loadBundle loads the bundle in the directory or tar archive at path, which
+// may also be an OCI image layout or docker save archive of a bundle image.
+func loadBundle(path string) (*manifests.Bundle, error) {
+	info, err := os.Stat(path)
 	if err != nil {
-		log.Fatalf("Error generating bundle from directory: %s", err.Error())
+		return nil, err
 	}
-	if bundle == nil {
-		log.Fatalf("Error generating bundle from directory")
+	if info.IsDir() {
+		if _, err := os.Stat(filepath.Join(path, "oci-layout")); err == nil {
+			return manifests.GetBundleFromOCILayout(path)
+		}
+		return manifests.GetBundleFromDir(path)
+	}
+	fsys, err := manifests.TarFileFS(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fs.Stat(fsys, "manifest.json"); err == nil {
+		img, err := manifests.DockerArchiveImage(fsys)
+		if err != nil {
+			return nil, err
+		}
+		return manifests.GetBundleFromImage(img)
+	}
+	return manifests.GetBundleFromFS(fsys)
+}
+
+func manifestsFunc(cmd *cobra.Command, args []string) error {
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return UsageError(fmt.Errorf("unable to parse output parameter: %v", err))
+	}
+	if !isOutputFormat(output) {
+		return UsageError(fmt.Errorf("invalid output format %q, must be one of: %s", output, strings.Join(outputFormats, ", ")))
+	}
+
+	failOn, err := cmd.Flags().GetString("fail-on")
+	if err != nil {
+		return UsageError(fmt.Errorf("unable to parse fail-on parameter: %v", err))
+	}
+	if err := validateFailOn(failOn); err != nil {
+		return UsageError(err)
 	}
 
 	operatorHubValidate, err := cmd.Flags().GetBool("operatorhub_validate")
 	if err != nil {
-		log.Fatalf("Unable to parse operatorhub_validate parameter")
+		return UsageError(fmt.Errorf("unable to parse operatorhub_validate parameter: %v", err))
 	}
 
 	bundleObjectValidate, err := cmd.Flags().GetBool("object_validate")
 	if err != nil {
-		log.Fatalf("Unable to parse object_validate parameter: %v", err)
+		return UsageError(fmt.Errorf("unable to parse object_validate parameter: %v", err))
 	}
 
-	validators := validation.DefaultBundleValidators
+	selection, err := cmd.Flags().GetStringSlice("select")
+	if err != nil {
+		return UsageError(fmt.Errorf("unable to parse select parameter: %v", err))
+	}
 	if operatorHubValidate {
-		validators = validators.WithValidators(validation.OperatorHubValidator)
+		selection = append(selection, "operatorhub")
 	}
 	if bundleObjectValidate {
-		validators = validators.WithValidators(validation.ObjectValidator)
+		selection = append(selection, "object")
+	}
+	validators, err := selectValidators(selection)
+	if err != nil {
+		return UsageError(err)
 	}
 
-	results := validators.Validate(bundle.ObjectsToValidate()...)
-	nonEmptyResults := []errors.ManifestResult{}
-	for _, result := range results {
-		if result.HasError() || result.HasWarn() {
-			nonEmptyResults = append(nonEmptyResults, result)
-		}
+	optionalValues, err := cmd.Flags().GetStringToString("optional-values")
+	if err != nil {
+		return UsageError(fmt.Errorf("unable to parse optional-values parameter: %v", err))
+	}
+	options, err := validation.ParseOptions(optionalValues)
+	if err != nil {
+		return UsageError(fmt.Errorf("invalid --optional-values: %v", err))
 	}
 
-	for _, result := range nonEmptyResults {
-		for _, err := range result.Errors {
-			log.Error(err.Error())
-		}
-		for _, err := range result.Warnings {
-			log.Warn(err.Error())
+	configPath, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return UsageError(fmt.Errorf("unable to parse config parameter: %v", err))
+	}
+	var cfg *config.Config
+	if configPath != "" {
+		if cfg, err = config.Load(configPath); err != nil {
+			return UsageError(err)
 		}
 	}
 
-	return
+	bundle, err := loadBundle(args[0])
+	if err != nil {
+		log.Errorf("Error generating bundle from directory: %s", err.Error())
+		return &ExitError{Code: ExitCodeLoaderFailure}
+	}
+	if bundle == nil {
+		log.Errorf("Error generating bundle from directory")
+		return &ExitError{Code: ExitCodeLoaderFailure}
+	}
+
+	objs := append(bundle.ObjectsToValidate(), options)
+
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return UsageError(fmt.Errorf("unable to parse timeout parameter: %v", err))
+	}
+	ctx := cmd.Context()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	results, suppressed := cfg.Apply(validators.ValidateContext(ctx, objs...))
+	if err := writeOutput(cmd.OutOrStdout(), output, results, suppressed, newManifestIndex(args[0], bundle)); err != nil {
+		return &ExitError{Code: ExitCodeErrors, Err: fmt.Errorf("error writing validation results: %v", err)}
+	}
+
+	if err := ctx.Err(); err != nil {
+		log.Errorf("Validation did not complete: %v", err)
+		return &ExitError{Code: ExitCodeInterrupted}
+	}
+	if code := exitCode(results, failOn); code != ExitCodeSuccess {
+		return &ExitError{Code: code}
+	}
+	return nil
 }
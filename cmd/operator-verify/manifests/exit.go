@@ -0,0 +1,88 @@
+package manifests
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/validation/errors"
+)
+
+// Exit codes of 'operator-verify manifests'.
+const (
+	// ExitCodeSuccess is returned when no findings reach the --fail-on threshold.
+	ExitCodeSuccess = 0
+	// ExitCodeErrors is returned when errors were found.
+	ExitCodeErrors = 1
+	// ExitCodeWarnings is returned when only warnings were found and --fail-on=warning.
+	ExitCodeWarnings = 2
+	// ExitCodeLoaderFailure is returned when the bundle could not be loaded.
+	ExitCodeLoaderFailure = 3
+	// ExitCodeInterrupted is returned when validation was cancelled or timed out.
+	ExitCodeInterrupted = 4
+	// ExitCodeUsage is returned when the command line or the validation config
+	// is invalid, like EX_USAGE of sysexits.h.
+	ExitCodeUsage = 64
+)
+
+// Supported values for the --fail-on flag.
+const (
+	failOnError   = "error"
+	failOnWarning = "warning"
+	failOnNone    = "none"
+)
+
+var failOnLevels = []string{failOnError, failOnWarning, failOnNone}
+
+// ExitError is returned by the manifests command when the process should exit
+// with a specific code.
+type ExitError struct {
+	Code int
+	// Err is the error to report before exiting, if it was not already.
+	Err error
+}
+
+// Error implements the 'error' interface.
+func (e *ExitError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("exit code %d", e.Code)
+}
+
+// Unwrap returns the error to report.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// UsageError returns err as an ExitError with the ExitCodeUsage code.
+func UsageError(err error) error {
+	return &ExitError{Code: ExitCodeUsage, Err: err}
+}
+
+func validateFailOn(failOn string) error {
+	for _, l := range failOnLevels {
+		if l == failOn {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid --fail-on value %q, must be one of: %s", failOn, strings.Join(failOnLevels, ", "))
+}
+
+// exitCode returns the exit code for results given the --fail-on threshold.
+func exitCode(results []errors.ManifestResult, failOn string) int {
+	if failOn == failOnNone {
+		return ExitCodeSuccess
+	}
+
+	hasWarn := false
+	for _, result := range results {
+		if result.HasError() {
+			return ExitCodeErrors
+		}
+		hasWarn = hasWarn || result.HasWarn()
+	}
+	if hasWarn && failOn == failOnWarning {
+		return ExitCodeWarnings
+	}
+	return ExitCodeSuccess
+}
@@ -0,0 +1,94 @@
+package manifests
+
+import (
+	"context"
+	goerrors "errors"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/validation/errors"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitCode(t *testing.T) {
+	withErr := errors.ManifestResult{Name: "with-error"}
+	withErr.Add(errors.ErrInvalidCSV("install modes not found", "etcdoperator.v0.9.4"))
+	withWarn := errors.ManifestResult{Name: "with-warning"}
+	withWarn.Add(errors.WarnInvalidCSV("annotations not found", "etcdoperator.v0.9.4"))
+	empty := errors.ManifestResult{Name: "empty"}
+
+	tests := []struct {
+		name    string
+		results []errors.ManifestResult
+		failOn  string
+		want    int
+	}{
+		{"NoFindings/Error", []errors.ManifestResult{empty}, failOnError, ExitCodeSuccess},
+		{"NoFindings/Warning", []errors.ManifestResult{empty}, failOnWarning, ExitCodeSuccess},
+		{"Errors/Error", []errors.ManifestResult{withWarn, withErr}, failOnError, ExitCodeErrors},
+		{"Errors/Warning", []errors.ManifestResult{withWarn, withErr}, failOnWarning, ExitCodeErrors},
+		{"Errors/None", []errors.ManifestResult{withErr}, failOnNone, ExitCodeSuccess},
+		{"WarningsOnly/Error", []errors.ManifestResult{withWarn, empty}, failOnError, ExitCodeSuccess},
+		{"WarningsOnly/Warning", []errors.ManifestResult{withWarn, empty}, failOnWarning, ExitCodeWarnings},
+		{"WarningsOnly/None", []errors.ManifestResult{withWarn}, failOnNone, ExitCodeSuccess},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, exitCode(tt.results, tt.failOn))
+		})
+	}
+}
+
+func TestValidateFailOn(t *testing.T) {
+	for _, l := range failOnLevels {
+		require.NoError(t, validateFailOn(l))
+	}
+	require.EqualError(t, validateFailOn("info"), `invalid --fail-on value "info", must be one of: error, warning, none`)
+}
+
+func TestUsageErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"Output", []string{"--output", "xml"}},
+		{"FailOn", []string{"--fail-on", "info"}},
+		{"Select", []string{"--select", "unknown"}},
+		{"OptionalValues", []string{"--optional-values", "unknown=1"}},
+		{"Config", []string{"--config", filepath.Join(t.TempDir(), "missing.yaml")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewCmd()
+			cmd.SetArgs(append(tt.args, "./testdata/missing"))
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			err := cmd.ExecuteContext(context.Background())
+			var exitErr *ExitError
+			require.ErrorAs(t, err, &exitErr)
+			require.Equal(t, ExitCodeUsage, exitErr.Code)
+			require.Error(t, exitErr.Err)
+		})
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, goerrors.New("disk full")
+}
+
+func TestWriteOutputError(t *testing.T) {
+	cmd := NewCmd()
+	cmd.SetArgs([]string{"--output", "json", "../../../pkg/validation/internal/testdata/valid_bundle"})
+	cmd.SetOut(failingWriter{})
+	cmd.SetErr(io.Discard)
+	err := cmd.ExecuteContext(context.Background())
+	var exitErr *ExitError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, ExitCodeErrors, exitErr.Code)
+	require.EqualError(t, exitErr.Err, "error writing validation results: disk full")
+}
@@ -0,0 +1,472 @@
+package manifests
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/validation/config"
+	"github.com/operator-framework/api/pkg/validation/errors"
+
+	log "github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+)
+
+// Supported values for the --output flag.
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputSARIF = "sarif"
+	outputJUnit = "junit"
+)
+
+var outputFormats = []string{outputText, outputJSON, outputYAML, outputSARIF, outputJUnit}
+
+func isOutputFormat(format string) bool {
+	for _, f := range outputFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// report is the structured representation of a validation run which is
+// serialized by the json, yaml, sarif and junit output formats.
+type report struct {
+	Results    []reportResult     `json:"results"`
+	Suppressed []suppressedResult `json:"suppressed,omitempty"`
+}
+
+// reportResult mirrors errors.ManifestResult.
+type reportResult struct {
+	Name     string    `json:"name"`
+	Errors   []finding `json:"errors"`
+	Warnings []finding `json:"warnings"`
+}
+
+// finding mirrors errors.Error, adding the manifest file the finding
+// was reported against, when it is known.
+type finding struct {
+	Type     errors.ErrorType `json:"type"`
+	Code     errors.RuleCode  `json:"code,omitempty"`
+	Level    errors.Level     `json:"level"`
+	Field    string           `json:"field,omitempty"`
+	BadValue interface{}      `json:"badValue,omitempty"`
+	Detail   string           `json:"detail,omitempty"`
+	Manifest string           `json:"manifest,omitempty"`
+	Position *errors.Position `json:"position,omitempty"`
+	Fix      *errors.Fix      `json:"fix,omitempty"`
+	// message is the human readable form of the finding, as returned by errors.Error.Error().
+	message string
+}
+
+// suppressedResult holds the findings of a result removed by the validation config.
+type suppressedResult struct {
+	Name     string              `json:"name"`
+	Findings []suppressedFinding `json:"findings"`
+}
+
+// suppressedFinding is a finding removed by the validation config, along with
+// the justification of the suppression.
+type suppressedFinding struct {
+	finding       `json:",inline"`
+	Justification string `json:"justification"`
+}
+
+// newReport converts results and suppressed findings into a report, resolving
+// the manifest file of each result with files.
+func newReport(results []errors.ManifestResult, suppressed []config.SuppressedResult, files manifestIndex) report {
+	r := report{Results: make([]reportResult, 0, len(results))}
+	for _, result := range results {
+		manifest := files.lookup(result.Name)
+		rr := reportResult{
+			Name:     result.Name,
+			Errors:   make([]finding, 0, len(result.Errors)),
+			Warnings: make([]finding, 0, len(result.Warnings)),
+		}
+		for _, err := range result.Errors {
+			rr.Errors = append(rr.Errors, newFinding(err, manifest))
+		}
+		for _, err := range result.Warnings {
+			rr.Warnings = append(rr.Warnings, newFinding(err, manifest))
+		}
+		r.Results = append(r.Results, rr)
+	}
+	for _, result := range suppressed {
+		manifest := files.lookup(result.Name)
+		sr := suppressedResult{Name: result.Name, Findings: make([]suppressedFinding, 0, len(result.Findings))}
+		for _, f := range result.Findings {
+			sr.Findings = append(sr.Findings, suppressedFinding{
+				finding:       newFinding(f.Error, manifest),
+				Justification: f.Justification,
+			})
+		}
+		r.Suppressed = append(r.Suppressed, sr)
+	}
+	return r
+}
+
+func newFinding(err errors.Error, manifest string) finding {
+	if err.Position != nil {
+		manifest = err.Position.File
+	}
+	return finding{
+		Type:     err.Type,
+		Code:     err.Code,
+		Level:    err.Level,
+		Field:    err.Field,
+		BadValue: serializableValue(err.BadValue),
+		Detail:   err.Detail,
+		Manifest: manifest,
+		Position: err.Position,
+		Fix:      err.Fix,
+		message:  err.Error(),
+	}
+}
+
+// serializableValue returns v in a form that can be marshaled to JSON. Errors
+// are converted to their message and values that cannot be marshaled are
+// formatted with fmt.
+func serializableValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case error:
+		return t.Error()
+	case string:
+		if t == "" {
+			return nil
+		}
+		return t
+	}
+	if _, err := json.Marshal(v); err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return v
+}
+
+// writeOutput writes results, followed by the findings suppressed by the
+// validation config, to w in the given format.
+func writeOutput(w io.Writer, format string, results []errors.ManifestResult, suppressed []config.SuppressedResult, files manifestIndex) error {
+	switch format {
+	case outputText:
+		writeText(results, suppressed)
+		return nil
+	case outputJSON:
+		return writeJSON(w, newReport(results, suppressed, files))
+	case outputYAML:
+		return writeYAML(w, newReport(results, suppressed, files))
+	case outputSARIF:
+		return writeSARIF(w, newReport(results, suppressed, files))
+	case outputJUnit:
+		return writeJUnit(w, newReport(results, suppressed, files))
+	}
+	return fmt.Errorf("unsupported output format %q, must be one of: %s", format, strings.Join(outputFormats, ", "))
+}
+
+// writeText logs every error and warning found, then every suppressed finding.
+func writeText(results []errors.ManifestResult, suppressed []config.SuppressedResult) {
+	for _, result := range results {
+		for _, err := range result.Errors {
+			textEntry(err).Error(err.Error())
+		}
+		for _, err := range result.Warnings {
+			textEntry(err).Warn(err.Error())
+		}
+	}
+	for _, result := range suppressed {
+		for _, f := range result.Findings {
+			textEntry(f.Error).WithField("justification", f.Justification).Info("suppressed: " + f.Error.Error())
+		}
+	}
+}
+
+// textEntry returns a log entry carrying the rule code and position of err, if any.
+func textEntry(err errors.Error) *log.Entry {
+	entry := log.NewEntry(log.StandardLogger())
+	if err.Code != "" {
+		entry = entry.WithField("code", err.Code)
+	}
+	if err.Position != nil {
+		entry = entry.WithField("position", err.Position.String())
+	}
+	return entry
+}
+
+func writeJSON(w io.Writer, r report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func writeYAML(w io.Writer, r report) error {
+	b, err := yaml.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// SARIF 2.1.0 types. Only the subset of the specification needed to report
+// findings is modeled. See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "operator-verify"
+	toolInfoURI  = "https://github.com/operator-framework/api"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string        `json:"id"`
+	ShortDescription *sarifMessage `json:"shortDescription,omitempty"`
+	HelpURI          string        `json:"helpUri,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID       string                 `json:"ruleId"`
+	Level        string                 `json:"level"`
+	Message      sarifMessage           `json:"message"`
+	Locations    []sarifLocation        `json:"locations,omitempty"`
+	Suppressions []sarifSuppression     `json:"suppressions,omitempty"`
+	Properties   map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+func writeSARIF(w io.Writer, r report) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           toolName,
+			InformationURI: toolInfoURI,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	rules := map[string]struct{}{}
+	for _, result := range r.Results {
+		for _, f := range append(append([]finding{}, result.Errors...), result.Warnings...) {
+			ruleID := f.ruleID()
+			rules[ruleID] = struct{}{}
+			run.Results = append(run.Results, newSARIFResult(ruleID, result.Name, f))
+		}
+	}
+	// Suppressed findings are kept in the log, flagged as suppressed externally
+	// by the validation config.
+	for _, result := range r.Suppressed {
+		for _, f := range result.Findings {
+			ruleID := f.ruleID()
+			rules[ruleID] = struct{}{}
+			sr := newSARIFResult(ruleID, result.Name, f.finding)
+			sr.Suppressions = []sarifSuppression{{Kind: "external", Justification: f.Justification}}
+			run.Results = append(run.Results, sr)
+		}
+	}
+
+	ruleIDs := make([]string, 0, len(rules))
+	for id := range rules {
+		ruleIDs = append(ruleIDs, id)
+	}
+	sort.Strings(ruleIDs)
+	for _, id := range ruleIDs {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, newSARIFRule(id))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}})
+}
+
+// ruleID returns the rule code of f. Findings without a rule code are
+// reported under their error type.
+func (f finding) ruleID() string {
+	if f.Code == "" {
+		return string(f.Type)
+	}
+	return string(f.Code)
+}
+
+// newSARIFRule describes the rule id with its catalog entry, if any.
+func newSARIFRule(id string) sarifRule {
+	sr := sarifRule{ID: id}
+	if rule, ok := errors.LookupRule(errors.RuleCode(id)); ok {
+		sr.ShortDescription = &sarifMessage{Text: rule.Description}
+		sr.HelpURI = rule.Docs
+	}
+	return sr
+}
+
+func newSARIFResult(ruleID, name string, f finding) sarifResult {
+	sr := sarifResult{
+		RuleID:  ruleID,
+		Level:   "warning",
+		Message: sarifMessage{Text: f.message},
+		Properties: map[string]interface{}{
+			"name": name,
+		},
+	}
+	if f.Level == errors.LevelError {
+		sr.Level = "error"
+	}
+	if f.Field != "" {
+		sr.Properties["field"] = f.Field
+	}
+	if f.BadValue != nil {
+		sr.Properties["badValue"] = f.BadValue
+	}
+	if f.Manifest != "" {
+		location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: f.Manifest}}
+		if f.Position != nil && f.Position.Line > 0 {
+			location.Region = &sarifRegion{StartLine: f.Position.Line, StartColumn: f.Position.Column}
+		}
+		sr.Locations = []sarifLocation{{PhysicalLocation: location}}
+	}
+	return sr
+}
+
+// JUnit XML types. Each ManifestResult is reported as a test case which fails
+// when the result has errors. Warnings are reported as the test case output.
+// Suppressed findings are reported as skipped test cases of a separate suite.
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr,omitempty"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+func writeJUnit(w io.Writer, r report) error {
+	suite := junitTestSuite{Name: toolName, TestCases: []junitTestCase{}}
+	for _, result := range r.Results {
+		tc := junitTestCase{Name: result.Name, ClassName: toolName}
+		if len(result.Errors) > 0 && result.Errors[0].Manifest != "" {
+			tc.ClassName = result.Errors[0].Manifest
+		} else if len(result.Warnings) > 0 && result.Warnings[0].Manifest != "" {
+			tc.ClassName = result.Warnings[0].Manifest
+		}
+		if len(result.Errors) > 0 {
+			tc.Failure = &junitFailure{
+				Message:  fmt.Sprintf("%d error(s) found", len(result.Errors)),
+				Type:     string(result.Errors[0].Type),
+				Contents: joinMessages(result.Errors),
+			}
+			suite.Failures++
+		}
+		tc.SystemOut = joinMessages(result.Warnings)
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	suite.Tests = len(suite.TestCases)
+	suites := junitTestSuites{Suites: []junitTestSuite{suite}}
+
+	if len(r.Suppressed) > 0 {
+		skipped := junitTestSuite{Name: toolName + " (suppressed)", TestCases: []junitTestCase{}}
+		for _, result := range r.Suppressed {
+			for _, f := range result.Findings {
+				tc := junitTestCase{Name: fmt.Sprintf("%s: %s", result.Name, f.ruleID()), ClassName: toolName}
+				if f.Manifest != "" {
+					tc.ClassName = f.Manifest
+				}
+				tc.Skipped = &junitSkipped{Message: f.Justification}
+				tc.SystemOut = f.message
+				skipped.TestCases = append(skipped.TestCases, tc)
+			}
+		}
+		skipped.Tests = len(skipped.TestCases)
+		skipped.Skipped = skipped.Tests
+		suites.Suites = append(suites.Suites, skipped)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func joinMessages(findings []finding) string {
+	lines := make([]string, 0, len(findings))
+	for _, f := range findings {
+		lines = append(lines, f.message)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,181 @@
+package manifests
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	goerrors "errors"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/validation/config"
+	"github.com/operator-framework/api/pkg/validation/errors"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func testResults() []errors.ManifestResult {
+	csvResult := errors.ManifestResult{Name: "etcdoperator.v0.9.4"}
+	csvResult.Add(
+		errors.ErrInvalidCSV("install modes not found", "etcdoperator.v0.9.4").WithCode(errors.RuleCSVInstallModesMissing),
+		errors.WarnFieldMissing("optional field missing", "spec.icon", nil),
+	)
+	crdResult := errors.ManifestResult{Name: "etcdclusters.etcd.database.coreos.com"}
+	crdResult.Add(errors.ErrInvalidParse("error converting crd", goerrors.New("bad version")))
+	return []errors.ManifestResult{csvResult, crdResult, {Name: "0.9.4"}}
+}
+
+func testIndex() manifestIndex {
+	return manifestIndex{
+		byName: map[string]string{
+			"etcdoperator.v0.9.4":                   "bundle/manifests/etcdoperator.clusterserviceversion.yaml",
+			"etcdclusters.etcd.database.coreos.com": "bundle/manifests/etcdclusters.crd.yaml",
+		},
+		csvFile:    "bundle/manifests/etcdoperator.clusterserviceversion.yaml",
+		csvVersion: "0.9.4",
+	}
+}
+
+func TestWriteOutputJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeOutput(buf, outputJSON, testResults(), nil, testIndex()))
+
+	r := report{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &r))
+	require.Len(t, r.Results, 3)
+
+	require.Equal(t, "etcdoperator.v0.9.4", r.Results[0].Name)
+	require.Len(t, r.Results[0].Errors, 1)
+	require.Equal(t, errors.ErrorInvalidCSV, r.Results[0].Errors[0].Type)
+	require.Equal(t, errors.RuleCSVInstallModesMissing, r.Results[0].Errors[0].Code)
+	require.Equal(t, errors.Level(errors.LevelError), r.Results[0].Errors[0].Level)
+	require.Equal(t, "bundle/manifests/etcdoperator.clusterserviceversion.yaml", r.Results[0].Errors[0].Manifest)
+	require.Len(t, r.Results[0].Warnings, 1)
+	require.Equal(t, "spec.icon", r.Results[0].Warnings[0].Field)
+
+	require.Equal(t, "bad version", r.Results[1].Errors[0].BadValue)
+	require.Equal(t, "bundle/manifests/etcdclusters.crd.yaml", r.Results[1].Errors[0].Manifest)
+
+	require.Empty(t, r.Results[2].Errors)
+	require.Empty(t, r.Results[2].Warnings)
+}
+
+func TestWriteOutputYAML(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeOutput(buf, outputYAML, testResults(), nil, testIndex()))
+
+	r := report{}
+	require.NoError(t, yaml.Unmarshal(buf.Bytes(), &r))
+	require.Len(t, r.Results, 3)
+	require.Equal(t, "(etcdoperator.v0.9.4) install modes not found", r.Results[0].Errors[0].Detail)
+}
+
+func TestWriteOutputSARIF(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeOutput(buf, outputSARIF, testResults(), nil, testIndex()))
+
+	log := sarifLog{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	require.Equal(t, sarifVersion, log.Version)
+	require.Len(t, log.Runs, 1)
+
+	run := log.Runs[0]
+	require.Equal(t, []sarifRule{
+		{ID: string(errors.ErrorFieldMissing)},
+		{ID: string(errors.ErrorInvalidParse)},
+		{ID: string(errors.RuleCSVInstallModesMissing), ShortDescription: &sarifMessage{Text: "spec.installModes is empty"}},
+	}, run.Tool.Driver.Rules)
+	require.Equal(t, string(errors.RuleCSVInstallModesMissing), run.Results[0].RuleID)
+	require.Len(t, run.Results, 3)
+	require.Equal(t, "error", run.Results[0].Level)
+	require.Equal(t, "warning", run.Results[1].Level)
+	require.Equal(t, "spec.icon", run.Results[1].Properties["field"])
+	require.Equal(t, "bundle/manifests/etcdclusters.crd.yaml", run.Results[2].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+}
+
+func TestWriteOutputJUnit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeOutput(buf, outputJUnit, testResults(), nil, testIndex()))
+
+	suites := junitTestSuites{}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+	require.Len(t, suites.Suites, 1)
+
+	suite := suites.Suites[0]
+	require.Equal(t, 3, suite.Tests)
+	require.Equal(t, 2, suite.Failures)
+	require.NotNil(t, suite.TestCases[0].Failure)
+	require.Contains(t, suite.TestCases[0].SystemOut, "optional field missing")
+	require.Nil(t, suite.TestCases[2].Failure)
+}
+
+func testSuppressed() []config.SuppressedResult {
+	return []config.SuppressedResult{{
+		Name: "etcdoperator.v0.9.4",
+		Findings: []config.SuppressedFinding{{
+			Error:         errors.WarnFailedValidation("channel(s) [\"alpha\"] are not following the recommended naming convention", "etcdoperator.v0.9.4").WithCode(errors.RuleGoodPracticesChannelNaming),
+			Justification: "kept for existing subscribers",
+		}},
+	}}
+}
+
+func TestWriteOutputSuppressed(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeOutput(buf, outputJSON, testResults(), testSuppressed(), testIndex()))
+	r := report{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &r))
+	require.Len(t, r.Results, 3)
+	require.Len(t, r.Suppressed, 1)
+	require.Equal(t, "etcdoperator.v0.9.4", r.Suppressed[0].Name)
+	require.Len(t, r.Suppressed[0].Findings, 1)
+	require.Equal(t, errors.RuleGoodPracticesChannelNaming, r.Suppressed[0].Findings[0].Code)
+	require.Equal(t, "kept for existing subscribers", r.Suppressed[0].Findings[0].Justification)
+	require.Equal(t, "bundle/manifests/etcdoperator.clusterserviceversion.yaml", r.Suppressed[0].Findings[0].Manifest)
+
+	buf.Reset()
+	require.NoError(t, writeOutput(buf, outputSARIF, testResults(), testSuppressed(), testIndex()))
+	log := sarifLog{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	run := log.Runs[0]
+	require.Len(t, run.Results, 4)
+	require.Empty(t, run.Results[0].Suppressions)
+	require.Equal(t, string(errors.RuleGoodPracticesChannelNaming), run.Results[3].RuleID)
+	require.Equal(t, []sarifSuppression{{Kind: "external", Justification: "kept for existing subscribers"}}, run.Results[3].Suppressions)
+
+	buf.Reset()
+	require.NoError(t, writeOutput(buf, outputJUnit, testResults(), testSuppressed(), testIndex()))
+	suites := junitTestSuites{}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+	require.Len(t, suites.Suites, 2)
+	require.Equal(t, 1, suites.Suites[1].Skipped)
+	require.NotNil(t, suites.Suites[1].TestCases[0].Skipped)
+	require.Equal(t, "kept for existing subscribers", suites.Suites[1].TestCases[0].Skipped.Message)
+}
+
+func TestWriteOutputPositions(t *testing.T) {
+	result := errors.ManifestResult{Name: "etcdoperator.v0.9.4"}
+	result.Add(errors.ErrInvalidCSV("install modes not found", "etcdoperator.v0.9.4").
+		WithCode(errors.RuleCSVInstallModesMissing).
+		WithPosition(errors.Position{File: "bundle/manifests/etcd.csv.yaml", Line: 27, Column: 1}))
+	results := []errors.ManifestResult{result}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeOutput(buf, outputJSON, results, nil, testIndex()))
+	r := report{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &r))
+	require.Equal(t, "bundle/manifests/etcd.csv.yaml", r.Results[0].Errors[0].Manifest)
+	require.Equal(t, &errors.Position{File: "bundle/manifests/etcd.csv.yaml", Line: 27, Column: 1}, r.Results[0].Errors[0].Position)
+
+	buf.Reset()
+	require.NoError(t, writeOutput(buf, outputSARIF, results, nil, testIndex()))
+	log := sarifLog{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	location := log.Runs[0].Results[0].Locations[0].PhysicalLocation
+	require.Equal(t, "bundle/manifests/etcd.csv.yaml", location.ArtifactLocation.URI)
+	require.Equal(t, &sarifRegion{StartLine: 27, StartColumn: 1}, location.Region)
+}
+
+func TestWriteOutputUnsupported(t *testing.T) {
+	err := writeOutput(&bytes.Buffer{}, "html", testResults(), nil, testIndex())
+	require.EqualError(t, err, `unsupported output format "html", must be one of: text, json, yaml, sarif, junit`)
+}
@@ -0,0 +1,86 @@
+package manifests
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/validation"
+	interfaces "github.com/operator-framework/api/pkg/validation/interfaces"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// validatorGroups maps a group name to the label selector of the validators
+// it selects from validation.DefaultRegistry. Deprecated validators can only
+// be selected by name or by an explicit selector.
+var validatorGroups = map[string]string{
+	"default":            validation.StageLabel + "=" + string(validation.StageDefault),
+	"operator-framework": validation.SuiteLabel + "=" + validation.OperatorFrameworkSuite,
+	"all":                validation.StageLabel + "!=" + string(validation.StageDeprecated),
+}
+
+// validatorGroupAliases maps the former names of groups to their name.
+var validatorGroupAliases = map[string]string{
+	"operatorframework": "operator-framework",
+}
+
+// selectValidators returns the validators matching the given validator IDs,
+// group names or label selectors, in registration order. Former validator IDs
+// and group names are accepted too.
+func selectValidators(selection []string) (interfaces.Validators, error) {
+	selected := map[string]struct{}{}
+	for _, s := range selection {
+		s = strings.TrimSpace(s)
+		if info, ok := validation.DefaultRegistry.Get(s); ok {
+			selected[info.ID] = struct{}{}
+			continue
+		}
+
+		if group, ok := validatorGroupAliases[s]; ok {
+			s = group
+		}
+		selector, ok := validatorGroups[s]
+		if !ok {
+			if !isSelector(s) {
+				return nil, fmt.Errorf("unknown validator or group %q, must be a label selector or one of: %s", s, strings.Join(selectionNames(), ", "))
+			}
+			selector = s
+		}
+		infos, err := validation.DefaultRegistry.SelectString(selector)
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range infos {
+			selected[info.ID] = struct{}{}
+		}
+	}
+
+	validators := interfaces.Validators{}
+	for _, info := range validation.DefaultRegistry.Select(labels.Everything()) {
+		if _, ok := selected[info.ID]; ok {
+			validators = append(validators, info.Validator)
+		}
+	}
+	return validators, nil
+}
+
+// isSelector returns true if s looks like a label selector rather than a name.
+func isSelector(s string) bool {
+	return strings.ContainsAny(s, "=!()") || strings.Contains(s, " in ")
+}
+
+// selectionNames returns all group and validator names accepted by --select.
+func selectionNames() []string {
+	groups := make([]string, 0, len(validatorGroups))
+	for group := range validatorGroups {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	names := groups
+	for _, info := range validation.DefaultRegistry.List() {
+		names = append(names, info.ID)
+	}
+	return names
+}
@@ -0,0 +1,51 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/operator-framework/api/pkg/validation"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectValidators(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection []string
+		wantLen   int
+		wantErr   string
+	}{
+		{name: "Default", selection: []string{"default"}, wantLen: 4},
+		{name: "DefaultAndName", selection: []string{"default", "good-practices"}, wantLen: 5},
+		{name: "Duplicates", selection: []string{"csv", "default", " csv "}, wantLen: 4},
+		{name: "Group", selection: []string{"operator-framework"}, wantLen: 5},
+		{name: "GroupAlias", selection: []string{"operatorframework", "operator-framework"}, wantLen: 5},
+		{name: "Aliases", selection: []string{"operatorhub/v2", "operatorhubv2", "operatorhub-v2", "standardcapabilities"}, wantLen: 2},
+		{name: "All", selection: []string{"all"}, wantLen: 13},
+		{name: "Deprecated", selection: []string{"all", "operatorhub", "community"}, wantLen: 15},
+		{name: "Selector", selection: []string{"stage=alpha"}, wantLen: 1},
+		{name: "SelectorSet", selection: []string{"name in (csv,multiarch)"}, wantLen: 2},
+		{name: "Unknown", selection: []string{"default", "goodpractices"}, wantErr: `unknown validator or group "goodpractices"`},
+		{name: "InvalidSelector", selection: []string{"name in (csv"}, wantErr: `invalid validator selector "name in (csv"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validators, err := selectValidators(tt.selection)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, validators, tt.wantLen)
+		})
+	}
+}
+
+func TestValidatorGroupsAreSelectable(t *testing.T) {
+	for group, selector := range validatorGroups {
+		infos, err := validation.DefaultRegistry.SelectString(selector)
+		require.NoError(t, err, "group %q", group)
+		require.NotEmpty(t, infos, "group %q selects no validators", group)
+	}
+}
@@ -0,0 +1,86 @@
+package manifests
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/manifests"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// manifestIndex resolves the name of a ManifestResult to the manifest file
+// in the bundle directory that it was reported against.
+type manifestIndex struct {
+	// byName maps an object's metadata.name to the file it was decoded from.
+	byName map[string]string
+	// csvFile is the file containing the bundle's ClusterServiceVersion.
+	csvFile string
+	// csvVersion is the bundle's CSV spec.version, which some validators
+	// use as the result name.
+	csvVersion string
+}
+
+// newManifestIndex walks dir and records the file each named object is found in.
+// Files which cannot be read or decoded are ignored, since reporting those is
+// the job of the bundle loader.
+func newManifestIndex(dir string, bundle *manifests.Bundle) manifestIndex {
+	idx := manifestIndex{byName: map[string]string{}}
+	if bundle != nil && bundle.CSV != nil {
+		idx.csvVersion = bundle.CSV.Spec.Version.String()
+	}
+
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") && path != dir {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return nil
+		}
+		defer f.Close()
+
+		obj := unstructured.Unstructured{}
+		if err := yaml.NewYAMLOrJSONDecoder(f, 30).Decode(&obj); err != nil || obj.GetName() == "" {
+			return nil
+		}
+		if _, ok := idx.byName[obj.GetName()]; !ok {
+			idx.byName[obj.GetName()] = path
+		}
+		if obj.GetKind() == operatorsv1alpha1.ClusterServiceVersionKind && idx.csvFile == "" {
+			idx.csvFile = path
+		}
+		return nil
+	})
+
+	return idx
+}
+
+// lookup returns the manifest file for the result name, or an empty string
+// if it is not known.
+func (idx manifestIndex) lookup(name string) string {
+	if name == "" {
+		return ""
+	}
+	if path, ok := idx.byName[name]; ok {
+		return path
+	}
+	if name == idx.csvVersion {
+		return idx.csvFile
+	}
+	return ""
+}
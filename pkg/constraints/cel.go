@@ -1,14 +1,13 @@
 package constraints
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"github.com/google/cel-go/cel"
-	"github.com/google/cel-go/checker/decls"
 	"github.com/google/cel-go/common/types"
 	"github.com/google/cel-go/common/types/ref"
-	"github.com/google/cel-go/interpreter/functions"
-	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 
 	"github.com/blang/semver/v4"
 )
@@ -25,9 +24,14 @@ type Cel struct {
 // NewCelEnvironment returns a CEL environment which can be used to
 // evaluate CEL expression and an error if occurs
 func NewCelEnvironment() *CelEnvironment {
-	env, err := cel.NewEnv(cel.Declarations(
-		decls.NewVar(PropertiesKey, decls.NewListType(decls.NewMapType(decls.String, decls.Any)))),
+	env, err := cel.NewEnv(
+		cel.Variable(PropertiesKey, cel.ListType(cel.MapType(cel.StringType, cel.DynType))),
 		cel.Lib(semverLib{}),
+		cel.Lib(kubeLib{}),
+		cel.Lib(propertiesLib{}),
+		// Macro calls are tracked for the traces of Explain to show the macros
+		// of rules, such as exists(), as written.
+		cel.EnableMacroCallTracking(),
 	)
 	// If an error occurs here, it means the CEL environment is unable to load
 	// configuration for custom libraries properly. Hence, the CEL environment is
@@ -49,6 +53,7 @@ type CelEnvironment struct {
 // CelProgram is a struct that encapsulates compiled CEL program
 type CelProgram struct {
 	program cel.Program
+	ast     *cel.Ast
 }
 
 /*
@@ -64,46 +69,136 @@ Example:
 The result is `semver_compare` is an integer just like `Compare`. So, the CEL
 expression `semver_compare(v1, v2) == 0` is equivalent v1.Compare(v2) == 0. In
 the other words, it checks if v1 is equal to v2 in term of semver comparision.
+Either version can be a number, ex. `semver_compare(p.value, "4.9")` where the
+value is 4.8, compared as the string it is printed as.
+
+The other functions of the library parse a version, ex. "1.2.3-beta.1", with
+the same leniency:
+  - `semver_valid(v)` returns whether v is a valid version;
+  - `semver_major(v)`, `semver_minor(v)` and `semver_patch(v)` return its
+    components, ex. 1, 2 and 3;
+  - `semver_prerelease(v)` returns its pre-release version, ex. "beta.1", or ""
+    if it has none;
+  - `semver_in_range(v, r)` returns whether v is in the version range r, ex.
+    ">=1.0.0 <2.0.0", written as for the versionRange of package constraints.
 */
 type semverLib struct{}
 
 func (semverLib) CompileOptions() []cel.EnvOption {
 	return []cel.EnvOption{
-		cel.Declarations(
-			decls.NewFunction("semver_compare",
-				decls.NewOverload("semver_compare",
-					[]*exprpb.Type{decls.Any, decls.Any},
-					decls.Int))),
+		cel.Function("semver_compare",
+			cel.Overload("semver_compare_string_string",
+				[]*cel.Type{cel.StringType, cel.StringType}, cel.IntType,
+				cel.BinaryBinding(semverCompare)),
+			// Versions are often given as numbers in property values, ex. the
+			// 4.8 of olm.maxOpenShiftVersion.
+			cel.Overload("semver_compare_double_string",
+				[]*cel.Type{cel.DoubleType, cel.StringType}, cel.IntType,
+				cel.BinaryBinding(semverCompareAny)),
+			cel.Overload("semver_compare_string_double",
+				[]*cel.Type{cel.StringType, cel.DoubleType}, cel.IntType,
+				cel.BinaryBinding(semverCompareAny))),
+		cel.Function("semver_in_range",
+			cel.Overload("semver_in_range_string_string",
+				[]*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(semverInRange))),
+		cel.Function("semver_valid",
+			cel.Overload("semver_valid_string",
+				[]*cel.Type{cel.StringType}, cel.BoolType,
+				cel.UnaryBinding(func(val ref.Val) ref.Val {
+					_, err := parseSemver(val)
+					return types.Bool(err == nil)
+				}))),
+		cel.Function("semver_major",
+			cel.Overload("semver_major_string",
+				[]*cel.Type{cel.StringType}, cel.IntType,
+				cel.UnaryBinding(semverComponent(func(v semver.Version) ref.Val { return types.Int(v.Major) })))),
+		cel.Function("semver_minor",
+			cel.Overload("semver_minor_string",
+				[]*cel.Type{cel.StringType}, cel.IntType,
+				cel.UnaryBinding(semverComponent(func(v semver.Version) ref.Val { return types.Int(v.Minor) })))),
+		cel.Function("semver_patch",
+			cel.Overload("semver_patch_string",
+				[]*cel.Type{cel.StringType}, cel.IntType,
+				cel.UnaryBinding(semverComponent(func(v semver.Version) ref.Val { return types.Int(v.Patch) })))),
+		cel.Function("semver_prerelease",
+			cel.Overload("semver_prerelease_string",
+				[]*cel.Type{cel.StringType}, cel.StringType,
+				cel.UnaryBinding(semverComponent(func(v semver.Version) ref.Val {
+					pre := make([]string, 0, len(v.Pre))
+					for _, p := range v.Pre {
+						pre = append(pre, p.String())
+					}
+					return types.String(strings.Join(pre, "."))
+				})))),
 	}
 }
 
 func (semverLib) ProgramOptions() []cel.ProgramOption {
-	return []cel.ProgramOption{
-		cel.Functions(
-			&functions.Overload{
-				Operator: "semver_compare",
-				Binary:   semverCompare,
-			},
-		),
+	return nil
+}
+
+// parseSemver parses the version val, which must be a string.
+func parseSemver(val ref.Val) (semver.Version, ref.Val) {
+	s, ok := val.(types.String)
+	if !ok {
+		return semver.Version{}, types.MaybeNoSuchOverloadErr(val)
+	}
+	v, err := semver.ParseTolerant(string(s))
+	if err != nil {
+		return semver.Version{}, types.NewErr("unable to parse '%v' to semver format", val.Value())
 	}
+	return v, nil
 }
 
 func semverCompare(val1, val2 ref.Val) ref.Val {
-	v1, err := semver.ParseTolerant(fmt.Sprint(val1.Value()))
+	v1, err := parseSemver(val1)
 	if err != nil {
-		return types.ValOrErr(val1, "unable to parse '%v' to semver format", val1.Value())
+		return err
 	}
-
-	v2, err := semver.ParseTolerant(fmt.Sprint(val2.Value()))
+	v2, err := parseSemver(val2)
 	if err != nil {
-		return types.ValOrErr(val2, "unable to parse '%v' to semver format", val2.Value())
+		return err
 	}
 	return types.Int(v1.Compare(v2))
 }
 
+// semverCompareAny compares the versions val1 and val2 as they are printed,
+// ex. 4.8 as "4.8".
+func semverCompareAny(val1, val2 ref.Val) ref.Val {
+	return semverCompare(types.String(fmt.Sprint(val1.Value())), types.String(fmt.Sprint(val2.Value())))
+}
+
+func semverInRange(val, rangeVal ref.Val) ref.Val {
+	v, err := parseSemver(val)
+	if err != nil {
+		return err
+	}
+	s, ok := rangeVal.(types.String)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(rangeVal)
+	}
+	r, rangeErr := semver.ParseRange(string(s))
+	if rangeErr != nil {
+		return types.NewErr("unable to parse '%v' to a semver range: %v", s, rangeErr)
+	}
+	return types.Bool(r(v))
+}
+
+// semverComponent returns a function returning the component of a version.
+func semverComponent(component func(semver.Version) ref.Val) func(ref.Val) ref.Val {
+	return func(val ref.Val) ref.Val {
+		v, err := parseSemver(val)
+		if err != nil {
+			return err
+		}
+		return component(v)
+	}
+}
+
 // Evaluate to evaluate the compiled CEL program against input data (map)
 func (e CelProgram) Evaluate(data map[string]interface{}) (bool, error) {
-	result, _, err := e.program.Eval(data)
+	result, _, err := e.eval(data)
 	if err != nil {
 		return false, err
 	}
@@ -115,21 +210,56 @@ func (e CelProgram) Evaluate(data map[string]interface{}) (bool, error) {
 	return false, fmt.Errorf("cel expression evalutated to %T, not bool", result.Value())
 }
 
+// eval evaluates the compiled CEL program against input data within
+// maxCelEvalTime.
+func (e CelProgram) eval(data map[string]interface{}) (ref.Val, *cel.EvalDetails, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), maxCelEvalTime)
+	defer cancel()
+	result, details, err := e.program.ContextEval(ctx, data)
+	if err != nil {
+		return result, details, evalError(ctx, err)
+	}
+	return result, details, nil
+}
+
 // Validate to validate the CEL expression string by compiling it into CEL program
 func (e *CelEnvironment) Validate(rule string) (CelProgram, error) {
+	return e.validate(rule)
+}
+
+// validate compiles the CEL expression string into a CEL program with the
+// program options opts. Rules longer than maxCelRuleSize or with an estimated
+// cost over maxCelCost are rejected.
+func (e *CelEnvironment) validate(rule string, opts ...cel.ProgramOption) (CelProgram, error) {
 	var celProg CelProgram
+	if len(rule) > maxCelRuleSize {
+		return celProg, fmt.Errorf("%w: the rule is %d bytes", ErrMaxCelRuleSizeExceeded, len(rule))
+	}
 	ast, issues := e.env.Compile(rule)
 	if err := issues.Err(); err != nil {
 		return celProg, err
 	}
 
-	if ast.ResultType() != decls.Bool {
+	if !ast.OutputType().IsExactType(cel.BoolType) {
 		return celProg, fmt.Errorf("cel expressions must have type Bool")
 	}
 
-	prog, err := e.env.Program(ast)
+	cost, err := e.env.EstimateCost(ast, celCostEstimator{})
+	if err != nil {
+		return celProg, err
+	}
+	if cost.Max > maxCelCost {
+		return celProg, fmt.Errorf("%w: the estimated cost of the rule is %d", ErrMaxCelCostExceeded, cost.Max)
+	}
+
+	opts = append(opts,
+		cel.CostLimit(maxCelCost),
+		cel.CostTracking(celCostEstimator{}),
+		cel.InterruptCheckFrequency(celInterruptCheckFrequency),
+	)
+	prog, err := e.env.Program(ast, opts...)
 	if err != nil {
 		return celProg, err
 	}
-	return CelProgram{program: prog}, nil
+	return CelProgram{program: prog, ast: ast}, nil
 }
@@ -1,9 +1,11 @@
 package constraints
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCelLibrary(t *testing.T) {
@@ -69,3 +71,118 @@ func TestCelLibrary(t *testing.T) {
 		})
 	}
 }
+
+func TestCelFunctions(t *testing.T) {
+	props := []map[string]interface{}{
+		{"type": "olm.package", "value": map[string]interface{}{"packageName": "etcd", "version": "0.9.4-beta.1"}},
+		{"type": "olm.gvk", "value": map[string]interface{}{"group": "etcd.database.coreos.com", "kind": "EtcdCluster", "version": "v1beta2"}},
+		{"type": "olm.gvk", "value": map[string]interface{}{"group": "etcd.database.coreos.com", "kind": "EtcdBackup", "version": "v1alpha1"}},
+		{"type": "olm.maxOpenShiftVersion", "value": "4.8"},
+		{"type": "olm.test.number", "value": 4.8},
+	}
+	data := map[string]interface{}{PropertiesKey: props}
+	version := `property_values(properties, "olm.package")[0].version`
+
+	tests := []struct {
+		name   string
+		rule   string
+		out    bool
+		errStr string
+	}{
+		{name: "semver_compare/number", rule: `properties.exists(p, p.type == "olm.test.number" && semver_compare(p.value, "4.9") < 0 && semver_compare("4.8.0", p.value) == 0)`, out: true},
+		{name: "semver_in_range", rule: `semver_in_range(` + version + `, ">=0.9.0 <1.0.0")`, out: true},
+		{name: "semver_in_range/prerelease", rule: `semver_in_range(` + version + `, ">=0.9.4")`, out: false},
+		{name: "semver components", rule: `semver_major(` + version + `) == 0 && semver_minor(` + version + `) == 9 && semver_patch(` + version + `) == 4`, out: true},
+		{name: "semver_prerelease", rule: `semver_prerelease(` + version + `) == "beta.1" && semver_prerelease("1.0.0") == ""`, out: true},
+		{name: "semver_valid", rule: `semver_valid(` + version + `) && semver_valid("v1.2") && !semver_valid("latest")`, out: true},
+		{name: "kube_version_compare", rule: `kube_version_compare("v1beta2", "v1beta1") == 1 && kube_version_compare("v1beta2", "v1") == -1 && kube_version_compare("v2alpha1", "v1alpha1") == 1 && kube_version_compare("v1", "v1") == 0`, out: true},
+		{name: "gvks", rule: `gvks(properties).exists(g, g.group == "etcd.database.coreos.com" && kube_version_compare(g.version, "v1beta2") >= 0)`, out: true},
+		{name: "gvks/none", rule: `gvks(properties).exists(g, g.kind == "EtcdBackup" && kube_version_compare(g.version, "v1beta1") >= 0)`, out: false},
+		{name: "provides_gvk", rule: `provides_gvk(properties, "etcd.database.coreos.com", "v1beta2", "EtcdCluster") && !provides_gvk(properties, "etcd.database.coreos.com", "v1", "EtcdCluster")`, out: true},
+		{name: "has_property", rule: `has_property(properties, "olm.maxOpenShiftVersion") && !has_property(properties, "olm.label")`, out: true},
+		{name: "property_values", rule: `size(property_values(properties, "olm.gvk")) == 2 && property_values(properties, "olm.maxOpenShiftVersion")[0] == "4.8"`, out: true},
+		{name: "invalid semver", rule: `semver_major("latest") == 0`, errStr: "unable to parse 'latest' to semver format"},
+		{name: "invalid semver range", rule: `semver_in_range("1.0.0", "latest")`, errStr: "unable to parse 'latest' to a semver range"},
+		{name: "invalid kube version", rule: `kube_version_compare("1.0", "v1") == 0`, errStr: "unable to parse '1.0' to a Kubernetes API version"},
+	}
+	env := NewCelEnvironment()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prog, err := env.Validate(tt.rule)
+			require.NoError(t, err)
+			out, err := prog.Evaluate(data)
+			if tt.errStr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.errStr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.out, out)
+		})
+	}
+}
+
+func TestCelFunctionsTypeChecked(t *testing.T) {
+	env := NewCelEnvironment()
+	for _, rule := range []string{
+		`semver_compare(1, "1.0.0") == 0`,
+		`semver_in_range("1.0.0")`,
+		`kube_version_compare("v1", 1) == 0`,
+		`provides_gvk(properties, "example.com", "v1")`,
+		`has_property("olm.gvk", properties)`,
+		`gvks(properties) == 1`,
+	} {
+		_, err := env.Validate(rule)
+		require.Error(t, err, rule)
+		require.Contains(t, err.Error(), "found no matching overload", rule)
+	}
+}
+
+func TestCelLimits(t *testing.T) {
+	env := NewCelEnvironment()
+
+	_, err := env.Validate(`properties.exists(p, p.type == "` + strings.Repeat("x", maxCelRuleSize) + `")`)
+	require.ErrorIs(t, err, ErrMaxCelRuleSizeExceeded)
+	require.EqualError(t, err, "cel rule is greater than max rule size 8192 bytes: the rule is 8226 bytes")
+
+	for _, rule := range []string{
+		`properties.all(a, properties.all(b, properties.exists(c, a.type == b.type && b.type == c.type)))`,
+		`property_values(properties, "olm.gvk").all(a, gvks(properties).all(b, properties.exists(c, a == b && c.type == "olm.gvk")))`,
+	} {
+		_, err := env.Validate(rule)
+		require.ErrorIs(t, err, ErrMaxCelCostExceeded, rule)
+		require.Contains(t, err.Error(), "the estimated cost of the rule is", rule)
+	}
+
+	// Rules iterating over the properties pairwise are accepted.
+	for _, rule := range []string{
+		`properties.exists(p, properties.exists(q, p.type == q.type))`,
+		`properties.all(a, properties.exists(b, a.type == b.type))`,
+		`property_values(properties, "olm.gvk").all(a, gvks(properties).exists(b, a == b))`,
+	} {
+		prog, err := env.Validate(rule)
+		require.NoError(t, err, rule)
+		satisfied, err := prog.Evaluate(map[string]interface{}{PropertiesKey: []interface{}{
+			map[string]interface{}{"type": "olm.gvk", "value": map[string]interface{}{"group": "example.com", "kind": "Memcached", "version": "v1"}},
+		}})
+		require.NoError(t, err, rule)
+		require.True(t, satisfied, rule)
+	}
+
+	// The rule is estimated for at most maxCelProperties properties, its
+	// evaluation against many more runs out of its budget.
+	prog, err := env.Validate(`!has_property(properties, "olm.maxOpenShiftVersion")`)
+	require.NoError(t, err)
+	property := map[string]interface{}{"type": "olm.gvk", "value": ""}
+	properties := make([]interface{}, maxCelCost)
+	for i := range properties {
+		properties[i] = property
+	}
+	_, err = prog.Evaluate(map[string]interface{}{PropertiesKey: properties})
+	require.ErrorIs(t, err, ErrMaxCelCostExceeded)
+	require.EqualError(t, err, "cel rule cost is greater than max cost 1000000: the evaluation of the rule exceeded it")
+
+	satisfied, err := prog.Evaluate(map[string]interface{}{PropertiesKey: properties[:maxCelProperties]})
+	require.NoError(t, err)
+	require.True(t, satisfied)
+}
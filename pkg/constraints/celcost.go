@@ -0,0 +1,107 @@
+package constraints
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/cel-go/checker"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/interpreter"
+)
+
+const (
+	// maxCelRuleSize is the maximum size in bytes of a CEL rule.
+	maxCelRuleSize = 8 << 10
+	// maxCelCost is the maximum cost of evaluating a CEL rule against the
+	// properties of a bundle, both as estimated when the rule is compiled and
+	// as tracked when it is evaluated. A cost unit is roughly one step of the
+	// evaluation, ex. a comparison, a field selection or an iteration of a macro.
+	maxCelCost = 1000000
+	// maxCelProperties is the number of properties of a bundle the cost of a
+	// rule is estimated for. Bundles declare tens of properties, and rules
+	// iterating over them in nested macros, ex. to compare them pairwise, stay
+	// within maxCelCost. Evaluations against more properties are still bounded
+	// by maxCelCost.
+	maxCelProperties = 128
+	// maxCelValueSize is the size of the strings, lists and maps of property
+	// values the cost of a rule is estimated for: types, names, versions and
+	// GVKs are short.
+	maxCelValueSize = 64
+	// celInterruptCheckFrequency is the number of iterations of the macros of
+	// a rule between the checks of whether its evaluation timed out.
+	celInterruptCheckFrequency = 100
+	// maxCelEvalTime is the maximum duration of the evaluation of a CEL rule.
+	maxCelEvalTime = time.Second
+)
+
+// ErrMaxCelRuleSizeExceeded is returned when a CEL rule's size > maxCelRuleSize.
+var ErrMaxCelRuleSizeExceeded = fmt.Errorf("cel rule is greater than max rule size %d bytes", maxCelRuleSize)
+
+// ErrMaxCelCostExceeded is returned when a CEL rule's estimated or actual cost > maxCelCost.
+var ErrMaxCelCostExceeded = fmt.Errorf("cel rule cost is greater than max cost %d", maxCelCost)
+
+// celCostEstimator estimates the cost of the rules at compile time, bounding
+// the size of the properties variable and of its values, and tracks the cost
+// of the functions of propertiesLib at runtime. The functions of semverLib and
+// kubeLib parse short strings and are given the default cost of a call.
+type celCostEstimator struct{}
+
+func (celCostEstimator) EstimateSize(element checker.AstNode) *checker.SizeEstimate {
+	if path := element.Path(); len(path) == 1 && path[0] == PropertiesKey {
+		return &checker.SizeEstimate{Min: 0, Max: maxCelProperties}
+	}
+	return &checker.SizeEstimate{Min: 0, Max: maxCelValueSize}
+}
+
+func (celCostEstimator) EstimateCallCost(function, overloadID string, target *checker.AstNode, args []checker.AstNode) *checker.CallEstimate {
+	switch function {
+	case "has_property", "property_values", "gvks", "provides_gvk":
+	default:
+		return nil
+	}
+	// The functions iterate over the properties given as first argument, and
+	// the lists they return are at most as long.
+	size := checker.SizeEstimate{Min: 0, Max: maxCelProperties}
+	if computed := args[0].ComputedSize(); computed != nil {
+		size = *computed
+	}
+	estimate := &checker.CallEstimate{CostEstimate: checker.CostEstimate{Min: 1, Max: size.Max + 1}}
+	if function == "property_values" || function == "gvks" {
+		estimate.ResultSize = &size
+	}
+	return estimate
+}
+
+func (celCostEstimator) CallCost(function, overloadID string, args []ref.Val, result ref.Val) *uint64 {
+	switch function {
+	case "has_property", "property_values", "gvks", "provides_gvk":
+	default:
+		return nil
+	}
+	cost := uint64(1)
+	if len(args) != 0 {
+		if list, ok := args[0].(traits.Sizer); ok {
+			if size, ok := list.Size().(types.Int); ok && size > 0 {
+				cost += uint64(size)
+			}
+		}
+	}
+	return &cost
+}
+
+// evalError returns a clear error for the evaluations of a rule cancelled for
+// exceeding maxCelCost or maxCelEvalTime, and err otherwise.
+func evalError(ctx context.Context, err error) error {
+	var cancelled interpreter.EvalCancelledError
+	switch {
+	case errors.As(err, &cancelled) && cancelled.Cause == interpreter.CostLimitExceeded:
+		return fmt.Errorf("%w: the evaluation of the rule exceeded it", ErrMaxCelCostExceeded)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("cel rule evaluation took longer than %s", maxCelEvalTime)
+	}
+	return err
+}
@@ -0,0 +1,172 @@
+package constraints
+
+import (
+	"regexp"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"k8s.io/apimachinery/pkg/version"
+)
+
+/*
+kubeLib is the CEL library comparing Kubernetes API versions, ex. "v1beta2".
+
+`kube_version_compare(v1, v2)` returns -1, 0 or 1 if v1 is respectively older
+than, the same as or newer than v2, in the order Kubernetes sorts API
+versions: v1alpha1 < v1beta1 < v1beta2 < v1 < v2. For example, the rule
+`kube_version_compare(p.value.version, "v1beta2") >= 0` is true for the
+olm.gvk properties p of version v1beta2, v1 or newer.
+*/
+type kubeLib struct{}
+
+func (kubeLib) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("kube_version_compare",
+			cel.Overload("kube_version_compare_string_string",
+				[]*cel.Type{cel.StringType, cel.StringType}, cel.IntType,
+				cel.BinaryBinding(kubeVersionCompare))),
+	}
+}
+
+func (kubeLib) ProgramOptions() []cel.ProgramOption {
+	return nil
+}
+
+// kubeVersionPattern matches the Kubernetes API versions.
+var kubeVersionPattern = regexp.MustCompile(`^v[1-9][0-9]*((alpha|beta)[1-9][0-9]*)?$`)
+
+func kubeVersionCompare(val1, val2 ref.Val) ref.Val {
+	for _, val := range []ref.Val{val1, val2} {
+		s, ok := val.(types.String)
+		if !ok {
+			return types.MaybeNoSuchOverloadErr(val)
+		}
+		if !kubeVersionPattern.MatchString(string(s)) {
+			return types.NewErr("unable to parse '%v' to a Kubernetes API version", s)
+		}
+	}
+	switch c := version.CompareKubeAwareVersionStrings(string(val1.(types.String)), string(val2.(types.String))); {
+	case c < 0:
+		return types.IntNegOne
+	case c > 0:
+		return types.IntOne
+	}
+	return types.IntZero
+}
+
+/*
+propertiesLib is the CEL library of helpers filtering the properties of a
+bundle, as given to rules in the properties variable:
+  - `has_property(properties, type)` returns whether a property is of the type,
+    ex. "olm.maxOpenShiftVersion";
+  - `property_values(properties, type)` returns the values of the properties of
+    the type;
+  - `gvks(properties)` returns the values of the olm.gvk properties, maps with
+    a group, version and kind;
+  - `provides_gvk(properties, group, version, kind)` returns whether an olm.gvk
+    property is for the group, version and kind.
+
+For example, the rule
+`gvks(properties).exists(g, g.group == "example.com" && kube_version_compare(g.version, "v1beta2") >= 0)`
+is true for the bundles which provide an API of the group example.com of
+version v1beta2 or newer.
+*/
+type propertiesLib struct{}
+
+func (propertiesLib) CompileOptions() []cel.EnvOption {
+	properties := cel.ListType(cel.MapType(cel.StringType, cel.DynType))
+	return []cel.EnvOption{
+		cel.Function("has_property",
+			cel.Overload("has_property_list_string",
+				[]*cel.Type{properties, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(func(props, typ ref.Val) ref.Val {
+					values, err := propertyValues(props, typ)
+					if err != nil {
+						return err
+					}
+					return types.Bool(len(values) != 0)
+				}))),
+		cel.Function("property_values",
+			cel.Overload("property_values_list_string",
+				[]*cel.Type{properties, cel.StringType}, cel.ListType(cel.DynType),
+				cel.BinaryBinding(func(props, typ ref.Val) ref.Val {
+					values, err := propertyValues(props, typ)
+					if err != nil {
+						return err
+					}
+					return types.NewRefValList(types.DefaultTypeAdapter, values)
+				}))),
+		cel.Function("gvks",
+			cel.Overload("gvks_list",
+				[]*cel.Type{properties}, properties,
+				cel.UnaryBinding(func(props ref.Val) ref.Val {
+					values, err := propertyValues(props, types.String(gvkPropertyType))
+					if err != nil {
+						return err
+					}
+					return types.NewRefValList(types.DefaultTypeAdapter, values)
+				}))),
+		cel.Function("provides_gvk",
+			cel.Overload("provides_gvk_list_string_string_string",
+				[]*cel.Type{properties, cel.StringType, cel.StringType, cel.StringType}, cel.BoolType,
+				cel.FunctionBinding(providesGVK))),
+	}
+}
+
+func (propertiesLib) ProgramOptions() []cel.ProgramOption {
+	return nil
+}
+
+// propertyValues returns the values of the properties props of type typ.
+func propertyValues(props, typ ref.Val) ([]ref.Val, ref.Val) {
+	list, ok := props.(traits.Lister)
+	if !ok {
+		return nil, types.MaybeNoSuchOverloadErr(props)
+	}
+	if _, ok := typ.(types.String); !ok {
+		return nil, types.MaybeNoSuchOverloadErr(typ)
+	}
+	var values []ref.Val
+	for it := list.Iterator(); it.HasNext() == types.True; {
+		p, ok := it.Next().(traits.Mapper)
+		if !ok {
+			continue
+		}
+		if t, found := p.Find(types.String("type")); !found || t.Equal(typ) != types.True {
+			continue
+		}
+		if v, found := p.Find(types.String("value")); found {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+func providesGVK(args ...ref.Val) ref.Val {
+	if len(args) != 4 {
+		return types.NoSuchOverloadErr()
+	}
+	values, err := propertyValues(args[0], types.String(gvkPropertyType))
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		gvk, ok := v.(traits.Mapper)
+		if !ok {
+			continue
+		}
+		matches := true
+		for i, field := range []string{"group", "version", "kind"} {
+			if f, found := gvk.Find(types.String(field)); !found || f.Equal(args[i+1]) != types.True {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return types.True
+		}
+	}
+	return types.False
+}
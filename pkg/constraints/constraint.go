@@ -49,6 +49,11 @@ type GVKConstraint struct {
 	Version string `json:"version" yaml:"version"`
 }
 
+// String returns the GVK of c, ex. "etcd.database.coreos.com/v1beta2, Kind=EtcdCluster".
+func (c GVKConstraint) String() string {
+	return fmt.Sprintf("%s/%s, Kind=%s", c.Group, c.Version, c.Kind)
+}
+
 // PackageConstraint defines a package constraint.
 type PackageConstraint struct {
 	// PackageName is the name of the package.
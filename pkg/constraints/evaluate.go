@@ -0,0 +1,349 @@
+package constraints
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/google/cel-go/cel"
+)
+
+const (
+	// packagePropertyType and gvkPropertyType are the types of the properties
+	// which package and GVK constraints are satisfied by.
+	packagePropertyType = "olm.package"
+	gvkPropertyType     = "olm.gvk"
+)
+
+// Property is a typed property of a candidate bundle, ex. an olm.gvk property
+// with the value {"group":"etcd.database.coreos.com","kind":"EtcdCluster","version":"v1beta2"}.
+type Property struct {
+	Type  string          `json:"type" yaml:"type"`
+	Value json.RawMessage `json:"value" yaml:"value"`
+}
+
+// Candidate is a bundle which may satisfy a constraint.
+type Candidate struct {
+	// Name identifies the candidate, ex. the name of its bundle.
+	Name       string
+	Properties []Property
+}
+
+// Result is the result of the evaluation of a constraint against a candidate.
+type Result struct {
+	// Candidate is the name of the candidate.
+	Candidate string
+	// Satisfied is whether the candidate satisfies the constraint.
+	Satisfied bool
+	// Err is the first error met evaluating the constraint against the
+	// candidate, ex. a CEL rule failing on its properties, which is then not
+	// satisfied, or an olm.package property with an invalid version, which is
+	// then ignored.
+	Err error
+	// Trace is the trace of the evaluation, returned by Explain only.
+	Trace *Trace
+}
+
+// Evaluate evaluates c against each of candidates, the way OLM does when
+// resolving a dependency: a candidate satisfies a package constraint if it has
+// an olm.package property for the package with a version in the range, a GVK
+// constraint if it has an olm.gvk property for the GVK and a CEL constraint if
+// the rule is true for its properties. It satisfies an all constraint if it
+// satisfies each of its constraints, an any constraint if it satisfies one of
+// them and a not constraint if it satisfies none of them.
+//
+// The results are returned in the order of candidates. An error is returned
+// if c cannot be evaluated, ex. because one of its version ranges or CEL rules
+// is invalid, or one of its constraints is not of exactly one kind.
+func Evaluate(c Constraint, candidates []Candidate) ([]Result, error) {
+	return evaluate(c, candidates, false)
+}
+
+// evaluate evaluates c against each of candidates, with the trace of each
+// evaluation if explain is true.
+func evaluate(c Constraint, candidates []Candidate, explain bool) ([]Result, error) {
+	e := &evaluator{env: NewCelEnvironment(), programs: map[string]CelProgram{}, ranges: map[string]semver.Range{}, explain: explain}
+	if err := e.compile(c, ""); err != nil {
+		return nil, err
+	}
+	results := make([]Result, 0, len(candidates))
+	for _, candidate := range candidates {
+		cand := &candidateProperties{Candidate: candidate}
+		trace := e.trace(c, "", cand)
+		result := Result{Candidate: candidate.Name, Satisfied: trace.Satisfied, Err: cand.err}
+		if explain {
+			result.Trace = trace
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Satisfying returns the names of the candidates of results which satisfy the
+// constraint, in order.
+func Satisfying(results []Result) []string {
+	var names []string
+	for _, r := range results {
+		if r.Satisfied {
+			names = append(names, r.Candidate)
+		}
+	}
+	return names
+}
+
+// evaluator evaluates a constraint whose version ranges and CEL rules are
+// compiled once for every candidate.
+type evaluator struct {
+	env      *CelEnvironment
+	programs map[string]CelProgram
+	ranges   map[string]semver.Range
+	// explain is whether CEL programs track the values of their sub-expressions.
+	explain bool
+}
+
+// kinds returns the kinds of the constraints set in c, ex. "package".
+func kinds(c Constraint) []string {
+	var kinds []string
+	for _, k := range []struct {
+		name string
+		set  bool
+	}{
+		{"cel", c.Cel != nil},
+		{"package", c.Package != nil},
+		{"gvk", c.GVK != nil},
+		{"all", c.All != nil},
+		{"any", c.Any != nil},
+		{"not", c.Not != nil},
+	} {
+		if k.set {
+			kinds = append(kinds, k.name)
+		}
+	}
+	return kinds
+}
+
+// compile checks that c and its nested constraints can be evaluated, and
+// compiles their version ranges and CEL rules. path locates c in the
+// constraint evaluated, ex. "all.constraints[1]".
+func (e *evaluator) compile(c Constraint, path string) error {
+	kinds := kinds(c)
+	if len(kinds) != 1 {
+		where := "constraint"
+		if path != "" {
+			where = fmt.Sprintf("constraint %s", path)
+		}
+		if len(kinds) == 0 {
+			return fmt.Errorf("%s has none of cel, package, gvk, all, any or not", where)
+		}
+		return fmt.Errorf("%s has more than one of %s", where, strings.Join(kinds, ", "))
+	}
+	path = constraintPath(path, kinds[0])
+
+	switch {
+	case c.Cel != nil:
+		if _, ok := e.programs[c.Cel.Rule]; ok {
+			return nil
+		}
+		var opts []cel.ProgramOption
+		if e.explain {
+			opts = append(opts, cel.EvalOptions(cel.OptTrackState))
+		}
+		prog, err := e.env.validate(c.Cel.Rule, opts...)
+		if err != nil {
+			return fmt.Errorf("%s: invalid rule %q: %v", path, c.Cel.Rule, err)
+		}
+		e.programs[c.Cel.Rule] = prog
+	case c.Package != nil:
+		if _, ok := e.ranges[c.Package.VersionRange]; ok {
+			return nil
+		}
+		r, err := semver.ParseRange(c.Package.VersionRange)
+		if err != nil {
+			return fmt.Errorf("%s: invalid version range %q: %v", path, c.Package.VersionRange, err)
+		}
+		e.ranges[c.Package.VersionRange] = r
+	case c.GVK != nil:
+	default:
+		for i, nested := range compound(c).Constraints {
+			if err := e.compile(nested, fmt.Sprintf("%s.constraints[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// constraintPath returns the path of a constraint of kind located at path,
+// ex. "all.constraints[1].package".
+func constraintPath(path, kind string) string {
+	if path == "" {
+		return kind
+	}
+	return path + "." + kind
+}
+
+// compound returns the compound constraint of c, which is an all, any or not
+// constraint.
+func compound(c Constraint) *CompoundConstraint {
+	switch {
+	case c.All != nil:
+		return c.All
+	case c.Any != nil:
+		return c.Any
+	default:
+		return c.Not
+	}
+}
+
+// trace evaluates c, which was compiled and is located at path, against the
+// candidate.
+func (e *evaluator) trace(c Constraint, path string, cand *candidateProperties) *Trace {
+	t := &Trace{Path: constraintPath(path, kinds(c)[0]), FailureMessage: c.FailureMessage}
+	switch {
+	case c.Cel != nil:
+		e.traceCel(t, c.Cel, cand)
+	case c.Package != nil:
+		e.tracePackage(t, c.Package, cand)
+	case c.GVK != nil:
+		traceGVK(t, c.GVK, cand)
+	default:
+		e.traceCompound(t, c, cand)
+	}
+	if t.Err != nil && cand.err == nil {
+		cand.err = t.Err
+	}
+	return t
+}
+
+func (e *evaluator) traceCel(t *Trace, c *Cel, cand *candidateProperties) {
+	data, err := cand.celData()
+	if err != nil {
+		t.fail(err)
+		return
+	}
+	satisfied, expressions, err := e.programs[c.Rule].explain(data)
+	t.Expressions = expressions
+	if err != nil {
+		t.fail(fmt.Errorf("cel rule %q: %v", c.Rule, err))
+		return
+	}
+	t.Satisfied = satisfied
+	t.Reason = fmt.Sprintf("rule is %t", satisfied)
+}
+
+func (e *evaluator) tracePackage(t *Trace, c *PackageConstraint, cand *candidateProperties) {
+	var versions []string
+	for _, p := range cand.Properties {
+		if p.Type != packagePropertyType {
+			continue
+		}
+		var pkg struct {
+			PackageName string `json:"packageName"`
+			Version     string `json:"version"`
+		}
+		if err := json.Unmarshal(p.Value, &pkg); err != nil {
+			t.fail(fmt.Errorf("invalid %s property: %v", p.Type, err))
+			continue
+		}
+		if pkg.PackageName != c.PackageName {
+			continue
+		}
+		v, err := semver.Parse(pkg.Version)
+		if err != nil {
+			t.fail(fmt.Errorf("invalid %s property: invalid version %q: %v", p.Type, pkg.Version, err))
+			continue
+		}
+		if e.ranges[c.VersionRange](v) {
+			t.Satisfied = true
+			t.Reason = fmt.Sprintf("package %s %s is in range %q", c.PackageName, pkg.Version, c.VersionRange)
+			return
+		}
+		versions = append(versions, pkg.Version)
+	}
+	if len(versions) == 0 {
+		t.Reason = fmt.Sprintf("no %s property for package %s", packagePropertyType, c.PackageName)
+		return
+	}
+	t.Reason = fmt.Sprintf("package %s %s is not in range %q", c.PackageName, strings.Join(versions, ", "), c.VersionRange)
+}
+
+func traceGVK(t *Trace, c *GVKConstraint, cand *candidateProperties) {
+	for _, p := range cand.Properties {
+		if p.Type != gvkPropertyType {
+			continue
+		}
+		var gvk GVKConstraint
+		if err := json.Unmarshal(p.Value, &gvk); err != nil {
+			t.fail(fmt.Errorf("invalid %s property: %v", p.Type, err))
+			continue
+		}
+		if gvk == *c {
+			t.Satisfied = true
+			t.Reason = fmt.Sprintf("%s property for %s", gvkPropertyType, c)
+			return
+		}
+	}
+	t.Reason = fmt.Sprintf("no %s property for %s", gvkPropertyType, c)
+}
+
+func (e *evaluator) traceCompound(t *Trace, c Constraint, cand *candidateProperties) {
+	var satisfied, unsatisfied []string
+	nested := compound(c).Constraints
+	for i, n := range nested {
+		nt := e.trace(n, fmt.Sprintf("%s.constraints[%d]", t.Path, i), cand)
+		t.Nested = append(t.Nested, nt)
+		if nt.Satisfied {
+			satisfied = append(satisfied, nt.Path)
+		} else {
+			unsatisfied = append(unsatisfied, nt.Path)
+		}
+	}
+	none := fmt.Sprintf("none of the %d constraints is satisfied", len(nested))
+	switch {
+	case c.All != nil:
+		t.Satisfied = len(unsatisfied) == 0
+		t.Reason = fmt.Sprintf("all of the %d constraints are satisfied", len(nested))
+		if !t.Satisfied {
+			t.Reason = "unsatisfied constraints: " + strings.Join(unsatisfied, ", ")
+		}
+	case c.Any != nil:
+		t.Satisfied = len(satisfied) != 0
+		t.Reason = none
+		if t.Satisfied {
+			t.Reason = "satisfied constraints: " + strings.Join(satisfied, ", ")
+		}
+	default:
+		t.Satisfied = len(satisfied) == 0
+		t.Reason = none
+		if !t.Satisfied {
+			t.Reason = "satisfied constraints: " + strings.Join(satisfied, ", ")
+		}
+	}
+}
+
+// candidateProperties is a candidate being evaluated, with its properties
+// decoded for CEL rules and the first error met evaluating it.
+type candidateProperties struct {
+	Candidate
+	data map[string]interface{}
+	err  error
+}
+
+// celData returns the input data of CEL rules for the candidate: its
+// properties, as a list of maps with their type and decoded value.
+func (c *candidateProperties) celData() (map[string]interface{}, error) {
+	if c.data != nil {
+		return c.data, nil
+	}
+	props := make([]map[string]interface{}, 0, len(c.Properties))
+	for _, p := range c.Properties {
+		var value interface{}
+		if err := json.Unmarshal(p.Value, &value); err != nil {
+			return nil, fmt.Errorf("invalid %s property: %v", p.Type, err)
+		}
+		props = append(props, map[string]interface{}{"type": p.Type, "value": value})
+	}
+	c.data = map[string]interface{}{PropertiesKey: props}
+	return c.data, nil
+}
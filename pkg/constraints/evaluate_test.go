@@ -0,0 +1,145 @@
+package constraints
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func etcdCandidates() []Candidate {
+	candidate := func(name, version string, gvkVersions ...string) Candidate {
+		c := Candidate{Name: name, Properties: []Property{
+			{Type: "olm.package", Value: json.RawMessage(`{"packageName":"etcd","version":"` + version + `"}`)},
+		}}
+		for _, v := range gvkVersions {
+			c.Properties = append(c.Properties, Property{
+				Type:  "olm.gvk",
+				Value: json.RawMessage(`{"group":"etcd.database.coreos.com","kind":"EtcdCluster","version":"` + v + `"}`),
+			})
+		}
+		return c
+	}
+	return []Candidate{
+		candidate("etcd.v0.9.0", "0.9.0", "v1beta1"),
+		candidate("etcd.v0.9.4", "0.9.4", "v1beta1", "v1beta2"),
+		candidate("etcd.v1.0.0", "1.0.0", "v1"),
+		{Name: "prometheus.v0.27.0", Properties: []Property{
+			{Type: "olm.package", Value: json.RawMessage(`{"packageName":"prometheus","version":"0.27.0"}`)},
+		}},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	pkg := func(name, versionRange string) Constraint {
+		return Constraint{Package: &PackageConstraint{PackageName: name, VersionRange: versionRange}}
+	}
+	gvk := func(version string) Constraint {
+		return Constraint{GVK: &GVKConstraint{Group: "etcd.database.coreos.com", Kind: "EtcdCluster", Version: version}}
+	}
+	cel := func(rule string) Constraint {
+		return Constraint{Cel: &Cel{Rule: rule}}
+	}
+
+	tests := []struct {
+		name       string
+		constraint Constraint
+		want       []string
+		errStr     string
+	}{
+		{
+			name:       "package",
+			constraint: pkg("etcd", ">=0.9.2"),
+			want:       []string{"etcd.v0.9.4", "etcd.v1.0.0"},
+		},
+		{
+			name:       "gvk",
+			constraint: gvk("v1beta1"),
+			want:       []string{"etcd.v0.9.0", "etcd.v0.9.4"},
+		},
+		{
+			name:       "cel",
+			constraint: cel(`properties.exists(p, p.type == "olm.package" && semver_compare(p.value.version, "0.9.4") <= 0)`),
+			want:       []string{"etcd.v0.9.0", "etcd.v0.9.4"},
+		},
+		{
+			name:       "all",
+			constraint: Constraint{All: &CompoundConstraint{Constraints: []Constraint{pkg("etcd", "<1.0.0"), gvk("v1beta2")}}},
+			want:       []string{"etcd.v0.9.4"},
+		},
+		{
+			name:       "any",
+			constraint: Constraint{Any: &CompoundConstraint{Constraints: []Constraint{gvk("v1"), pkg("prometheus", "0.27.x")}}},
+			want:       []string{"etcd.v1.0.0", "prometheus.v0.27.0"},
+		},
+		{
+			name:       "not",
+			constraint: Constraint{Not: &CompoundConstraint{Constraints: []Constraint{gvk("v1beta1"), gvk("v1")}}},
+			want:       []string{"prometheus.v0.27.0"},
+		},
+		{
+			name: "nested",
+			constraint: Constraint{All: &CompoundConstraint{Constraints: []Constraint{
+				pkg("etcd", ">=0.9.0"),
+				{Not: &CompoundConstraint{Constraints: []Constraint{gvk("v1")}}},
+				{Any: &CompoundConstraint{Constraints: []Constraint{gvk("v1beta2"), pkg("etcd", "0.9.0")}}},
+			}}},
+			want: []string{"etcd.v0.9.0", "etcd.v0.9.4"},
+		},
+		{
+			name:       "no kind",
+			constraint: Constraint{FailureMessage: "blah"},
+			errStr:     "constraint has none of cel, package, gvk, all, any or not",
+		},
+		{
+			name:       "several kinds",
+			constraint: Constraint{All: &CompoundConstraint{Constraints: []Constraint{{Package: &PackageConstraint{}, GVK: &GVKConstraint{}}}}},
+			errStr:     "constraint all.constraints[0] has more than one of package, gvk",
+		},
+		{
+			name:       "invalid version range",
+			constraint: Constraint{Any: &CompoundConstraint{Constraints: []Constraint{gvk("v1"), pkg("etcd", "~>0.9")}}},
+			errStr:     `any.constraints[1].package: invalid version range "~>0.9": Could not parse Range "~>0.9": Could not parse comparator "~>" in "~>0.9"`,
+		},
+		{
+			name:       "invalid cel rule",
+			constraint: Constraint{Not: &CompoundConstraint{Constraints: []Constraint{cel("1")}}},
+			errStr:     `not.constraints[0].cel: invalid rule "1": cel expressions must have type Bool`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := Evaluate(tt.constraint, etcdCandidates())
+			if tt.errStr != "" {
+				require.EqualError(t, err, tt.errStr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, results, len(etcdCandidates()))
+			for _, r := range results {
+				require.NoError(t, r.Err)
+			}
+			require.Equal(t, tt.want, Satisfying(results))
+		})
+	}
+}
+
+func TestEvaluateCandidateErrors(t *testing.T) {
+	candidates := []Candidate{
+		{Name: "a", Properties: []Property{{Type: "olm.package", Value: json.RawMessage(`{"packageName":"etcd","version":"latest"}`)}}},
+		{Name: "b", Properties: []Property{{Type: "olm.test", Value: json.RawMessage(`"not semver"`)}}},
+	}
+	c := Constraint{Any: &CompoundConstraint{Constraints: []Constraint{
+		{Package: &PackageConstraint{PackageName: "etcd", VersionRange: ">=0.9.0"}},
+		{Cel: &Cel{Rule: `properties.exists(p, p.type == "olm.test" && semver_compare(p.value, "1.0.0") > 0)`}},
+	}}}
+
+	results, err := Evaluate(c, candidates)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.False(t, results[0].Satisfied)
+	require.EqualError(t, results[0].Err, `invalid olm.package property: invalid version "latest": No Major.Minor.Patch elements found`)
+	require.False(t, results[1].Satisfied)
+	require.Error(t, results[1].Err)
+	require.Contains(t, results[1].Err.Error(), `unable to parse 'not semver' to semver format`)
+}
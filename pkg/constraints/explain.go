@@ -0,0 +1,158 @@
+package constraints
+
+import (
+	"fmt"
+	"strings"
+
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/interpreter"
+	"github.com/google/cel-go/parser"
+)
+
+// Trace is the trace of the evaluation of a constraint against a candidate,
+// with the traces of its nested constraints.
+type Trace struct {
+	// Path locates the constraint in the constraint evaluated, ex.
+	// "all.constraints[1].package".
+	Path string
+	// FailureMessage is the failure message of the constraint, which OLM
+	// surfaces when it is not satisfied.
+	FailureMessage string
+	// Satisfied is whether the candidate satisfies the constraint.
+	Satisfied bool
+	// Reason explains why the constraint is satisfied or not, ex.
+	// `package etcd 0.9.4 is in range ">=0.9.2"`.
+	Reason string
+	// Err is the error met evaluating the constraint, ex. a CEL rule failing on
+	// the properties of the candidate.
+	Err error
+	// Expressions are the values of the sub-expressions of the rule of a CEL
+	// constraint, starting with the rule itself, in the order they appear in
+	// the rule. The expressions which were not evaluated, such as the right
+	// operand of a false && operator, and the expressions within macros such as
+	// exists(), evaluated for each element of a list, are not included.
+	Expressions []CelExpression
+	// Nested are the traces of the constraints of an all, any or not constraint.
+	Nested []*Trace
+}
+
+// CelExpression is a sub-expression of a CEL rule with its value.
+type CelExpression struct {
+	Expression string
+	Value      string
+}
+
+// Explain evaluates c against each of candidates like Evaluate, returning the
+// trace of each evaluation with the results.
+func Explain(c Constraint, candidates []Candidate) ([]Result, error) {
+	return evaluate(c, candidates, true)
+}
+
+// String returns t and its nested traces, one per line and indented by depth,
+// ex.
+//
+//	all: not satisfied: unsatisfied constraints: all.constraints[1].gvk
+//	  all.constraints[0].package: satisfied: package etcd 0.9.4 is in range ">=0.9.2"
+//	  all.constraints[1].gvk: not satisfied: no olm.gvk property for etcd.database.coreos.com/v1, Kind=EtcdCluster
+func (t *Trace) String() string {
+	b := &strings.Builder{}
+	t.write(b, "")
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (t *Trace) write(b *strings.Builder, indent string) {
+	status := "satisfied"
+	if !t.Satisfied {
+		status = "not satisfied"
+	}
+	fmt.Fprintf(b, "%s%s: %s", indent, t.Path, status)
+	if t.Reason != "" {
+		fmt.Fprintf(b, ": %s", t.Reason)
+	}
+	b.WriteString("\n")
+	if t.Err != nil {
+		fmt.Fprintf(b, "%s  error: %v\n", indent, t.Err)
+	}
+	if !t.Satisfied && t.FailureMessage != "" {
+		fmt.Fprintf(b, "%s  failure message: %s\n", indent, t.FailureMessage)
+	}
+	for _, e := range t.Expressions {
+		fmt.Fprintf(b, "%s  %s = %s\n", indent, e.Expression, e.Value)
+	}
+	for _, nested := range t.Nested {
+		nested.write(b, indent+"  ")
+	}
+}
+
+// fail records err if it is the first error met evaluating the constraint.
+func (t *Trace) fail(err error) {
+	if t.Err == nil {
+		t.Err = err
+	}
+}
+
+// explain evaluates the compiled CEL program against input data like Evaluate,
+// returning the values of its sub-expressions too if the program tracks the
+// state of its evaluation.
+func (e CelProgram) explain(data map[string]interface{}) (bool, []CelExpression, error) {
+	result, details, err := e.eval(data)
+	var expressions []CelExpression
+	if details != nil && details.State() != nil {
+		expressions = celExpressions(e.ast.NativeRep(), details.State())
+	}
+	if err != nil {
+		return false, expressions, err
+	}
+	if b, ok := result.Value().(bool); ok {
+		return b, expressions, nil
+	}
+	return false, expressions, fmt.Errorf("cel expression evalutated to %T, not bool", result.Value())
+}
+
+// celExpressions returns the values recorded in state of the function calls,
+// field selections and macros of a, in order and once per expression.
+func celExpressions(a *celast.AST, state interpreter.EvalState) []CelExpression {
+	var expressions []CelExpression
+	seen := map[string]bool{}
+	var visit func(expr celast.Expr)
+	visit = func(expr celast.Expr) {
+		kind := expr.Kind()
+		if kind != celast.CallKind && kind != celast.SelectKind && kind != celast.ComprehensionKind {
+			return
+		}
+		if val, ok := state.Value(expr.ID()); ok {
+			// Long expressions are written on a single line.
+			if s, err := parser.Unparse(expr, a.SourceInfo(), parser.WrapOnOperators()); err == nil && !seen[s] {
+				seen[s] = true
+				expressions = append(expressions, CelExpression{Expression: s, Value: celValue(val)})
+			}
+		}
+		switch kind {
+		case celast.CallKind:
+			call := expr.AsCall()
+			if call.IsMemberFunction() {
+				visit(call.Target())
+			}
+			for _, arg := range call.Args() {
+				visit(arg)
+			}
+		case celast.SelectKind:
+			visit(expr.AsSelect().Operand())
+		}
+	}
+	visit(a.Expr())
+	return expressions
+}
+
+// celValue returns val formatted for a trace, ex. `"0.9.4"` for a string.
+func celValue(val ref.Val) string {
+	if types.IsError(val) {
+		return fmt.Sprintf("error: %v", val)
+	}
+	if s, ok := val.Value().(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(val.Value())
+}
@@ -0,0 +1,75 @@
+package constraints
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	c := Constraint{FailureMessage: "requires etcd with the v1beta2 API", All: &CompoundConstraint{Constraints: []Constraint{
+		{Package: &PackageConstraint{PackageName: "etcd", VersionRange: ">=0.9.2"}},
+		{Not: &CompoundConstraint{Constraints: []Constraint{{GVK: &GVKConstraint{Group: "etcd.database.coreos.com", Kind: "EtcdCluster", Version: "v1"}}}}},
+		{Cel: &Cel{Rule: `properties.exists(p, p.type == "olm.gvk" && p.value.version == "v1beta2") || size(properties) > 3`}},
+	}}}
+
+	results, err := Explain(c, etcdCandidates())
+	require.NoError(t, err)
+	require.Equal(t, []string{"etcd.v0.9.4"}, Satisfying(results))
+
+	require.Equal(t, `all: not satisfied: unsatisfied constraints: all.constraints[0].package, all.constraints[2].cel
+  failure message: requires etcd with the v1beta2 API
+  all.constraints[0].package: not satisfied: package etcd 0.9.0 is not in range ">=0.9.2"
+  all.constraints[1].not: satisfied: none of the 1 constraints is satisfied
+    all.constraints[1].not.constraints[0].gvk: not satisfied: no olm.gvk property for etcd.database.coreos.com/v1, Kind=EtcdCluster
+  all.constraints[2].cel: not satisfied: rule is false
+    properties.exists(p, p.type == "olm.gvk" && p.value.version == "v1beta2") || size(properties) > 3 = false
+    properties.exists(p, p.type == "olm.gvk" && p.value.version == "v1beta2") = false
+    size(properties) > 3 = false
+    size(properties) = 2`, results[0].Trace.String())
+
+	// The right operand of || is not evaluated when the left one is true.
+	cel := results[1].Trace.Nested[2]
+	require.True(t, cel.Satisfied)
+	require.Equal(t, []CelExpression{
+		{Expression: `properties.exists(p, p.type == "olm.gvk" && p.value.version == "v1beta2") || size(properties) > 3`, Value: "true"},
+		{Expression: `properties.exists(p, p.type == "olm.gvk" && p.value.version == "v1beta2")`, Value: "true"},
+	}, cel.Expressions)
+
+	require.Equal(t, `all: not satisfied: unsatisfied constraints: all.constraints[1].not, all.constraints[2].cel
+  failure message: requires etcd with the v1beta2 API
+  all.constraints[0].package: satisfied: package etcd 1.0.0 is in range ">=0.9.2"
+  all.constraints[1].not: not satisfied: satisfied constraints: all.constraints[1].not.constraints[0].gvk
+    all.constraints[1].not.constraints[0].gvk: satisfied: olm.gvk property for etcd.database.coreos.com/v1, Kind=EtcdCluster
+  all.constraints[2].cel: not satisfied: rule is false
+    properties.exists(p, p.type == "olm.gvk" && p.value.version == "v1beta2") || size(properties) > 3 = false
+    properties.exists(p, p.type == "olm.gvk" && p.value.version == "v1beta2") = false
+    size(properties) > 3 = false
+    size(properties) = 2`, results[2].Trace.String())
+
+	// Evaluate does not return traces.
+	results, err = Evaluate(c, etcdCandidates())
+	require.NoError(t, err)
+	for _, r := range results {
+		require.Nil(t, r.Trace)
+	}
+}
+
+func TestExplainErrors(t *testing.T) {
+	c := Constraint{Any: &CompoundConstraint{Constraints: []Constraint{
+		{Cel: &Cel{Rule: `properties.exists(p, p.type == "olm.package" && semver_compare(p.value.packageName, "1.0.0") > 0)`}},
+		{Package: &PackageConstraint{PackageName: "etcd", VersionRange: "<0.9.4"}},
+	}}}
+	results, err := Explain(c, etcdCandidates()[:1])
+	require.NoError(t, err)
+	require.True(t, results[0].Satisfied)
+	require.Error(t, results[0].Err)
+
+	trace := results[0].Trace
+	require.Equal(t, "satisfied constraints: any.constraints[1].package", trace.Reason)
+	require.NoError(t, trace.Err)
+	require.False(t, trace.Nested[0].Satisfied)
+	require.Equal(t, results[0].Err, trace.Nested[0].Err)
+	require.Contains(t, trace.Nested[0].Err.Error(), `unable to parse 'etcd' to semver format`)
+	require.Contains(t, trace.String(), "  any.constraints[0].cel: not satisfied\n    error: cel rule")
+}
@@ -0,0 +1,520 @@
+package constraints
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver/v4"
+)
+
+// ProblemKind is the kind of a problem found by Lint.
+type ProblemKind string
+
+const (
+	// ProblemInvalid is a constraint which cannot be evaluated, ex. because its
+	// version range is invalid or its list of constraints is empty.
+	ProblemInvalid ProblemKind = "invalid"
+	// ProblemUnsatisfiable is a constraint which no bundle satisfies.
+	ProblemUnsatisfiable ProblemKind = "unsatisfiable"
+	// ProblemAlwaysSatisfied is a constraint which every bundle satisfies.
+	ProblemAlwaysSatisfied ProblemKind = "always-satisfied"
+	// ProblemRedundant is a constraint of an all, any or not constraint whose
+	// removal does not change which bundles satisfy the constraint linted.
+	ProblemRedundant ProblemKind = "redundant"
+)
+
+// Problem is a problem of a constraint found by Lint.
+type Problem struct {
+	Kind ProblemKind
+	// Path locates the constraint with the problem in the constraint linted,
+	// ex. "all.constraints[1]", and is empty for the constraint linted itself.
+	Path   string
+	Detail string
+}
+
+// String returns the path and detail of p, ex.
+// "all.constraints[1]: duplicate of all.constraints[0]".
+func (p Problem) String() string {
+	if p.Path == "" {
+		return p.Detail
+	}
+	return fmt.Sprintf("%s: %s", p.Path, p.Detail)
+}
+
+// maxLintAtoms and maxLintBundles bound the number of distinct GVK and CEL
+// constraints, and the number of bundles, Lint enumerates to find the
+// unsatisfiable, always satisfied and redundant constraints.
+const (
+	maxLintAtoms   = 10
+	maxLintBundles = 1 << 16
+)
+
+// Lint returns the problems of c and of the constraints nested in it, in
+// order. The invalid constraints are reported first: if there are any, c is
+// not analyzed further. Otherwise Lint reports the constraints which no bundle
+// or every bundle satisfies, ex. all{package foo >=2.0.0, not{package foo
+// >=1.0.0}}, and the redundant constraints of all, any and not constraints,
+// such as duplicates or branches which can never change the outcome.
+//
+// Lint assumes that a bundle has a single olm.package property, as OLM
+// requires, and that the GVK and CEL constraints are independent of each other
+// and of the package constraints: only CEL rules written identically are
+// known to be equivalent. Constraints with more than 10 distinct GVK and CEL
+// constraints are not analyzed.
+func Lint(c Constraint) []Problem {
+	l := &linter{env: NewCelEnvironment()}
+	l.check(c, "")
+	if len(l.problems) != 0 {
+		return l.problems
+	}
+	if a := newAnalysis(c); a != nil {
+		l.problems = a.report(c)
+	}
+	return l.problems
+}
+
+// linter finds the invalid constraints of a constraint.
+type linter struct {
+	env      *CelEnvironment
+	problems []Problem
+}
+
+func (l *linter) add(path, detail string) {
+	l.problems = append(l.problems, Problem{Kind: ProblemInvalid, Path: path, Detail: detail})
+}
+
+// check reports the invalid constraints of c, located at path, and of the
+// constraints nested in it.
+func (l *linter) check(c Constraint, path string) {
+	kinds := kinds(c)
+	if len(kinds) != 1 {
+		l.add(path, fmt.Sprintf("constraint must set exactly one of cel, package, gvk, all, any or not, found %d", len(kinds)))
+		return
+	}
+	switch {
+	case c.Cel != nil:
+		if c.Cel.Rule == "" {
+			l.add(path, "cel rule is empty")
+		} else if _, err := l.env.Validate(c.Cel.Rule); err != nil {
+			l.add(path, fmt.Sprintf("cel rule %q is not valid: %v", c.Cel.Rule, err))
+		}
+	case c.Package != nil:
+		if c.Package.PackageName == "" {
+			l.add(path, "packageName is empty")
+		}
+		if _, err := semver.ParseRange(c.Package.VersionRange); err != nil {
+			l.add(path, fmt.Sprintf("versionRange %q is not a valid semantic version range: %v", c.Package.VersionRange, err))
+		}
+	case c.GVK != nil:
+		if c.GVK.Kind == "" {
+			l.add(path, "kind is empty")
+		}
+		if c.GVK.Version == "" {
+			l.add(path, "version is empty")
+		}
+	default:
+		constraints := compound(c).Constraints
+		if len(constraints) == 0 {
+			l.add(path, fmt.Sprintf("%s has no constraints", kinds[0]))
+		}
+		for i, nested := range constraints {
+			l.check(nested, nestedPath(path, kinds[0], i))
+		}
+	}
+}
+
+// nestedPath returns the path of the constraint i of the compound constraint
+// of kind located at path, ex. "all.constraints[1]".
+func nestedPath(path, kind string, i int) string {
+	nested := fmt.Sprintf("%s.constraints[%d]", kind, i)
+	if path == "" {
+		return nested
+	}
+	return path + "." + nested
+}
+
+// Normalize returns c, which must be valid, with equivalent nested constraints
+// simplified:
+//   - the all and any constraints nested in a constraint of the same kind, and
+//     the any constraints nested in a not constraint, are flattened into it;
+//   - the duplicate constraints of an all, any or not constraint are removed;
+//   - an all or any constraint of a single constraint is replaced with it, as
+//     is a not constraint of a single not constraint of a single constraint.
+//
+// The failure messages of the nested constraints are kept: constraints with a
+// failure message are neither flattened nor replaced if that would drop it.
+func Normalize(c Constraint) Constraint {
+	if len(kinds(c)) != 1 || c.All == nil && c.Any == nil && c.Not == nil {
+		return c
+	}
+	var constraints []Constraint
+	seen := map[string]bool{}
+	var add func(n Constraint)
+	add = func(n Constraint) {
+		n = Normalize(n)
+		if n.FailureMessage == "" && len(kinds(n)) == 1 &&
+			(c.All != nil && n.All != nil || c.Any != nil && n.Any != nil || c.Not != nil && n.Any != nil) {
+			for _, nn := range compound(n).Constraints {
+				add(nn)
+			}
+			return
+		}
+		if k := key(n); !seen[k] {
+			seen[k] = true
+			constraints = append(constraints, n)
+		}
+	}
+	for _, n := range compound(c).Constraints {
+		add(n)
+	}
+
+	if len(constraints) == 1 {
+		single, replace := constraints[0], c.All != nil || c.Any != nil
+		if c.Not != nil && single.Not != nil && len(single.Not.Constraints) == 1 && single.FailureMessage == "" {
+			single, replace = single.Not.Constraints[0], true
+		}
+		if replace && (c.FailureMessage == "" || single.FailureMessage == "") {
+			if single.FailureMessage == "" {
+				single.FailureMessage = c.FailureMessage
+			}
+			return single
+		}
+	}
+	normalized := Constraint{FailureMessage: c.FailureMessage}
+	switch {
+	case c.All != nil:
+		normalized.All = &CompoundConstraint{Constraints: constraints}
+	case c.Any != nil:
+		normalized.Any = &CompoundConstraint{Constraints: constraints}
+	default:
+		normalized.Not = &CompoundConstraint{Constraints: constraints}
+	}
+	return normalized
+}
+
+// key returns a key identifying c, which is the same for the constraints which
+// only differ by their failure messages.
+func key(c Constraint) string {
+	data, _ := json.Marshal(withoutFailureMessages(c))
+	return string(data)
+}
+
+// withoutFailureMessages returns c and its nested constraints without their
+// failure messages.
+func withoutFailureMessages(c Constraint) Constraint {
+	c.FailureMessage = ""
+	for _, compound := range []**CompoundConstraint{&c.All, &c.Any, &c.Not} {
+		if *compound == nil {
+			continue
+		}
+		nested := make([]Constraint, 0, len((*compound).Constraints))
+		for _, n := range (*compound).Constraints {
+			nested = append(nested, withoutFailureMessages(n))
+		}
+		*compound = &CompoundConstraint{Constraints: nested}
+	}
+	return c
+}
+
+// analysis finds the unsatisfiable, always satisfied and redundant
+// constraints of a valid constraint by evaluating it against every bundle
+// which may satisfy it differently: a bundle is a package and version, and the
+// set of the GVK and CEL constraints it satisfies, or atoms.
+type analysis struct {
+	// packages are the packages and versions of the bundles: a version of each
+	// interval between the versions bounding the version ranges of the package
+	// constraints, and an empty package standing for every other package.
+	packages []packageVersion
+	// atoms indexes the keys of the GVK and CEL constraints.
+	atoms map[string]int
+	// removed are the paths of the constraints found redundant so far, which
+	// are not evaluated anymore.
+	removed map[string]bool
+}
+
+type packageVersion struct {
+	name    string
+	version semver.Version
+}
+
+// newAnalysis returns the analysis of c, or nil if c has too many atoms or
+// bundles to analyze.
+func newAnalysis(c Constraint) *analysis {
+	a := &analysis{atoms: map[string]int{}, removed: map[string]bool{}}
+	ranges := map[string][]string{}
+	var collect func(c Constraint)
+	collect = func(c Constraint) {
+		switch {
+		case c.Package != nil:
+			ranges[c.Package.PackageName] = append(ranges[c.Package.PackageName], c.Package.VersionRange)
+		case c.GVK != nil, c.Cel != nil:
+			if k := atomKey(c); a.atoms[k] == 0 {
+				a.atoms[k] = len(a.atoms) + 1
+			}
+		default:
+			for _, n := range compound(c).Constraints {
+				collect(n)
+			}
+		}
+	}
+	collect(c)
+	if len(a.atoms) > maxLintAtoms {
+		return nil
+	}
+	for k := range a.atoms {
+		// Atoms are numbered from 1 while collected.
+		a.atoms[k]--
+	}
+
+	names := make([]string, 0, len(ranges))
+	for name := range ranges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	a.packages = append(a.packages, packageVersion{})
+	for _, name := range names {
+		for _, v := range rangeVersions(ranges[name]) {
+			a.packages = append(a.packages, packageVersion{name: name, version: v})
+		}
+	}
+	if len(a.packages)<<len(a.atoms) > maxLintBundles {
+		return nil
+	}
+	return a
+}
+
+// atomKey returns the key of the GVK or CEL constraint c.
+func atomKey(c Constraint) string {
+	if c.GVK != nil {
+		return "gvk " + c.GVK.String()
+	}
+	return "cel " + c.Cel.Rule
+}
+
+// versionPattern matches the versions of version ranges, ex. "1.2.3-beta.1" or "1.x".
+var versionPattern = regexp.MustCompile(`\d+(\.(\d+|[xX*]))*(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?`)
+
+// rangeVersions returns versions which each version range of ranges is
+// satisfied by either all or none of the versions of the interval between
+// consecutive ones they are in: the versions bounding the ranges, their
+// successors and the lowest version.
+func rangeVersions(ranges []string) []semver.Version {
+	lowest := semver.Version{Pre: []semver.PRVersion{{VersionNum: 0, IsNum: true}}}
+	versions := []semver.Version{lowest}
+	for _, r := range ranges {
+		for _, v := range boundingVersions(r) {
+			versions = append(versions, v, successor(v))
+		}
+	}
+	semver.Sort(versions)
+	unique := versions[:1]
+	for _, v := range versions[1:] {
+		if !v.Equals(unique[len(unique)-1]) {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// boundingVersions returns the versions bounding the version range r: the
+// versions it compares to, and for wildcards such as "1.2.x" the lowest version
+// they match and the lowest greater version they do not match.
+func boundingVersions(r string) []semver.Version {
+	var versions []semver.Version
+	for _, match := range versionPattern.FindAllString(r, -1) {
+		core, suffix := match, ""
+		if i := strings.IndexAny(match, "-+"); i >= 0 {
+			core, suffix = match[:i], match[i:]
+		}
+		parts := strings.Split(core, ".")
+		wildcard := len(parts)
+		for i, part := range parts {
+			if _, err := strconv.ParseUint(part, 10, 64); err != nil {
+				wildcard = i
+				break
+			}
+		}
+		nums := make([]uint64, 3)
+		for i := 0; i < min(wildcard, 3); i++ {
+			nums[i], _ = strconv.ParseUint(parts[i], 10, 64)
+		}
+		if wildcard == len(parts) {
+			if v, err := semver.Parse(fmt.Sprintf("%d.%d.%d%s", nums[0], nums[1], nums[2], suffix)); err == nil {
+				versions = append(versions, v)
+			}
+			continue
+		}
+		versions = append(versions, semver.Version{Major: nums[0], Minor: nums[1], Patch: nums[2]})
+		if wildcard > 0 && wildcard <= 3 {
+			nums[wildcard-1]++
+			for i := wildcard; i < 3; i++ {
+				nums[i] = 0
+			}
+			versions = append(versions, semver.Version{Major: nums[0], Minor: nums[1], Patch: nums[2]})
+		}
+	}
+	return versions
+}
+
+// successor returns the lowest version greater than v.
+func successor(v semver.Version) semver.Version {
+	zero := semver.PRVersion{VersionNum: 0, IsNum: true}
+	if len(v.Pre) == 0 {
+		return semver.Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1, Pre: []semver.PRVersion{zero}}
+	}
+	pre := append(append([]semver.PRVersion{}, v.Pre...), zero)
+	return semver.Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch, Pre: pre}
+}
+
+// bundles returns the number of bundles of the analysis.
+func (a *analysis) bundles() int {
+	return len(a.packages) << len(a.atoms)
+}
+
+// satisfied returns whether each bundle satisfies c, located at path, without
+// the constraints found redundant.
+func (a *analysis) satisfied(c Constraint, path string) []bool {
+	result := make([]bool, a.bundles())
+	switch {
+	case c.Package != nil:
+		r := semver.MustParseRange(c.Package.VersionRange)
+		for b := range result {
+			pkg := a.packages[b>>len(a.atoms)]
+			result[b] = pkg.name == c.Package.PackageName && r(pkg.version)
+		}
+	case c.GVK != nil, c.Cel != nil:
+		atom := a.atoms[atomKey(c)]
+		for b := range result {
+			result[b] = b&(1<<atom) != 0
+		}
+	default:
+		kind := kinds(c)[0]
+		for b := range result {
+			result[b] = c.All != nil || c.Not != nil
+		}
+		for i, n := range compound(c).Constraints {
+			p := nestedPath(path, kind, i)
+			if a.removed[p] {
+				continue
+			}
+			nested := a.satisfied(n, p)
+			for b := range result {
+				switch {
+				case c.All != nil:
+					result[b] = result[b] && nested[b]
+				case c.Any != nil:
+					result[b] = result[b] || nested[b]
+				default:
+					result[b] = result[b] && !nested[b]
+				}
+			}
+		}
+	}
+	return result
+}
+
+// report returns the problems of root.
+func (a *analysis) report(root Constraint) []Problem {
+	var problems []Problem
+	// satisfiability returns the problem of c, located at path, if it is
+	// unsatisfiable or always satisfied.
+	satisfiability := func(c Constraint, path string) (Problem, bool) {
+		satisfied := a.satisfied(c, path)
+		switch {
+		case !anyTrue(satisfied):
+			return Problem{Kind: ProblemUnsatisfiable, Path: path, Detail: "constraint can never be satisfied"}, true
+		case allTrue(satisfied):
+			return Problem{Kind: ProblemAlwaysSatisfied, Path: path, Detail: "constraint is always satisfied"}, true
+		}
+		return Problem{}, false
+	}
+	if problem, ok := satisfiability(root, ""); ok {
+		return []Problem{problem}
+	}
+
+	rootSatisfied := a.satisfied(root, "")
+	var visit func(c Constraint, path string)
+	visit = func(c Constraint, path string) {
+		if c.All == nil && c.Any == nil && c.Not == nil {
+			return
+		}
+		kind := kinds(c)[0]
+		constraints := compound(c).Constraints
+		paths := make([]string, len(constraints))
+		nested := make([]*Problem, len(constraints))
+		remove := func(i int, problem Problem) {
+			a.removed[paths[i]] = true
+			nested[i] = &problem
+		}
+		// Since c is neither unsatisfiable nor always satisfied, the nested
+		// constraints which are can be removed. The duplicates are found before
+		// the other redundant constraints, so that the first of them is kept.
+		keys := map[string]string{}
+		for i, n := range constraints {
+			paths[i] = nestedPath(path, kind, i)
+			if problem, ok := satisfiability(n, paths[i]); ok {
+				remove(i, problem)
+				continue
+			}
+			k := key(Normalize(n))
+			if first, ok := keys[k]; ok {
+				remove(i, Problem{Kind: ProblemRedundant, Path: paths[i], Detail: fmt.Sprintf("duplicate of %s", first)})
+				continue
+			}
+			keys[k] = paths[i]
+		}
+		remaining := len(keys)
+		for i := range constraints {
+			if nested[i] != nil || remaining == 1 {
+				continue
+			}
+			a.removed[paths[i]] = true
+			if equal(rootSatisfied, a.satisfied(root, "")) {
+				nested[i] = &Problem{Kind: ProblemRedundant, Path: paths[i], Detail: "removing it does not change which bundles satisfy the constraint"}
+				remaining--
+				continue
+			}
+			delete(a.removed, paths[i])
+		}
+		for i, n := range constraints {
+			if nested[i] != nil {
+				problems = append(problems, *nested[i])
+				continue
+			}
+			visit(n, paths[i])
+		}
+	}
+	visit(root, "")
+	return problems
+}
+
+func anyTrue(values []bool) bool {
+	for _, v := range values {
+		if v {
+			return true
+		}
+	}
+	return false
+}
+
+func allTrue(values []bool) bool {
+	for _, v := range values {
+		if !v {
+			return false
+		}
+	}
+	return true
+}
+
+func equal(a, b []bool) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
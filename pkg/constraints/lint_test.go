@@ -0,0 +1,143 @@
+package constraints
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	pkg := func(name, versionRange string) Constraint {
+		return Constraint{Package: &PackageConstraint{PackageName: name, VersionRange: versionRange}}
+	}
+	gvk := func(version string) Constraint {
+		return Constraint{GVK: &GVKConstraint{Group: "etcd.database.coreos.com", Kind: "EtcdCluster", Version: version}}
+	}
+	all := func(constraints ...Constraint) Constraint {
+		return Constraint{All: &CompoundConstraint{Constraints: constraints}}
+	}
+	any := func(constraints ...Constraint) Constraint {
+		return Constraint{Any: &CompoundConstraint{Constraints: constraints}}
+	}
+	not := func(constraints ...Constraint) Constraint {
+		return Constraint{Not: &CompoundConstraint{Constraints: constraints}}
+	}
+
+	tests := []struct {
+		name       string
+		constraint Constraint
+		want       []string
+	}{
+		{
+			name:       "valid",
+			constraint: all(pkg("etcd", ">=0.9.0"), not(gvk("v1")), Constraint{Cel: &Cel{Rule: `properties.exists(p, p.type == "certified")`}}),
+		},
+		{
+			name: "invalid",
+			constraint: any(
+				Constraint{},
+				pkg("", "~>1"),
+				Constraint{GVK: &GVKConstraint{Group: "etcd.database.coreos.com"}},
+				not(),
+				Constraint{Cel: &Cel{}},
+			),
+			want: []string{
+				"invalid: any.constraints[0]: constraint must set exactly one of cel, package, gvk, all, any or not, found 0",
+				"invalid: any.constraints[1]: packageName is empty",
+				`invalid: any.constraints[1]: versionRange "~>1" is not a valid semantic version range: Could not parse Range "~>1": Could not parse comparator "~>" in "~>1"`,
+				"invalid: any.constraints[2]: kind is empty",
+				"invalid: any.constraints[2]: version is empty",
+				"invalid: any.constraints[3]: not has no constraints",
+				"invalid: any.constraints[4]: cel rule is empty",
+			},
+		},
+		{
+			name:       "contradiction",
+			constraint: all(pkg("foo", ">=2.0.0"), not(pkg("foo", ">=1.0.0"))),
+			want:       []string{"unsatisfiable: constraint can never be satisfied"},
+		},
+		{
+			name:       "different packages",
+			constraint: all(pkg("foo", ">=1.0.0"), pkg("bar", ">=1.0.0")),
+			want:       []string{"unsatisfiable: constraint can never be satisfied"},
+		},
+		{
+			name:       "tautology",
+			constraint: any(gvk("v1"), not(gvk("v1"))),
+			want:       []string{"always-satisfied: constraint is always satisfied"},
+		},
+		{
+			name:       "wildcard ranges",
+			constraint: all(pkg("foo", "1.x"), not(pkg("foo", ">=1.0.0 <2.0.0"))),
+			want:       []string{"unsatisfiable: constraint can never be satisfied"},
+		},
+		{
+			name:       "prerelease versions",
+			constraint: all(pkg("foo", ">=1.0.0-0 <1.0.0"), not(pkg("foo", "<1.0.0-alpha"))),
+		},
+		{
+			name: "dead branches",
+			constraint: all(
+				pkg("foo", ">=2.0.0"),
+				any(pkg("foo", "<1.0.0"), gvk("v1")),
+				any(gvk("v1beta1"), all(pkg("foo", "<1.0.0"), pkg("foo", ">1.0.0"))),
+			),
+			want: []string{
+				"redundant: all.constraints[1].any.constraints[0]: removing it does not change which bundles satisfy the constraint",
+				"unsatisfiable: all.constraints[2].any.constraints[1]: constraint can never be satisfied",
+			},
+		},
+		{
+			name: "redundant constraints",
+			constraint: any(
+				gvk("v1"),
+				pkg("foo", ">=1.0.0"),
+				Constraint{FailureMessage: "needs v1", GVK: &GVKConstraint{Group: "etcd.database.coreos.com", Kind: "EtcdCluster", Version: "v1"}},
+				any(gvk("v1")),
+				pkg("foo", ">=2.0.0"),
+				all(gvk("v1beta1"), not(gvk("v1beta1"), gvk("v1"))),
+			),
+			want: []string{
+				"redundant: any.constraints[2]: duplicate of any.constraints[0]",
+				"redundant: any.constraints[3]: duplicate of any.constraints[0]",
+				"redundant: any.constraints[4]: removing it does not change which bundles satisfy the constraint",
+				"unsatisfiable: any.constraints[5]: constraint can never be satisfied",
+			},
+		},
+		{
+			name:       "always satisfied nested constraint",
+			constraint: all(pkg("foo", ">=1.0.0"), any(gvk("v1"), not(gvk("v1")))),
+			want:       []string{"always-satisfied: all.constraints[1]: constraint is always satisfied"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var problems []string
+			for _, p := range Lint(tt.constraint) {
+				problems = append(problems, string(p.Kind)+": "+p.String())
+			}
+			require.Equal(t, tt.want, problems)
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	a := Constraint{GVK: &GVKConstraint{Group: "etcd.database.coreos.com", Kind: "EtcdCluster", Version: "v1"}}
+	b := Constraint{Package: &PackageConstraint{PackageName: "etcd", VersionRange: ">=1.0.0"}}
+	c := Constraint{FailureMessage: "certified", Cel: &Cel{Rule: `properties.exists(p, p.type == "certified")`}}
+
+	require.Equal(t, Constraint{FailureMessage: "requires etcd", All: &CompoundConstraint{Constraints: []Constraint{a, b, c}}},
+		Normalize(Constraint{FailureMessage: "requires etcd", All: &CompoundConstraint{Constraints: []Constraint{
+			a,
+			{All: &CompoundConstraint{Constraints: []Constraint{b, a, {All: &CompoundConstraint{Constraints: []Constraint{c}}}}}},
+		}}}))
+	require.Equal(t, Constraint{Not: &CompoundConstraint{Constraints: []Constraint{a, b}}},
+		Normalize(Constraint{Not: &CompoundConstraint{Constraints: []Constraint{{Any: &CompoundConstraint{Constraints: []Constraint{a, b}}}}}}))
+	require.Equal(t, Constraint{FailureMessage: "requires etcd", GVK: a.GVK},
+		Normalize(Constraint{FailureMessage: "requires etcd", Not: &CompoundConstraint{Constraints: []Constraint{
+			{Not: &CompoundConstraint{Constraints: []Constraint{a}}},
+		}}}))
+	// Failure messages are not dropped.
+	withMessage := Constraint{FailureMessage: "requires certification", Any: &CompoundConstraint{Constraints: []Constraint{c}}}
+	require.Equal(t, withMessage, Normalize(withMessage))
+}
@@ -0,0 +1,174 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+
+	"github.com/operator-framework/api/pkg/manifests"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+// FromBundles returns the upgrade graphs of the channels of bundles, such as
+// the bundles of a package loaded with manifests.GetBundleFromDir, sorted by
+// package and channel. Bundles are in the channels of their Package and
+// Channels, and their upgrade edges are taken from the replaces and skips of
+// their CSV and its olm.skipRange annotation.
+//
+// An error is returned for the bundles which have no CSV, package or channel,
+// which are left out of the graphs.
+func FromBundles(bundles []*manifests.Bundle) ([]*Graph, error) {
+	type channelKey struct{ pkg, channel string }
+	nodes := map[channelKey][]*Node{}
+	var errs []string
+	for _, bundle := range bundles {
+		switch {
+		case bundle.CSV == nil:
+			errs = append(errs, fmt.Sprintf("bundle %s has no CSV", bundle.Name))
+			continue
+		case bundle.Package == "":
+			errs = append(errs, fmt.Sprintf("bundle %s has no package", bundle.Name))
+			continue
+		case len(bundle.Channels) == 0:
+			errs = append(errs, fmt.Sprintf("bundle %s is not in any channel", bundle.Name))
+			continue
+		}
+		for _, channel := range bundle.Channels {
+			key := channelKey{bundle.Package, channel}
+			nodes[key] = append(nodes[key], csvNode(bundle.CSV))
+		}
+	}
+
+	keys := make([]channelKey, 0, len(nodes))
+	for key := range nodes {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].pkg != keys[j].pkg {
+			return keys[i].pkg < keys[j].pkg
+		}
+		return keys[i].channel < keys[j].channel
+	})
+	graphs := make([]*Graph, 0, len(keys))
+	for _, key := range keys {
+		graphs = append(graphs, New(key.pkg, key.channel, nodes[key]))
+	}
+	return graphs, buildError(errs)
+}
+
+// FromPackageManifest returns the upgrade graphs of the channels of the package
+// manifest pkg, in the order of its channels, from its bundles, as loaded by
+// manifests.GetManifestsDir. The head of each graph is the current CSV of its
+// channel, and its bundles are the ones returned by
+// manifests.PackageChannel.Bundles: the ones the current CSV replaces or skips,
+// directly or not, or skips with its olm.skipRange, as the package manifest
+// format defines them.
+//
+// An error is returned for the channels whose current CSV is not found, and
+// for the bundles which are in no channel.
+func FromPackageManifest(pkg *manifests.PackageManifest, bundles []*manifests.Bundle) ([]*Graph, error) {
+	byName := map[string]*Node{}
+	for _, bundle := range bundles {
+		if bundle.CSV != nil {
+			byName[bundle.CSV.GetName()] = csvNode(bundle.CSV)
+		}
+	}
+
+	var graphs []*Graph
+	var errs []string
+	inChannel := map[string]bool{}
+	for _, channel := range pkg.Channels {
+		channelBundles := channel.Bundles(bundles)
+		if len(channelBundles) == 0 {
+			errs = append(errs, fmt.Sprintf("current CSV %s of channel %s not found", channel.CurrentCSVName, channel.Name))
+			continue
+		}
+		nodes := make([]*Node, 0, len(channelBundles))
+		for _, bundle := range channelBundles {
+			name := bundle.CSV.GetName()
+			inChannel[name] = true
+			nodes = append(nodes, byName[name])
+		}
+		g := New(pkg.PackageName, channel.Name, nodes)
+		g.Head = channel.CurrentCSVName
+		graphs = append(graphs, g)
+	}
+	for name := range byName {
+		if !inChannel[name] {
+			errs = append(errs, fmt.Sprintf("bundle %s is not in any channel", name))
+		}
+	}
+	return graphs, buildError(errs)
+}
+
+// FromCatalog returns the upgrade graphs of the channels of the file-based
+// catalog, in the order of its channels. The upgrade edges are taken from the
+// entries of the channels, and the versions of the bundles from their
+// olm.package property.
+//
+// An error is returned for the bundles whose olm.package property is invalid,
+// which are in the graphs without a version.
+func FromCatalog(catalog *manifests.Catalog) ([]*Graph, error) {
+	versions := map[string]*semver.Version{}
+	var errs []string
+	for _, bundle := range catalog.Bundles {
+		for _, property := range bundle.Properties {
+			if property.Type != manifests.PropertyPackage {
+				continue
+			}
+			v, err := property.Parse()
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("bundle %s: %v", bundle.Name, err))
+				continue
+			}
+			version, err := semver.Parse(v.(manifests.PackageProperty).Version)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("bundle %s: invalid version: %v", bundle.Name, err))
+				continue
+			}
+			versions[bundle.Package+"/"+bundle.Name] = &version
+		}
+	}
+
+	graphs := make([]*Graph, 0, len(catalog.Channels))
+	for _, channel := range catalog.Channels {
+		nodes := make([]*Node, 0, len(channel.Entries))
+		for _, entry := range channel.Entries {
+			nodes = append(nodes, &Node{
+				Name:      entry.Name,
+				Version:   versions[channel.Package+"/"+entry.Name],
+				Replaces:  entry.Replaces,
+				Skips:     entry.Skips,
+				SkipRange: entry.SkipRange,
+			})
+		}
+		graphs = append(graphs, New(channel.Package, channel.Name, nodes))
+	}
+	return graphs, buildError(errs)
+}
+
+// csvNode returns the node of the bundle of csv.
+func csvNode(csv *operatorsv1alpha1.ClusterServiceVersion) *Node {
+	n := &Node{
+		Name:      csv.GetName(),
+		Replaces:  csv.Spec.Replaces,
+		Skips:     csv.Spec.Skips,
+		SkipRange: csv.GetAnnotations()[operatorsv1alpha1.SkipRangeAnnotationKey],
+	}
+	if !csv.Spec.Version.Equals(semver.Version{}) {
+		version := csv.Spec.Version.Version
+		n.Version = &version
+	}
+	return n
+}
+
+// buildError returns the error of the problems errs found building graphs, if any.
+func buildError(errs []string) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(errs)
+	return fmt.Errorf("unable to build the upgrade graph: %s", strings.Join(errs, ", "))
+}
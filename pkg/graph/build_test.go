@@ -0,0 +1,114 @@
+package graph
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/api/pkg/lib/version"
+	"github.com/operator-framework/api/pkg/manifests"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+func bundle(name, ver, replaces, skipRange string, channels ...string) *manifests.Bundle {
+	csv := &operatorsv1alpha1.ClusterServiceVersion{}
+	csv.SetName(name)
+	csv.Spec.Replaces = replaces
+	if ver != "" {
+		csv.Spec.Version = version.OperatorVersion{Version: semver.MustParse(ver)}
+	}
+	if skipRange != "" {
+		csv.SetAnnotations(map[string]string{operatorsv1alpha1.SkipRangeAnnotationKey: skipRange})
+	}
+	return &manifests.Bundle{Name: name, CSV: csv, Package: "etcd", Channels: channels}
+}
+
+func TestFromBundles(t *testing.T) {
+	noPackage := bundle("other.v1.0.0", "1.0.0", "", "", "alpha")
+	noPackage.Package = ""
+	graphs, err := FromBundles([]*manifests.Bundle{
+		bundle("etcd.v0.9.2", "0.9.2", "", "", "alpha", "stable"),
+		bundle("etcd.v0.9.4", "0.9.4", "etcd.v0.9.2", ">=0.9.0 <0.9.4", "stable"),
+		bundle("etcd.v0.10.0", "0.10.0", "etcd.v0.9.2", "", "alpha"),
+		bundle("etcd.v0.11.0", "", "", ""),
+		noPackage,
+		{Name: "no-csv"},
+	})
+	require.EqualError(t, err, "unable to build the upgrade graph: bundle etcd.v0.11.0 is not in any channel, "+
+		"bundle no-csv has no CSV, bundle other.v1.0.0 has no package")
+	require.Len(t, graphs, 2)
+
+	require.Equal(t, "etcd", graphs[0].Package)
+	require.Equal(t, "alpha", graphs[0].Channel)
+	require.Equal(t, []Edge{{From: "etcd.v0.9.2", To: "etcd.v0.10.0", Kind: EdgeReplaces}}, graphs[0].Edges())
+
+	require.Equal(t, "stable", graphs[1].Channel)
+	require.Empty(t, graphs[1].Head)
+	require.Equal(t, []Edge{
+		{From: "etcd.v0.9.2", To: "etcd.v0.9.4", Kind: EdgeReplaces},
+		{From: "etcd.v0.9.2", To: "etcd.v0.9.4", Kind: EdgeSkipRange},
+	}, graphs[1].Edges())
+	require.Equal(t, "0.9.4", graphs[1].Node("etcd.v0.9.4").Version.String())
+}
+
+func TestFromPackageManifest(t *testing.T) {
+	pkg, bundles, err := manifests.GetManifestsDir("../manifests/testdata/valid_package")
+	require.NoError(t, err)
+	graphs, err := FromPackageManifest(pkg, bundles)
+	require.NoError(t, err)
+	require.Len(t, graphs, 1)
+	require.Equal(t, "etcd", graphs[0].Package)
+	require.Equal(t, "singlenamespace-alpha", graphs[0].Channel)
+	require.Equal(t, "etcdoperator.v0.9.4", graphs[0].Head)
+	require.Equal(t, []Edge{{From: "etcdoperator.v0.9.2", To: "etcdoperator.v0.9.4", Kind: EdgeReplaces}}, graphs[0].Edges())
+
+	pkg.Channels = append(pkg.Channels, manifests.PackageChannel{Name: "beta", CurrentCSVName: "etcdoperator.v1.0.0"})
+	bundles = append(bundles, bundle("etcdoperator.v0.9.0", "0.9.0", "", ""))
+	graphs, err = FromPackageManifest(pkg, bundles)
+	require.EqualError(t, err, "unable to build the upgrade graph: bundle etcdoperator.v0.9.0 is not in any channel, "+
+		"current CSV etcdoperator.v1.0.0 of channel beta not found")
+	require.Len(t, graphs, 1)
+
+	// Bundles skipped by the olm.skipRange of the current CSV are in its channel.
+	pkg.Channels = []manifests.PackageChannel{{Name: "stable", CurrentCSVName: "etcdoperator.v1.0.0"}}
+	bundles = append(bundles, bundle("etcdoperator.v1.0.0", "1.0.0", "", "<1.0.0"))
+	graphs, err = FromPackageManifest(pkg, bundles)
+	require.NoError(t, err)
+	require.Len(t, graphs, 1)
+	require.Equal(t, "etcdoperator.v1.0.0", graphs[0].Head)
+	require.NotNil(t, graphs[0].Node("etcdoperator.v0.9.0"))
+	require.NotNil(t, graphs[0].Node("etcdoperator.v0.9.2"))
+}
+
+func TestFromCatalog(t *testing.T) {
+	pkgProperty := func(version string) []manifests.Property {
+		return []manifests.Property{{Type: manifests.PropertyPackage, Value: json.RawMessage(`{"packageName":"etcd","version":"` + version + `"}`)}}
+	}
+	graphs, err := FromCatalog(&manifests.Catalog{
+		Channels: []manifests.CatalogChannel{{
+			Name:    "stable",
+			Package: "etcd",
+			Entries: []manifests.CatalogChannelEntry{
+				{Name: "etcd.v0.9.2"},
+				{Name: "etcd.v0.9.3", Replaces: "etcd.v0.9.2"},
+				{Name: "etcd.v0.9.4", Skips: []string{"etcd.v0.9.3"}, SkipRange: "<0.9.4"},
+			},
+		}},
+		Bundles: []manifests.CatalogBundle{
+			{Name: "etcd.v0.9.2", Package: "etcd", Properties: pkgProperty("0.9.2")},
+			{Name: "etcd.v0.9.3", Package: "etcd", Properties: pkgProperty("latest")},
+			{Name: "etcd.v0.9.4", Package: "etcd", Properties: pkgProperty("0.9.4")},
+		},
+	})
+	require.EqualError(t, err, `unable to build the upgrade graph: bundle etcd.v0.9.3: invalid version: No Major.Minor.Patch elements found`)
+	require.Len(t, graphs, 1)
+	require.Nil(t, graphs[0].Node("etcd.v0.9.3").Version)
+	require.Equal(t, []Edge{
+		{From: "etcd.v0.9.2", To: "etcd.v0.9.3", Kind: EdgeReplaces},
+		{From: "etcd.v0.9.2", To: "etcd.v0.9.4", Kind: EdgeSkipRange},
+		{From: "etcd.v0.9.3", To: "etcd.v0.9.4", Kind: EdgeSkips},
+	}, graphs[0].Edges())
+	require.Equal(t, []string{"etcd.v0.9.4"}, graphs[0].Heads())
+}
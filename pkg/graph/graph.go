@@ -0,0 +1,270 @@
+// Package graph builds the upgrade graphs of the channels of operator packages
+// from their bundles, package manifests or file-based catalogs, and finds the
+// problems of those graphs, such as cycles or bundles which cannot be upgraded
+// to the head of their channel.
+package graph
+
+import (
+	"sort"
+
+	"github.com/blang/semver/v4"
+)
+
+// EdgeKind is the field of a bundle which declares an upgrade edge.
+type EdgeKind string
+
+const (
+	// EdgeReplaces is declared by the replaces field of a bundle.
+	EdgeReplaces EdgeKind = "replaces"
+	// EdgeSkips is declared by the skips field of a bundle.
+	EdgeSkips EdgeKind = "skips"
+	// EdgeSkipRange is declared by the olm.skipRange annotation of a bundle.
+	EdgeSkipRange EdgeKind = "skipRange"
+)
+
+// Node is a bundle of an upgrade graph.
+type Node struct {
+	// Name is the name of the bundle, the name of its CSV.
+	Name string
+	// Version is the version of the bundle, or nil if it is unknown.
+	Version *semver.Version
+	// Replaces is the name of the bundle this bundle replaces, if any.
+	Replaces string
+	// Skips are the names of the bundles this bundle skips.
+	Skips []string
+	// SkipRange is the semver range of the versions of the bundles this bundle skips, if any.
+	SkipRange string
+}
+
+// Edge is an upgrade from the bundle From to the bundle To, declared by To.
+type Edge struct {
+	From string
+	To   string
+	Kind EdgeKind
+}
+
+// Graph is the upgrade graph of a channel of a package.
+type Graph struct {
+	// Package and Channel are the names of the package and channel of the graph.
+	Package string
+	Channel string
+	// Head is the bundle the channel is declared to point to, such as the
+	// currentCSV of package manifest channels, or empty if the channel does not
+	// declare its head.
+	Head string
+
+	nodes map[string]*Node
+	// from and to are the edges of the graph, keyed by their From and To bundle.
+	from map[string][]Edge
+	to   map[string][]Edge
+}
+
+// New returns the upgrade graph of the channel of pkg made of nodes. Edges are
+// only added between the nodes of the graph: replaces and skips of bundles
+// which are not in the graph, and invalid skip ranges, are ignored. If several
+// nodes have the same name, the last one is kept.
+func New(pkg, channel string, nodes []*Node) *Graph {
+	g := &Graph{
+		Package: pkg,
+		Channel: channel,
+		nodes:   map[string]*Node{},
+		from:    map[string][]Edge{},
+		to:      map[string][]Edge{},
+	}
+	for _, n := range nodes {
+		g.nodes[n.Name] = n
+	}
+	for _, n := range g.Nodes() {
+		if n.Replaces != "" {
+			g.addEdge(Edge{From: n.Replaces, To: n.Name, Kind: EdgeReplaces})
+		}
+		for _, skip := range n.Skips {
+			g.addEdge(Edge{From: skip, To: n.Name, Kind: EdgeSkips})
+		}
+		if n.SkipRange == "" {
+			continue
+		}
+		skipRange, err := semver.ParseRange(n.SkipRange)
+		if err != nil {
+			continue
+		}
+		for _, m := range g.Nodes() {
+			if m != n && m.Version != nil && skipRange(*m.Version) {
+				g.addEdge(Edge{From: m.Name, To: n.Name, Kind: EdgeSkipRange})
+			}
+		}
+	}
+	return g
+}
+
+// addEdge adds e to g if both of its bundles are in g.
+func (g *Graph) addEdge(e Edge) {
+	if g.nodes[e.From] == nil || g.nodes[e.To] == nil {
+		return
+	}
+	g.from[e.From] = append(g.from[e.From], e)
+	g.to[e.To] = append(g.to[e.To], e)
+}
+
+// Node returns the node of the bundle name, or nil if it is not in g.
+func (g *Graph) Node(name string) *Node {
+	return g.nodes[name]
+}
+
+// Nodes returns the nodes of g sorted by name.
+func (g *Graph) Nodes() []*Node {
+	nodes := make([]*Node, 0, len(g.nodes))
+	for _, n := range g.nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes
+}
+
+// Edges returns the edges of g sorted by their From, To and Kind.
+func (g *Graph) Edges() []Edge {
+	var edges []Edge
+	for _, n := range g.Nodes() {
+		edges = append(edges, g.from[n.Name]...)
+	}
+	sortEdges(edges)
+	return edges
+}
+
+// UpgradesFrom returns the edges upgrading from the bundle name, sorted by their To and Kind.
+func (g *Graph) UpgradesFrom(name string) []Edge {
+	edges := append([]Edge{}, g.from[name]...)
+	sortEdges(edges)
+	return edges
+}
+
+// UpgradesTo returns the edges upgrading to the bundle name, sorted by their From and Kind.
+func (g *Graph) UpgradesTo(name string) []Edge {
+	edges := append([]Edge{}, g.to[name]...)
+	sortEdges(edges)
+	return edges
+}
+
+// Heads returns the names of the bundles of g which no other bundle upgrades
+// from, sorted. A valid channel has a single head, its latest bundle.
+func (g *Graph) Heads() []string {
+	var heads []string
+	for _, n := range g.Nodes() {
+		head := true
+		for _, e := range g.from[n.Name] {
+			head = head && e.To == n.Name
+		}
+		if head {
+			heads = append(heads, n.Name)
+		}
+	}
+	return heads
+}
+
+// Unreachable returns the names of the bundles of g which cannot be upgraded,
+// directly or not, to the bundle head, sorted.
+func (g *Graph) Unreachable(head string) []string {
+	reached := g.upgradableTo(head)
+	var unreachable []string
+	for _, n := range g.Nodes() {
+		if !reached[n.Name] {
+			unreachable = append(unreachable, n.Name)
+		}
+	}
+	return unreachable
+}
+
+// upgradableTo returns the set of the bundles of g which can be upgraded,
+// directly or not, to the bundle name, including name.
+func (g *Graph) upgradableTo(name string) map[string]bool {
+	reached := map[string]bool{name: true}
+	next := []string{name}
+	for len(next) > 0 {
+		name := next[0]
+		next = next[1:]
+		for _, e := range g.to[name] {
+			if !reached[e.From] {
+				reached[e.From] = true
+				next = append(next, e.From)
+			}
+		}
+	}
+	return reached
+}
+
+// Cycles returns the cycles of g, each as the sorted names of the bundles which
+// can be upgraded to one another, sorted by their first name. A bundle which
+// upgrades from itself is a cycle.
+func (g *Graph) Cycles() [][]string {
+	// Tarjan's algorithm: the strongly connected components of more than one
+	// node, or with an edge from their node to itself, are cycles.
+	index := map[string]int{}
+	lowlink := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	var cycles [][]string
+
+	var connect func(name string)
+	connect = func(name string) {
+		index[name] = len(index)
+		lowlink[name] = index[name]
+		stack = append(stack, name)
+		onStack[name] = true
+		for _, e := range g.from[name] {
+			if _, ok := index[e.To]; !ok {
+				connect(e.To)
+				lowlink[name] = min(lowlink[name], lowlink[e.To])
+			} else if onStack[e.To] {
+				lowlink[name] = min(lowlink[name], index[e.To])
+			}
+		}
+		if lowlink[name] != index[name] {
+			return
+		}
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == name {
+				break
+			}
+		}
+		if len(component) > 1 || g.upgradesFromItself(name) {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+	for _, n := range g.Nodes() {
+		if _, ok := index[n.Name]; !ok {
+			connect(n.Name)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// upgradesFromItself returns whether the bundle name has an edge to itself.
+func (g *Graph) upgradesFromItself(name string) bool {
+	for _, e := range g.from[name] {
+		if e.To == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sortEdges sorts edges by their From, To and Kind.
+func sortEdges(edges []Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Kind < b.Kind
+	})
+}
@@ -0,0 +1,101 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func node(name, version, replaces, skipRange string, skips ...string) *Node {
+	n := &Node{Name: name, Replaces: replaces, SkipRange: skipRange, Skips: skips}
+	if version != "" {
+		v := semver.MustParse(version)
+		n.Version = &v
+	}
+	return n
+}
+
+func TestGraphEdges(t *testing.T) {
+	g := New("etcd", "stable", []*Node{
+		node("etcd.v0.9.0", "0.9.0", "", ""),
+		node("etcd.v0.9.2", "0.9.2", "etcd.v0.9.0", ""),
+		node("etcd.v0.9.3", "0.9.3", "etcd.v0.9.2", ""),
+		node("etcd.v0.9.4", "0.9.4", "etcd.v0.9.2", ">=0.9.0 <0.9.4", "etcd.v0.9.3", "etcd.v0.9.1"),
+		node("etcd.v1.0.0", "", "etcd.v0.9.4", "not a range"),
+	})
+
+	require.Equal(t, []Edge{
+		{From: "etcd.v0.9.0", To: "etcd.v0.9.2", Kind: EdgeReplaces},
+		{From: "etcd.v0.9.0", To: "etcd.v0.9.4", Kind: EdgeSkipRange},
+		{From: "etcd.v0.9.2", To: "etcd.v0.9.3", Kind: EdgeReplaces},
+		{From: "etcd.v0.9.2", To: "etcd.v0.9.4", Kind: EdgeReplaces},
+		{From: "etcd.v0.9.2", To: "etcd.v0.9.4", Kind: EdgeSkipRange},
+		{From: "etcd.v0.9.3", To: "etcd.v0.9.4", Kind: EdgeSkipRange},
+		{From: "etcd.v0.9.3", To: "etcd.v0.9.4", Kind: EdgeSkips},
+		{From: "etcd.v0.9.4", To: "etcd.v1.0.0", Kind: EdgeReplaces},
+	}, g.Edges())
+	require.Equal(t, []Edge{
+		{From: "etcd.v0.9.2", To: "etcd.v0.9.3", Kind: EdgeReplaces},
+		{From: "etcd.v0.9.2", To: "etcd.v0.9.4", Kind: EdgeReplaces},
+		{From: "etcd.v0.9.2", To: "etcd.v0.9.4", Kind: EdgeSkipRange},
+	}, g.UpgradesFrom("etcd.v0.9.2"))
+	require.Equal(t, []Edge{{From: "etcd.v0.9.4", To: "etcd.v1.0.0", Kind: EdgeReplaces}}, g.UpgradesTo("etcd.v1.0.0"))
+	require.Nil(t, g.Node("etcd.v0.9.1"))
+	require.Equal(t, []string{"etcd.v1.0.0"}, g.Heads())
+	require.Empty(t, g.Unreachable("etcd.v1.0.0"))
+	require.Equal(t, []string{"etcd.v0.9.4", "etcd.v1.0.0"}, g.Unreachable("etcd.v0.9.3"))
+	require.Empty(t, g.Cycles())
+}
+
+func TestGraphProblems(t *testing.T) {
+	tests := []struct {
+		name            string
+		nodes           []*Node
+		wantHeads       []string
+		wantCycles      [][]string
+		wantUnreachable []string
+	}{
+		{
+			name: "cycles",
+			nodes: []*Node{
+				node("a", "", "c", ""),
+				node("b", "", "a", ""),
+				node("c", "", "b", ""),
+				node("d", "", "c", ""),
+				node("e", "", "d", "", "e"),
+			},
+			wantHeads:  []string{"e"},
+			wantCycles: [][]string{{"a", "b", "c"}, {"e"}},
+		},
+		{
+			name: "multiple heads",
+			nodes: []*Node{
+				node("a", "1.0.0", "", ""),
+				node("b", "1.1.0", "a", ""),
+				node("c", "1.2.0", "", "<1.1.0"),
+			},
+			wantHeads:       []string{"b", "c"},
+			wantUnreachable: []string{"c"},
+		},
+		{
+			name: "unreachable from the head",
+			nodes: []*Node{
+				node("a", "1.0.0", "", ""),
+				node("b", "1.1.0", "a", ""),
+				node("c", "1.2.0", "b", ""),
+				node("d", "1.0.1", "", ""),
+			},
+			wantHeads:       []string{"c", "d"},
+			wantUnreachable: []string{"d"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New("etcd", "stable", tt.nodes)
+			require.Equal(t, tt.wantHeads, g.Heads())
+			require.Equal(t, tt.wantCycles, g.Cycles())
+			require.Equal(t, tt.wantUnreachable, g.Unreachable(tt.wantHeads[0]))
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+)
+
+// Hop is an upgrade of an upgrade path, from the bundle From to the bundle To.
+type Hop struct {
+	From string
+	To   string
+	// Kinds are the kinds of the edges of the graph which declare the upgrade,
+	// sorted: a bundle may, for example, both replace a bundle and skip it with
+	// its skipRange.
+	Kinds []EdgeKind
+}
+
+// Path is an upgrade path, the hops upgrading from a bundle to another in order.
+type Path []Hop
+
+// Bundles returns the names of the bundles of p in the order they are installed,
+// from the bundle upgraded from to the bundle upgraded to.
+func (p Path) Bundles() []string {
+	if len(p) == 0 {
+		return nil
+	}
+	bundles := []string{p[0].From}
+	for _, hop := range p {
+		bundles = append(bundles, hop.To)
+	}
+	return bundles
+}
+
+// String returns p with the kinds of each hop, ex.
+// "etcd.v0.9.0 -[replaces]-> etcd.v0.9.2 -[skipRange,skips]-> etcd.v0.9.4".
+func (p Path) String() string {
+	if len(p) == 0 {
+		return ""
+	}
+	b := &strings.Builder{}
+	b.WriteString(p[0].From)
+	for _, hop := range p {
+		kinds := make([]string, 0, len(hop.Kinds))
+		for _, kind := range hop.Kinds {
+			kinds = append(kinds, string(kind))
+		}
+		fmt.Fprintf(b, " -[%s]-> %s", strings.Join(kinds, ","), hop.To)
+	}
+	return b.String()
+}
+
+// NodeByVersion returns the node of the bundle of g with version, or nil if
+// no bundle of g has that version. If several bundles have the version, the
+// first by name is returned.
+func (g *Graph) NodeByVersion(version semver.Version) *Node {
+	for _, n := range g.Nodes() {
+		if n.Version != nil && n.Version.Equals(version) {
+			return n
+		}
+	}
+	return nil
+}
+
+// ShortestPath returns the upgrade path from the bundle from to the bundle to
+// with the fewest hops, and whether there is one. Among the paths with the
+// fewest hops, the first in the order of the names of their bundles is
+// returned. The path from a bundle to itself is empty.
+func (g *Graph) ShortestPath(from, to string) (Path, bool) {
+	if g.nodes[from] == nil || g.nodes[to] == nil {
+		return nil, false
+	}
+	// Breadth-first search, visiting the bundles upgraded to in order of name.
+	previous := map[string]string{from: ""}
+	next := []string{from}
+	for len(next) > 0 {
+		if _, ok := previous[to]; ok {
+			break
+		}
+		name := next[0]
+		next = next[1:]
+		for _, upgrade := range g.upgrades(name) {
+			if _, ok := previous[upgrade]; !ok {
+				previous[upgrade] = name
+				next = append(next, upgrade)
+			}
+		}
+	}
+	if _, ok := previous[to]; !ok {
+		return nil, false
+	}
+	path := Path{}
+	for name := to; name != from; name = previous[name] {
+		path = append(Path{g.hop(previous[name], name)}, path...)
+	}
+	return path, true
+}
+
+// Paths returns the upgrade paths from the bundle from to the bundle to which
+// install each bundle at most once, in order of their number of hops, then of
+// the names of their bundles. At most limit paths are returned, or every path
+// if limit is zero or negative: channels whose bundles skip many others with
+// their skipRange have a number of paths growing exponentially with their
+// number of bundles.
+func (g *Graph) Paths(from, to string, limit int) []Path {
+	if g.nodes[from] == nil || g.nodes[to] == nil {
+		return nil
+	}
+	if from == to {
+		return []Path{{}}
+	}
+	// Only the bundles which can be upgraded to the bundle to are explored.
+	upgradable := g.upgradableTo(to)
+	var paths []Path
+	// Breadth-first search of the paths, so that they are found in order.
+	next := [][]string{{from}}
+	for len(next) > 0 {
+		bundles := next[0]
+		next = next[1:]
+		for _, upgrade := range g.upgrades(bundles[len(bundles)-1]) {
+			if !upgradable[upgrade] || contains(bundles, upgrade) {
+				continue
+			}
+			extended := append(append([]string{}, bundles...), upgrade)
+			if upgrade != to {
+				next = append(next, extended)
+				continue
+			}
+			path := make(Path, 0, len(extended)-1)
+			for i := 1; i < len(extended); i++ {
+				path = append(path, g.hop(extended[i-1], extended[i]))
+			}
+			paths = append(paths, path)
+			if len(paths) == limit {
+				return paths
+			}
+		}
+	}
+	return paths
+}
+
+// upgrades returns the names of the bundles the bundle name upgrades to, sorted.
+func (g *Graph) upgrades(name string) []string {
+	var upgrades []string
+	for _, e := range g.from[name] {
+		if !contains(upgrades, e.To) {
+			upgrades = append(upgrades, e.To)
+		}
+	}
+	sort.Strings(upgrades)
+	return upgrades
+}
+
+// hop returns the hop from the bundle from to the bundle to.
+func (g *Graph) hop(from, to string) Hop {
+	hop := Hop{From: from, To: to}
+	for _, e := range g.UpgradesFrom(from) {
+		if e.To == to {
+			hop.Kinds = append(hop.Kinds, e.Kind)
+		}
+	}
+	return hop
+}
+
+// contains returns whether names contains name.
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func etcdGraph() *Graph {
+	return New("etcd", "stable", []*Node{
+		node("etcd.v1.2.0", "1.2.0", "", ""),
+		node("etcd.v1.3.0", "1.3.0", "etcd.v1.2.0", ""),
+		node("etcd.v1.4.0", "1.4.0", "etcd.v1.3.0", "", "etcd.v1.2.0"),
+		node("etcd.v1.5.0", "1.5.0", "etcd.v1.4.0", ">=1.3.0 <1.5.0"),
+		node("etcd.v1.9.0", "1.9.0", "etcd.v1.5.0", ""),
+		node("etcd.v2.0.0", "2.0.0", "", ""),
+	})
+}
+
+func TestShortestPath(t *testing.T) {
+	g := etcdGraph()
+
+	path, ok := g.ShortestPath("etcd.v1.2.0", "etcd.v1.9.0")
+	require.True(t, ok)
+	require.Equal(t, Path{
+		{From: "etcd.v1.2.0", To: "etcd.v1.3.0", Kinds: []EdgeKind{EdgeReplaces}},
+		{From: "etcd.v1.3.0", To: "etcd.v1.5.0", Kinds: []EdgeKind{EdgeSkipRange}},
+		{From: "etcd.v1.5.0", To: "etcd.v1.9.0", Kinds: []EdgeKind{EdgeReplaces}},
+	}, path)
+	require.Equal(t, []string{"etcd.v1.2.0", "etcd.v1.3.0", "etcd.v1.5.0", "etcd.v1.9.0"}, path.Bundles())
+	require.Equal(t, "etcd.v1.2.0 -[replaces]-> etcd.v1.3.0 -[skipRange]-> etcd.v1.5.0 -[replaces]-> etcd.v1.9.0", path.String())
+
+	path, ok = g.ShortestPath("etcd.v1.2.0", "etcd.v1.5.0")
+	require.True(t, ok)
+	require.Equal(t, "etcd.v1.2.0 -[replaces]-> etcd.v1.3.0 -[skipRange]-> etcd.v1.5.0", path.String())
+	path, ok = g.ShortestPath("etcd.v1.4.0", "etcd.v1.5.0")
+	require.True(t, ok)
+	require.Equal(t, "etcd.v1.4.0 -[replaces,skipRange]-> etcd.v1.5.0", path.String())
+
+	path, ok = g.ShortestPath("etcd.v1.5.0", "etcd.v1.5.0")
+	require.True(t, ok)
+	require.Empty(t, path)
+	require.Empty(t, path.String())
+
+	// Bundles cannot be downgraded, nor upgraded to a bundle which replaces nothing.
+	_, ok = g.ShortestPath("etcd.v1.9.0", "etcd.v1.2.0")
+	require.False(t, ok)
+	_, ok = g.ShortestPath("etcd.v1.9.0", "etcd.v2.0.0")
+	require.False(t, ok)
+	_, ok = g.ShortestPath("etcd.v1.2.0", "etcd.v3.0.0")
+	require.False(t, ok)
+}
+
+func TestPaths(t *testing.T) {
+	g := etcdGraph()
+
+	var paths []string
+	for _, path := range g.Paths("etcd.v1.2.0", "etcd.v1.9.0", 0) {
+		paths = append(paths, path.String())
+	}
+	require.Equal(t, []string{
+		"etcd.v1.2.0 -[replaces]-> etcd.v1.3.0 -[skipRange]-> etcd.v1.5.0 -[replaces]-> etcd.v1.9.0",
+		"etcd.v1.2.0 -[skips]-> etcd.v1.4.0 -[replaces,skipRange]-> etcd.v1.5.0 -[replaces]-> etcd.v1.9.0",
+		"etcd.v1.2.0 -[replaces]-> etcd.v1.3.0 -[replaces]-> etcd.v1.4.0 -[replaces,skipRange]-> etcd.v1.5.0 -[replaces]-> etcd.v1.9.0",
+	}, paths)
+
+	require.Len(t, g.Paths("etcd.v1.2.0", "etcd.v1.9.0", 2), 2)
+	require.Equal(t, []Path{{}}, g.Paths("etcd.v1.2.0", "etcd.v1.2.0", 0))
+	require.Empty(t, g.Paths("etcd.v1.9.0", "etcd.v1.2.0", 0))
+	require.Empty(t, g.Paths("etcd.v1.2.0", "etcd.v3.0.0", 0))
+}
+
+func TestPathsCycle(t *testing.T) {
+	g := New("etcd", "stable", []*Node{
+		node("a", "", "c", ""),
+		node("b", "", "a", ""),
+		node("c", "", "b", ""),
+		node("d", "", "c", ""),
+	})
+	paths := g.Paths("a", "d", 0)
+	require.Len(t, paths, 1)
+	require.Equal(t, []string{"a", "b", "c", "d"}, paths[0].Bundles())
+}
+
+func TestNodeByVersion(t *testing.T) {
+	g := etcdGraph()
+	require.Equal(t, "etcd.v1.4.0", g.NodeByVersion(semver.MustParse("1.4.0")).Name)
+	require.Nil(t, g.NodeByVersion(semver.MustParse("1.6.0")))
+}
@@ -19,10 +19,23 @@ type Bundle struct {
 	V1beta1CRDs    []*apiextensionsv1beta1.CustomResourceDefinition
 	V1CRDs         []*apiextensionsv1.CustomResourceDefinition
 	Dependencies   []*Dependency
+	// Properties are the properties declared by the metadata/properties.yaml file of the bundle
+	Properties []Property
 	// CompressedSize stores the gzip size of the bundle
 	CompressedSize int64
 	// Size stores the size of the bundle
 	Size int64
+	// Sources maps the objects of the bundle to the manifest files they were loaded from
+	Sources *SourceMap
+	// Warnings are the problems found while loading the bundle which did not prevent it from loading
+	Warnings []LoadWarning
+}
+
+// LoadWarning is a problem found while loading a bundle which did not prevent it from loading.
+type LoadWarning struct {
+	// Position is the position of the problem in the manifest files of the bundle.
+	Position Position
+	Detail   string
 }
 
 func (b *Bundle) ObjectsToValidate() []interface{} {
@@ -1,8 +1,14 @@
 package manifests
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -16,29 +22,43 @@ import (
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 )
 
-// bundleLoader loads a bundle directory from disk
+// bundleLoader loads a bundle directory from a file system
 type bundleLoader struct {
+	fsys            fs.FS
 	dir             string
 	bundle          *Bundle
 	foundCSV        bool
 	annotationsFile AnnotationsFile
+	propertiesFile  *PropertiesFile
+	dependencyFile  *dependencyFile
 }
 
 func NewBundleLoader(dir string) bundleLoader {
 	return bundleLoader{
-		dir: dir,
+		fsys: DirFS(dir),
+		dir:  dir,
+	}
+}
+
+// NewBundleLoaderFS returns a loader of the bundle in fsys. The paths passed to
+// its walk funcs are slash-separated paths in fsys.
+func NewBundleLoaderFS(fsys fs.FS) bundleLoader {
+	return bundleLoader{
+		fsys: fsys,
+		dir:  displayPath(fsys, "."),
 	}
 }
 
 func (b *bundleLoader) LoadBundle() error {
 	errs := make([]error, 0)
-	if err := filepath.Walk(b.dir, collectWalkErrs(b.LoadBundleWalkFunc, &errs)); err != nil {
+	if err := walkFS(b.fsys, collectWalkErrs(b.LoadBundleWalkFunc, &errs)); err != nil {
 		errs = append(errs, err)
 	}
 
 	errs = append(errs, b.calculateCompressedBundleSize())
 	b.addChannelsFromAnnotationsFile()
 	b.addPackageFromAnnotationsFile()
+	b.addMetadataFiles()
 
 	if !b.foundCSV {
 		errs = append(errs, fmt.Errorf("unable to find a csv in bundle directory %s", b.dir))
@@ -76,23 +96,39 @@ func (b *bundleLoader) addPackageFromAnnotationsFile() {
 	b.bundle.Package = b.annotationsFile.Annotations.PackageName
 }
 
+// Add the properties and dependencies of the metadata directory
+func (b *bundleLoader) addMetadataFiles() {
+	if b.bundle == nil {
+		// None of this is relevant if the bundle was not found
+		return
+	}
+	if b.propertiesFile != nil {
+		for _, p := range b.propertiesFile.Properties {
+			b.bundle.Properties = append(b.bundle.Properties, Property{Type: p.Type, Value: unescapeHTML(p.Value)})
+		}
+	}
+	if b.dependencyFile != nil {
+		b.bundle.Dependencies = b.dependencyFile.dependencies()
+	}
+}
+
 // Compress the bundle to check its size
 func (b *bundleLoader) calculateCompressedBundleSize() error {
 	if b.bundle == nil {
 		return nil
 	}
-	err := filepath.Walk(b.dir,
-		func(path string, info os.FileInfo, err error) error {
+	err := fs.WalkDir(b.fsys, ".",
+		func(path string, d fs.DirEntry, err error) error {
 			if err != nil {
 				return err
 			}
-			if info.IsDir() {
+			if d.IsDir() {
 				return nil
 			}
-			data, err := os.ReadFile(path)
+			data, err := fs.ReadFile(b.fsys, path)
 			if err == nil {
 				// Sum the bundle amount
-				b.bundle.Size += info.Size()
+				b.bundle.Size += int64(len(data))
 
 				// Sum the compressed amount
 				contentGzip, err := encoding.GzipBase64Encode(data)
@@ -109,6 +145,21 @@ func (b *bundleLoader) calculateCompressedBundleSize() error {
 	return nil
 }
 
+// walkFS walks fsys like filepath.Walk, calling walk with the slash-separated
+// path in fsys and the file info of each file and directory.
+func walkFS(fsys fs.FS, walk filepath.WalkFunc) error {
+	return fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return walk(path, nil, err)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return walk(path, nil, err)
+		}
+		return walk(path, info, nil)
+	})
+}
+
 // collectWalkErrs calls the given walk func and appends any non-nil, non skip dir error returned to the given errors slice.
 func collectWalkErrs(walk filepath.WalkFunc, errs *[]error) filepath.WalkFunc {
 	return func(path string, f os.FileInfo, err error) (walkErr error) {
@@ -130,7 +181,7 @@ func (b *bundleLoader) LoadBundleWalkFunc(path string, f os.FileInfo, err error)
 	}
 
 	if f.IsDir() {
-		if strings.HasPrefix(f.Name(), ".") {
+		if strings.HasPrefix(f.Name(), ".") && path != "." {
 			return filepath.SkipDir
 		}
 		return nil
@@ -142,38 +193,48 @@ func (b *bundleLoader) LoadBundleWalkFunc(path string, f os.FileInfo, err error)
 
 	annotationsFile := AnnotationsFile{}
 	if strings.HasPrefix(f.Name(), "annotations") {
-		annFile, err := os.ReadFile(path)
+		annFile, err := fs.ReadFile(b.fsys, path)
 		if err != nil {
 			return err
 		}
 		if err := yaml.Unmarshal(annFile, &annotationsFile); err == nil {
 			b.annotationsFile = annotationsFile
 		} else {
-			return fmt.Errorf("unable to load the annotations file %s: %s", path, err)
+			return fmt.Errorf("unable to load the annotations file %s: %s", displayPath(b.fsys, path), err)
 		}
 	}
 
-	fileReader, err := os.Open(path)
-	if err != nil {
-		return fmt.Errorf("unable to load file %s: %s", path, err)
+	if isMetadataFile(path, "properties") {
+		propertiesFile := &PropertiesFile{}
+		if err := b.loadMetadataFile(path, propertiesFile); err != nil {
+			return err
+		}
+		b.propertiesFile = propertiesFile
+		return nil
 	}
-	defer fileReader.Close()
-
-	decoder := yaml.NewYAMLOrJSONDecoder(fileReader, 30)
-	csv := unstructured.Unstructured{}
-
-	if err = decoder.Decode(&csv); err != nil {
+	if isMetadataFile(path, "dependencies") {
+		dependencyFile := &dependencyFile{}
+		if err := b.loadMetadataFile(path, dependencyFile); err != nil {
+			return err
+		}
+		b.dependencyFile = dependencyFile
 		return nil
 	}
 
-	if csv.GetKind() != operatorsv1alpha1.ClusterServiceVersionKind {
+	data, err := fs.ReadFile(b.fsys, path)
+	if err != nil {
+		return fmt.Errorf("unable to load file %s: %s", displayPath(b.fsys, path), err)
+	}
+
+	csv := findCSV(data)
+	if csv == nil {
 		return nil
 	}
 
 	b.foundCSV = true
 
 	var errs []error
-	bundle, err := loadBundle(csv.GetName(), filepath.Dir(path))
+	bundle, err := loadBundleFS(b.fsys, csv.GetName(), dirOf(path))
 	if err != nil {
 		errs = append(errs, fmt.Errorf("error loading objs in directory: %s", err))
 	}
@@ -188,20 +249,52 @@ func (b *bundleLoader) LoadBundleWalkFunc(path string, f os.FileInfo, err error)
 	return utilerrors.NewAggregate(errs)
 }
 
+// isMetadataFile returns whether the slash-separated path is the YAML file
+// with the given name, ex. "properties", of the metadata directory of a bundle.
+func isMetadataFile(name, kind string) bool {
+	base := path.Base(name)
+	return path.Base(path.Dir(name)) == "metadata" && (base == kind+".yaml" || base == kind+".yml")
+}
+
+// loadMetadataFile decodes the metadata file at path into v.
+func (b *bundleLoader) loadMetadataFile(path string, v interface{}) error {
+	data, err := fs.ReadFile(b.fsys, path)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("unable to load the metadata file %s: %s", displayPath(b.fsys, path), err)
+	}
+	return nil
+}
+
 // loadBundle takes the directory that a CSV is in and assumes the rest of the objects in that directory
 // are part of the bundle.
 func loadBundle(csvName string, dir string) (*Bundle, error) {
-	files, err := os.ReadDir(dir)
+	return loadBundleFS(DirFS(dir), csvName, ".")
+}
+
+// dirOf returns the directory of the slash-separated path name.
+func dirOf(name string) string {
+	return path.Dir(name)
+}
+
+// loadBundleFS takes the directory of fsys that a CSV is in and assumes the rest of the objects in
+// that directory are part of the bundle.
+func loadBundleFS(fsys fs.FS, csvName string, dir string) (*Bundle, error) {
+	files, err := fs.ReadDir(fsys, dir)
 	if err != nil {
 		return nil, err
 	}
 
 	var errs []error
 	bundle := &Bundle{
-		Name: csvName,
+		Name:    csvName,
+		Sources: &SourceMap{},
 	}
 	for _, f := range files {
-		path := filepath.Join(dir, f.Name())
+		name := path.Join(dir, f.Name())
+		path := displayPath(fsys, name)
 
 		if f.IsDir() {
 			errs = append(errs, fmt.Errorf("bundle manifests dir contains directory: %s", path))
@@ -213,57 +306,73 @@ func loadBundle(csvName string, dir string) (*Bundle, error) {
 			continue
 		}
 
-		fileReader, err := os.Open(path)
+		data, err := fs.ReadFile(fsys, name)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("unable to load file %s: %s", path, err))
 			continue
 		}
-		defer fileReader.Close()
-
-		decoder := yaml.NewYAMLToJSONDecoder(fileReader)
-		obj := &unstructured.Unstructured{}
-		if err = decoder.Decode(obj); err != nil {
-			errs = append(errs, fmt.Errorf("unable to decode object: %s", err))
-			continue
-		}
 
-		bundle.Objects = append(bundle.Objects, obj)
+		bundle.Sources.addFile(path, data)
 
-		// Reset the reader so we can decode it into a typed object.
-		if err = resetFile(fileReader); err != nil {
-			errs = append(errs, err)
-			continue
-		}
-
-		switch kind := obj.GetKind(); kind {
-		case "ClusterServiceVersion":
-			if bundle.CSV != nil {
-				return nil, fmt.Errorf("invalid bundle: contains multiple CSVs")
+		// seen counts the objects of the file by kind and name, to find the
+		// position of each of them in the file.
+		seen := map[[2]string]int{}
+		for i, doc := range readDocuments(data) {
+			if doc.err != nil {
+				errs = append(errs, fmt.Errorf("unable to decode object: %s", doc.err))
+				continue
 			}
-			csv := operatorsv1alpha1.ClusterServiceVersion{}
-			err := decoder.Decode(&csv)
-			if err != nil {
-				return nil, fmt.Errorf("unable to parse CSV %s: %s", f.Name(), err.Error())
+			obj := doc.obj
+			bundle.Objects = append(bundle.Objects, obj)
+
+			key := [2]string{obj.GetKind(), obj.GetName()}
+			n := seen[key]
+			seen[key]++
+
+			// Loaders which decode only the first document of each manifest
+			// file, such as previous versions of this one, drop the others.
+			if i > 0 {
+				pos, ok := bundle.Sources.objectPosition(path, obj.GetKind(), obj.GetName(), n)
+				if !ok {
+					pos = Position{File: path}
+				}
+				bundle.Warnings = append(bundle.Warnings, LoadWarning{
+					Position: pos,
+					Detail: fmt.Sprintf("%s %s is not the first document of %s and is ignored by tools which only load the first document of each manifest file",
+						obj.GetKind(), obj.GetName(), path),
+				})
 			}
-			bundle.CSV = &csv
-		case "CustomResourceDefinition":
-			version := obj.GetAPIVersion()
-			if version == apiextensionsv1beta1.SchemeGroupVersion.String() {
-				crd := apiextensionsv1beta1.CustomResourceDefinition{}
-				err := decoder.Decode(&crd)
-				if err != nil {
-					return nil, fmt.Errorf("unable to parse CRD %s: %s", f.Name(), err.Error())
+
+			switch kind := obj.GetKind(); kind {
+			case "ClusterServiceVersion":
+				if bundle.CSV != nil {
+					return nil, fmt.Errorf("invalid bundle: contains multiple CSVs")
 				}
-				bundle.V1beta1CRDs = append(bundle.V1beta1CRDs, &crd)
-			} else if version == apiextensionsv1.SchemeGroupVersion.String() {
-				crd := apiextensionsv1.CustomResourceDefinition{}
-				err := decoder.Decode(&crd)
+				csv := operatorsv1alpha1.ClusterServiceVersion{}
+				err := json.Unmarshal(doc.data, &csv)
 				if err != nil {
-					return nil, fmt.Errorf("unable to parse CRD %s: %s", f.Name(), err.Error())
+					return nil, fmt.Errorf("unable to parse CSV %s: %s", f.Name(), err.Error())
+				}
+				bundle.CSV = &csv
+			case "CustomResourceDefinition":
+				version := obj.GetAPIVersion()
+				if version == apiextensionsv1beta1.SchemeGroupVersion.String() {
+					crd := apiextensionsv1beta1.CustomResourceDefinition{}
+					err := json.Unmarshal(doc.data, &crd)
+					if err != nil {
+						return nil, fmt.Errorf("unable to parse CRD %s: %s", f.Name(), err.Error())
+					}
+					bundle.V1beta1CRDs = append(bundle.V1beta1CRDs, &crd)
+				} else if version == apiextensionsv1.SchemeGroupVersion.String() {
+					crd := apiextensionsv1.CustomResourceDefinition{}
+					err := json.Unmarshal(doc.data, &crd)
+					if err != nil {
+						return nil, fmt.Errorf("unable to parse CRD %s: %s", f.Name(), err.Error())
+					}
+					bundle.V1CRDs = append(bundle.V1CRDs, &crd)
+				} else {
+					return nil, fmt.Errorf("unsupported CRD version %s for %s", version, f.Name())
 				}
-				bundle.V1CRDs = append(bundle.V1CRDs, &crd)
-			} else {
-				return nil, fmt.Errorf("unsupported CRD version %s for %s", version, f.Name())
 			}
 		}
 	}
@@ -271,14 +380,47 @@ func loadBundle(csvName string, dir string) (*Bundle, error) {
 	return bundle, utilerrors.NewAggregate(errs)
 }
 
-// resetFile seeks f to read from 0, assuming it is read-only.
-func resetFile(f *os.File) error {
-	r, err := f.Seek(0, 0)
-	if err != nil {
-		return fmt.Errorf("unable to reset file %s: %v", f.Name(), err)
-	}
-	if r != 0 {
-		return fmt.Errorf("unable to reset file %s: seek is %d not 0", f.Name(), r)
+// findCSV returns the first ClusterServiceVersion among the documents of the
+// manifest file data, or nil if it has none.
+func findCSV(data []byte) *unstructured.Unstructured {
+	for _, doc := range readDocuments(data) {
+		if doc.err == nil && doc.obj.GetKind() == operatorsv1alpha1.ClusterServiceVersionKind {
+			return doc.obj
+		}
 	}
 	return nil
 }
+
+// manifestDocument is a YAML or JSON document of a manifest file.
+type manifestDocument struct {
+	// data is the document converted to JSON.
+	data []byte
+	obj  *unstructured.Unstructured
+	err  error
+}
+
+// readDocuments splits the manifest file data into its documents and decodes
+// them. Empty documents, such as the one before a leading "---", are skipped.
+// Documents which cannot be decoded are returned with their error, and the
+// remaining documents are still read.
+func readDocuments(data []byte) []manifestDocument {
+	var docs []manifestDocument
+	reader := yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		chunk, err := reader.Read()
+		if err == io.EOF {
+			return docs
+		}
+		if err != nil {
+			return append(docs, manifestDocument{err: err})
+		}
+		doc := manifestDocument{obj: &unstructured.Unstructured{}}
+		if doc.data, doc.err = yaml.ToJSON(chunk); doc.err == nil {
+			if trimmed := bytes.TrimSpace(doc.data); len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+				continue
+			}
+			doc.err = doc.obj.UnmarshalJSON(doc.data)
+		}
+		docs = append(docs, doc)
+	}
+}
@@ -32,6 +32,7 @@ type Dependency struct {
 	// dependency or `olm.gvk` for gvk based dependency. This field is required.
 	Type string `json:"type" yaml:"type"`
 
-	// The value of the dependency (either GVKDependency or PackageDependency)
+	// The value of the dependency (either GVKDependency or PackageDependency). The values
+	// loaded from a metadata/dependencies.yaml file are JSON encoded, see Dependency.Parse.
 	Value string `json:"value" yaml:"value"`
 }
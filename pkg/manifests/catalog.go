@@ -0,0 +1,167 @@
+package manifests
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Schemas of the blobs of file-based catalogs.
+const (
+	SchemaPackage      = "olm.package"
+	SchemaChannel      = "olm.channel"
+	SchemaBundle       = "olm.bundle"
+	SchemaDeprecations = "olm.deprecations"
+)
+
+// Catalog is a file-based catalog (FBC), the declarative config of the
+// packages, channels and bundles of an index image.
+type Catalog struct {
+	Packages     []CatalogPackage
+	Channels     []CatalogChannel
+	Bundles      []CatalogBundle
+	Deprecations []CatalogDeprecation
+	// Others are the blobs of the schemas unknown to this library.
+	Others []CatalogMeta
+}
+
+// CatalogPackage is an olm.package blob, which declares a package of the catalog.
+type CatalogPackage struct {
+	Schema         string       `json:"schema"`
+	Name           string       `json:"name"`
+	DefaultChannel string       `json:"defaultChannel"`
+	Icon           *CatalogIcon `json:"icon,omitempty"`
+	Description    string       `json:"description,omitempty"`
+	Properties     []Property   `json:"properties,omitempty"`
+}
+
+// CatalogIcon is the icon of a package.
+type CatalogIcon struct {
+	Data      []byte `json:"base64data"`
+	MediaType string `json:"mediatype"`
+}
+
+// CatalogChannel is an olm.channel blob, which declares a channel of a package
+// and the upgrade edges between its bundles.
+type CatalogChannel struct {
+	Schema     string                `json:"schema"`
+	Name       string                `json:"name"`
+	Package    string                `json:"package"`
+	Entries    []CatalogChannelEntry `json:"entries"`
+	Properties []Property            `json:"properties,omitempty"`
+}
+
+// CatalogChannelEntry is a bundle of a channel, along with the bundles it upgrades from.
+type CatalogChannelEntry struct {
+	// Name is the name of the bundle.
+	Name string `json:"name"`
+	// Replaces is the name of the bundle this bundle replaces, if any.
+	Replaces string `json:"replaces,omitempty"`
+	// Skips are the names of the bundles this bundle skips.
+	Skips []string `json:"skips,omitempty"`
+	// SkipRange is the semver range of the versions of the bundles this bundle skips.
+	SkipRange string `json:"skipRange,omitempty"`
+}
+
+// CatalogBundle is an olm.bundle blob, which declares a bundle of a package and
+// the image it is pulled from.
+type CatalogBundle struct {
+	Schema        string                `json:"schema"`
+	Name          string                `json:"name"`
+	Package       string                `json:"package"`
+	Image         string                `json:"image"`
+	Properties    []Property            `json:"properties,omitempty"`
+	RelatedImages []CatalogRelatedImage `json:"relatedImages,omitempty"`
+}
+
+// CatalogRelatedImage is an image related to a bundle.
+type CatalogRelatedImage struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+// CatalogDeprecation is an olm.deprecations blob, which deprecates a package or
+// some of its channels and bundles.
+type CatalogDeprecation struct {
+	Schema  string                    `json:"schema"`
+	Package string                    `json:"package"`
+	Entries []CatalogDeprecationEntry `json:"entries"`
+}
+
+// CatalogDeprecationEntry deprecates the package, channel or bundle it references.
+type CatalogDeprecationEntry struct {
+	Reference CatalogReference `json:"reference"`
+	Message   string           `json:"message"`
+}
+
+// CatalogReference references a package, or a channel or bundle of a package, by
+// its schema and name. The name of package references is empty.
+type CatalogReference struct {
+	Schema string `json:"schema"`
+	Name   string `json:"name,omitempty"`
+}
+
+// CatalogMeta is a blob of a file-based catalog, of any schema.
+type CatalogMeta struct {
+	Schema  string
+	Package string
+	Name    string
+	// Blob is the JSON of the whole blob.
+	Blob json.RawMessage
+}
+
+// MarshalJSON returns the blob of m.
+func (m CatalogMeta) MarshalJSON() ([]byte, error) {
+	return m.Blob, nil
+}
+
+// UnmarshalJSON sets the blob of m to data, along with its schema, package and name.
+func (m *CatalogMeta) UnmarshalJSON(data []byte) error {
+	fields := struct {
+		Schema  string `json:"schema"`
+		Package string `json:"package"`
+		Name    string `json:"name"`
+	}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	m.Schema, m.Package, m.Name = fields.Schema, fields.Package, fields.Name
+	m.Blob = append(json.RawMessage{}, data...)
+	return nil
+}
+
+// add adds the blob meta to c, decoded into the type of its schema.
+func (c *Catalog) add(meta CatalogMeta) error {
+	var v interface{}
+	switch meta.Schema {
+	case SchemaPackage:
+		c.Packages = append(c.Packages, CatalogPackage{})
+		v = &c.Packages[len(c.Packages)-1]
+	case SchemaChannel:
+		c.Channels = append(c.Channels, CatalogChannel{})
+		v = &c.Channels[len(c.Channels)-1]
+	case SchemaBundle:
+		c.Bundles = append(c.Bundles, CatalogBundle{})
+		v = &c.Bundles[len(c.Bundles)-1]
+	case SchemaDeprecations:
+		c.Deprecations = append(c.Deprecations, CatalogDeprecation{})
+		v = &c.Deprecations[len(c.Deprecations)-1]
+	default:
+		c.Others = append(c.Others, meta)
+		return nil
+	}
+	if err := json.Unmarshal(meta.Blob, v); err != nil {
+		return fmt.Errorf("invalid %s blob %q: %v", meta.Schema, blobName(meta), err)
+	}
+	return nil
+}
+
+// blobName returns the name a blob is reported with.
+func blobName(meta CatalogMeta) string {
+	if meta.Schema == SchemaPackage || meta.Package == "" {
+		return meta.Name
+	}
+	if meta.Name == "" {
+		return meta.Package
+	}
+	return meta.Package + "/" + meta.Name
+}
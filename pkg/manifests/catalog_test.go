@@ -0,0 +1,233 @@
+package manifests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const etcdCatalogYAML = `---
+schema: olm.package
+name: etcd
+defaultChannel: stable
+description: A message of the etcd package
+icon:
+  base64data: PHN2Zz48L3N2Zz4=
+  mediatype: image/svg+xml
+---
+schema: olm.channel
+name: stable
+package: etcd
+entries:
+- name: etcdoperator.v0.9.2
+- name: etcdoperator.v0.9.4
+  replaces: etcdoperator.v0.9.2
+  skipRange: '>=0.9.0 <0.9.4'
+---
+schema: olm.deprecations
+package: etcd
+entries:
+- reference:
+    schema: olm.bundle
+    name: etcdoperator.v0.9.2
+  message: etcdoperator.v0.9.2 is deprecated
+`
+
+const etcdBundlesJSON = `{
+    "schema": "olm.bundle",
+    "name": "etcdoperator.v0.9.2",
+    "package": "etcd",
+    "image": "quay.io/etcd/bundle:v0.9.2",
+    "properties": [
+        {"type": "olm.package", "value": {"packageName": "etcd", "version": "0.9.2"}}
+    ]
+}
+{
+    "schema": "olm.bundle",
+    "name": "etcdoperator.v0.9.4",
+    "package": "etcd",
+    "image": "quay.io/etcd/bundle:v0.9.4",
+    "properties": [
+        {"type": "olm.package", "value": {"packageName": "etcd", "version": "0.9.4"}}
+    ],
+    "relatedImages": [
+        {"name": "operator", "image": "quay.io/etcd/operator:v0.9.4"}
+    ]
+}
+{"schema": "example.com/notes", "package": "etcd", "name": "release", "text": "Released"}
+`
+
+func etcdCatalogFS() map[string][]byte {
+	return map[string][]byte{
+		"etcd/catalog.yaml":  []byte(etcdCatalogYAML),
+		"etcd/bundles.json":  []byte(etcdBundlesJSON),
+		"etcd/README.md":     []byte("# etcd\n"),
+		".git/config.json":   []byte("not a catalog"),
+		"etcd/.hidden.yaml":  []byte("not a catalog"),
+		"etcd/empty.yml":     []byte("---\n"),
+		"etcd/more/nil.json": []byte(""),
+	}
+}
+
+func TestGetCatalogFromFS(t *testing.T) {
+	catalog, err := GetCatalogFromFS(mustMapFS(t, etcdCatalogFS()))
+	require.NoError(t, err)
+
+	require.Equal(t, []CatalogPackage{{
+		Schema:         SchemaPackage,
+		Name:           "etcd",
+		DefaultChannel: "stable",
+		Description:    "A message of the etcd package",
+		Icon:           &CatalogIcon{Data: []byte("<svg></svg>"), MediaType: "image/svg+xml"},
+	}}, catalog.Packages)
+	require.Equal(t, []CatalogChannel{{
+		Schema:  SchemaChannel,
+		Name:    "stable",
+		Package: "etcd",
+		Entries: []CatalogChannelEntry{
+			{Name: "etcdoperator.v0.9.2"},
+			{Name: "etcdoperator.v0.9.4", Replaces: "etcdoperator.v0.9.2", SkipRange: ">=0.9.0 <0.9.4"},
+		},
+	}}, catalog.Channels)
+	require.Len(t, catalog.Bundles, 2)
+	require.Equal(t, "quay.io/etcd/bundle:v0.9.4", catalog.Bundles[1].Image)
+	require.Equal(t, []CatalogRelatedImage{{Name: "operator", Image: "quay.io/etcd/operator:v0.9.4"}}, catalog.Bundles[1].RelatedImages)
+	require.Equal(t, PropertyPackage, catalog.Bundles[1].Properties[0].Type)
+	require.JSONEq(t, `{"packageName":"etcd","version":"0.9.4"}`, string(catalog.Bundles[1].Properties[0].Value))
+	require.Equal(t, []CatalogDeprecation{{
+		Schema:  SchemaDeprecations,
+		Package: "etcd",
+		Entries: []CatalogDeprecationEntry{{
+			Reference: CatalogReference{Schema: SchemaBundle, Name: "etcdoperator.v0.9.2"},
+			Message:   "etcdoperator.v0.9.2 is deprecated",
+		}},
+	}}, catalog.Deprecations)
+	require.Len(t, catalog.Others, 1)
+	require.Equal(t, "example.com/notes", catalog.Others[0].Schema)
+	require.Equal(t, "etcd", catalog.Others[0].Package)
+	require.Equal(t, "release", catalog.Others[0].Name)
+	require.JSONEq(t, `{"schema":"example.com/notes","package":"etcd","name":"release","text":"Released"}`, string(catalog.Others[0].Blob))
+}
+
+func TestWalkCatalogFS(t *testing.T) {
+	var walked []string
+	stop := errors.New("stop")
+	err := WalkCatalogFS(mustMapFS(t, etcdCatalogFS()), func(path string, meta CatalogMeta) error {
+		walked = append(walked, path+" "+meta.Schema+" "+blobName(meta))
+		if meta.Schema == SchemaChannel {
+			return stop
+		}
+		return nil
+	})
+	require.Equal(t, stop, err)
+	require.Equal(t, []string{
+		"etcd/bundles.json olm.bundle etcd/etcdoperator.v0.9.2",
+		"etcd/bundles.json olm.bundle etcd/etcdoperator.v0.9.4",
+		"etcd/bundles.json example.com/notes etcd/release",
+		"etcd/catalog.yaml olm.package etcd",
+		"etcd/catalog.yaml olm.channel etcd/stable",
+	}, walked)
+}
+
+func TestGetCatalogFromFSErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string][]byte
+		want  string
+	}{
+		{
+			name:  "missing schema",
+			files: map[string][]byte{"catalog.yaml": []byte("schema: olm.package\nname: etcd\n---\nname: stable\n")},
+			want:  "unable to load catalog file catalog.yaml: blob 1 has no schema",
+		},
+		{
+			name:  "malformed JSON",
+			files: map[string][]byte{"catalog.json": []byte(`{"schema": "olm.package", "name": "etcd"} {"schema": `)},
+			want:  "unable to load catalog file catalog.json: blob 1: unexpected EOF",
+		},
+		{
+			name: "invalid blobs",
+			files: map[string][]byte{"catalog.json": []byte(`{"schema": "olm.channel", "package": "etcd", "name": "stable", "entries": {}}
+{"schema": "olm.bundle", "package": "etcd", "name": "etcdoperator.v0.9.4", "image": 4}`)},
+			want: `[catalog.json: invalid olm.channel blob "etcd/stable": json: cannot unmarshal object into Go struct field CatalogChannel.entries of type []manifests.CatalogChannelEntry, ` +
+				`catalog.json: invalid olm.bundle blob "etcd/etcdoperator.v0.9.4": json: cannot unmarshal number into Go struct field CatalogBundle.image of type string]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetCatalogFromFS(mustMapFS(t, tt.files))
+			require.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+func TestWriteCatalogRoundTrip(t *testing.T) {
+	catalog, err := GetCatalogFromFS(mustMapFS(t, etcdCatalogFS()))
+	require.NoError(t, err)
+	catalog.Others = append(catalog.Others, CatalogMeta{Schema: "example.com/global", Blob: json.RawMessage(`{"schema":"example.com/global"}`)})
+
+	dir := t.TempDir()
+	require.NoError(t, WriteCatalog(dir, catalog))
+	require.FileExists(t, dir+"/etcd/catalog.json")
+	require.FileExists(t, dir+"/catalog.json")
+	written, err := GetCatalogFromDir(dir)
+	require.NoError(t, err)
+	require.Equal(t, catalog.Packages, written.Packages)
+	require.Equal(t, catalog.Channels, written.Channels)
+	require.Equal(t, catalog.Bundles, written.Bundles)
+	require.Equal(t, catalog.Deprecations, written.Deprecations)
+	require.Len(t, written.Others, 2)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteCatalogYAML(buf, catalog))
+	fromYAML, err := GetCatalogFromFS(mustMapFS(t, map[string][]byte{"catalog.yaml": buf.Bytes()}))
+	require.NoError(t, err)
+	require.Equal(t, catalog.Packages, fromYAML.Packages)
+	require.Equal(t, catalog.Channels, fromYAML.Channels)
+	require.Equal(t, catalog.Bundles, fromYAML.Bundles)
+}
+
+func TestWriteCatalogJSON(t *testing.T) {
+	// Blobs built in code may not set their schema, and are written by package.
+	catalog := &Catalog{
+		Bundles: []CatalogBundle{{Name: "etcdoperator.v0.9.4", Package: "etcd", Image: "quay.io/etcd/bundle:v0.9.4"}},
+		Channels: []CatalogChannel{
+			{Name: "stable", Package: "etcd", Entries: []CatalogChannelEntry{{Name: "etcdoperator.v0.9.4", SkipRange: "<0.9.4"}}},
+		},
+		Packages: []CatalogPackage{{Name: "prometheus", DefaultChannel: "beta"}, {Name: "etcd", DefaultChannel: "stable"}},
+	}
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteCatalogJSON(buf, catalog))
+	require.Equal(t, `{
+    "schema": "olm.package",
+    "name": "etcd",
+    "defaultChannel": "stable"
+}
+{
+    "schema": "olm.channel",
+    "name": "stable",
+    "package": "etcd",
+    "entries": [
+        {
+            "name": "etcdoperator.v0.9.4",
+            "skipRange": "<0.9.4"
+        }
+    ]
+}
+{
+    "schema": "olm.bundle",
+    "name": "etcdoperator.v0.9.4",
+    "package": "etcd",
+    "image": "quay.io/etcd/bundle:v0.9.4"
+}
+{
+    "schema": "olm.package",
+    "name": "prometheus",
+    "defaultChannel": "beta"
+}
+`, buf.String())
+	require.Empty(t, catalog.Packages[0].Schema)
+}
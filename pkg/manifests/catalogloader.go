@@ -0,0 +1,159 @@
+package manifests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// WalkCatalogFunc is called by WalkCatalogFS with each blob of a catalog and
+// the slash-separated path of the file it was read from.
+type WalkCatalogFunc func(path string, meta CatalogMeta) error
+
+// WalkCatalogFS reads the blobs of the file-based catalog fsys one at a time,
+// in the order of its files and of the blobs of each file, and calls walk with
+// each of them, so that large catalogs can be processed without loading them
+// whole.
+//
+// The blobs are read from the .json, .yaml and .yml files of fsys: JSON files
+// hold a stream of JSON objects and YAML files a stream of YAML documents.
+// Hidden files and directories are skipped. Walking stops at the first error
+// reading a file, or returned by walk, which is returned.
+func WalkCatalogFS(fsys fs.FS, walk WalkCatalogFunc) error {
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !isCatalogFile(name) {
+			return nil
+		}
+		return walkCatalogFile(fsys, name, walk)
+	})
+}
+
+// GetCatalogFromDir loads the file-based catalog of the directory dir.
+func GetCatalogFromDir(dir string) (*Catalog, error) {
+	return GetCatalogFromFS(DirFS(dir))
+}
+
+// GetCatalogFromFS loads the file-based catalog of the file system fsys, such as
+// one returned by DirFS, TarFS, EmbedFS or MapFS. The blobs of the olm.package,
+// olm.channel, olm.bundle and olm.deprecations schemas are decoded into their
+// type, and the blobs of other schemas are kept in Catalog.Others.
+func GetCatalogFromFS(fsys fs.FS) (*Catalog, error) {
+	catalog := &Catalog{}
+	var errs []error
+	err := WalkCatalogFS(fsys, func(name string, meta CatalogMeta) error {
+		if err := catalog.add(meta); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", displayPath(fsys, name), err))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+	return catalog, nil
+}
+
+// isCatalogFile returns whether the file name holds catalog blobs.
+func isCatalogFile(name string) bool {
+	switch path.Ext(name) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// walkCatalogFile calls walk with each blob of the catalog file name. The
+// errors returned by walk are returned as is.
+func walkCatalogFile(fsys fs.FS, name string, walk WalkCatalogFunc) error {
+	fileErr := func(format string, args ...interface{}) error {
+		return fmt.Errorf("unable to load catalog file %s: %s", displayPath(fsys, name), fmt.Sprintf(format, args...))
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		return fileErr("%v", err)
+	}
+	defer f.Close()
+
+	next := nextJSONBlob(f)
+	if path.Ext(name) != ".json" {
+		next = nextYAMLBlob(f)
+	}
+	for i := 0; ; i++ {
+		data, err := next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fileErr("blob %d: %v", i, err)
+		}
+		var meta CatalogMeta
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return fileErr("blob %d: %v", i, err)
+		}
+		if meta.Schema == "" {
+			return fileErr("blob %d has no schema", i)
+		}
+		if err := walk(name, meta); err != nil {
+			return err
+		}
+	}
+}
+
+// nextJSONBlob returns a func reading the next JSON object of r, compacted,
+// or io.EOF.
+func nextJSONBlob(r io.Reader) func() ([]byte, error) {
+	dec := json.NewDecoder(r)
+	return func() ([]byte, error) {
+		var data json.RawMessage
+		if err := dec.Decode(&data); err != nil {
+			return nil, err
+		}
+		// Compact the blob so that the raw values of its properties are.
+		buf := &bytes.Buffer{}
+		if err := json.Compact(buf, data); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+}
+
+// nextYAMLBlob returns a func reading the next non-empty YAML document of r,
+// converted to JSON, or io.EOF.
+func nextYAMLBlob(r io.Reader) func() ([]byte, error) {
+	reader := yaml.NewYAMLReader(bufio.NewReader(r))
+	return func() ([]byte, error) {
+		for {
+			chunk, err := reader.Read()
+			if err != nil {
+				return nil, err
+			}
+			data, err := yaml.ToJSON(chunk)
+			if err != nil {
+				return nil, err
+			}
+			if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+				continue
+			}
+			return unescapeHTML(data), nil
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package manifests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// CatalogFileName is the name of the file each package is written to by WriteCatalog.
+const CatalogFileName = "catalog.json"
+
+// WriteCatalogJSON writes the blobs of catalog to w as a stream of indented JSON
+// objects. Blobs are grouped by package, in the order of their names: the
+// olm.package blob first, then its channels, bundles, deprecations and the
+// blobs of other schemas. The blobs which belong to no package are written last.
+func WriteCatalogJSON(w io.Writer, catalog *Catalog) error {
+	blobs, err := catalogBlobs(catalog)
+	if err != nil {
+		return err
+	}
+	for _, blob := range blobs {
+		if err := writeJSONBlob(w, blob.Blob); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCatalogYAML writes the blobs of catalog to w as a stream of YAML
+// documents, in the order of WriteCatalogJSON.
+func WriteCatalogYAML(w io.Writer, catalog *Catalog) error {
+	blobs, err := catalogBlobs(catalog)
+	if err != nil {
+		return err
+	}
+	for _, blob := range blobs {
+		data, err := marshalYAML(blob.Blob)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "---\n%s", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCatalog writes catalog to the directory dir, which is created if needed,
+// with the blobs of each package written to <dir>/<package>/catalog.json as
+// by WriteCatalogJSON, and the blobs which belong to no package to
+// <dir>/catalog.json.
+func WriteCatalog(dir string, catalog *Catalog) error {
+	blobs, err := catalogBlobs(catalog)
+	if err != nil {
+		return err
+	}
+	files := map[string]*bytes.Buffer{}
+	var names []string
+	for _, blob := range blobs {
+		name := filepath.Join(dir, blob.pkg, CatalogFileName)
+		if _, ok := files[name]; !ok {
+			files[name] = &bytes.Buffer{}
+			names = append(names, name)
+		}
+		if err := writeJSONBlob(files[name], blob.Blob); err != nil {
+			return err
+		}
+	}
+	for _, name := range names {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(name, files[name].Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// catalogBlob is a blob to write, along with the package it belongs to.
+type catalogBlob struct {
+	CatalogMeta
+	pkg string
+}
+
+// catalogBlobs returns the blobs of catalog in the order they are written.
+func catalogBlobs(catalog *Catalog) ([]catalogBlob, error) {
+	var blobs []catalogBlob
+	add := func(schema, pkg, name string, v interface{}) error {
+		buf := &bytes.Buffer{}
+		enc := json.NewEncoder(buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("unable to write %s blob %q: %v", schema, name, err)
+		}
+		data := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+		blobs = append(blobs, catalogBlob{CatalogMeta: CatalogMeta{Schema: schema, Package: pkg, Name: name, Blob: data}, pkg: pkg})
+		return nil
+	}
+
+	// The schema of typed blobs is set for the ones built without it.
+	for _, p := range catalog.Packages {
+		p.Schema = SchemaPackage
+		if err := add(SchemaPackage, p.Name, p.Name, p); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range catalog.Channels {
+		c.Schema = SchemaChannel
+		if err := add(SchemaChannel, c.Package, c.Name, c); err != nil {
+			return nil, err
+		}
+	}
+	for _, b := range catalog.Bundles {
+		b.Schema = SchemaBundle
+		if err := add(SchemaBundle, b.Package, b.Name, b); err != nil {
+			return nil, err
+		}
+	}
+	for _, d := range catalog.Deprecations {
+		d.Schema = SchemaDeprecations
+		if err := add(SchemaDeprecations, d.Package, "", d); err != nil {
+			return nil, err
+		}
+	}
+	for _, o := range catalog.Others {
+		pkg := o.Package
+		if o.Schema == SchemaPackage {
+			pkg = o.Name
+		}
+		blobs = append(blobs, catalogBlob{CatalogMeta: o, pkg: pkg})
+	}
+
+	order := map[string]int{SchemaPackage: 0, SchemaChannel: 1, SchemaBundle: 2, SchemaDeprecations: 3}
+	rank := func(schema string) int {
+		if r, ok := order[schema]; ok {
+			return r
+		}
+		return len(order)
+	}
+	sort.SliceStable(blobs, func(i, j int) bool {
+		a, b := blobs[i], blobs[j]
+		switch {
+		case a.pkg != b.pkg:
+			// The blobs of no package go last.
+			return b.pkg == "" || a.pkg != "" && a.pkg < b.pkg
+		case rank(a.Schema) != rank(b.Schema):
+			return rank(a.Schema) < rank(b.Schema)
+		case a.Schema != b.Schema:
+			return a.Schema < b.Schema
+		}
+		return a.Name < b.Name
+	})
+	return blobs, nil
+}
+
+// writeJSONBlob writes the indented JSON of blob to w.
+func writeJSONBlob(w io.Writer, blob json.RawMessage) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	return enc.Encode(blob)
+}
@@ -0,0 +1,86 @@
+package manifests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BundleDockerfileName is the name of the Dockerfile written to the bundle
+// directories converted from package manifests.
+const BundleDockerfileName = "bundle.Dockerfile"
+
+// SetPackageManifestChannels sets the package, channels and default channel of
+// the bundles of the package manifest pkg, as loaded by GetManifestsDir, so
+// that they can be written in the bundle format.
+//
+// A bundle is in the channels it is a bundle of, as returned by
+// PackageChannel.Bundles. The default channel is the one of pkg or, if pkg has
+// a single channel, that channel. An error is returned for the bundles which
+// are in no channel, which are left unchanged.
+func SetPackageManifestChannels(pkg *PackageManifest, bundles []*Bundle) error {
+	defaultChannel := pkg.DefaultChannelName
+	if defaultChannel == "" && len(pkg.Channels) == 1 {
+		defaultChannel = pkg.Channels[0].Name
+	}
+
+	channels := map[*Bundle][]string{}
+	var errs []string
+	for _, channel := range pkg.Channels {
+		channelBundles := channel.Bundles(bundles)
+		if len(channelBundles) == 0 {
+			errs = append(errs, fmt.Sprintf("current CSV %s of channel %s not found", channel.CurrentCSVName, channel.Name))
+			continue
+		}
+		for _, bundle := range channelBundles {
+			channels[bundle] = append(channels[bundle], channel.Name)
+		}
+	}
+
+	for _, bundle := range bundles {
+		if len(channels[bundle]) == 0 {
+			errs = append(errs, fmt.Sprintf("bundle %s is not in any channel", bundle.Name))
+			continue
+		}
+		bundle.Package = pkg.PackageName
+		bundle.Channels = channels[bundle]
+		bundle.DefaultChannel = defaultChannel
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("unable to convert package %s: %s", pkg.PackageName, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// ConvertPackageManifestDir converts the package manifest directory dir into
+// bundle directories, one per CSV, written to outputDir/<csv name> along with
+// their bundle.Dockerfile, and returns the paths of the directories written.
+// Nothing is written if any bundle cannot be converted.
+func ConvertPackageManifestDir(dir, outputDir string) ([]string, error) {
+	pkg, bundles, err := GetManifestsDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if pkg == nil {
+		return nil, fmt.Errorf("no package manifest found in directory %s", dir)
+	}
+	if err := SetPackageManifestChannels(pkg, bundles); err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	for _, bundle := range bundles {
+		bundleDir := filepath.Join(outputDir, bundle.CSV.GetName())
+		if err := WriteBundle(bundleDir, bundle); err != nil {
+			return dirs, fmt.Errorf("unable to write bundle %s: %v", bundle.Name, err)
+		}
+		if err := os.WriteFile(filepath.Join(bundleDir, BundleDockerfileName), BundleDockerfile(bundle, "."), 0644); err != nil {
+			return dirs, fmt.Errorf("unable to write bundle %s: %v", bundle.Name, err)
+		}
+		dirs = append(dirs, bundleDir)
+	}
+	return dirs, nil
+}
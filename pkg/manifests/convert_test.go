@@ -0,0 +1,123 @@
+package manifests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/require"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+func TestConvertPackageManifestDir(t *testing.T) {
+	outputDir := t.TempDir()
+	dirs, err := ConvertPackageManifestDir("./testdata/valid_package", outputDir)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(outputDir, "etcdoperator.v0.9.2"),
+		filepath.Join(outputDir, "etcdoperator.v0.9.4"),
+	}, dirs)
+
+	for _, dir := range dirs {
+		bundle, err := GetBundleFromDir(dir)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Base(dir), bundle.Name)
+		require.Equal(t, "etcd", bundle.Package)
+		require.Equal(t, []string{"singlenamespace-alpha"}, bundle.Channels)
+		require.Equal(t, "singlenamespace-alpha", bundle.DefaultChannel)
+		require.Equal(t, 3, len(bundle.V1beta1CRDs)+len(bundle.V1CRDs))
+
+		dockerfile, err := os.ReadFile(filepath.Join(dir, BundleDockerfileName))
+		require.NoError(t, err)
+		require.Equal(t, string(BundleDockerfile(bundle, ".")), string(dockerfile))
+	}
+
+	_, err = ConvertPackageManifestDir("./testdata/valid_bundle", outputDir)
+	require.EqualError(t, err, "no package manifest found in directory ./testdata/valid_bundle")
+}
+
+func TestSetPackageManifestChannels(t *testing.T) {
+	bundle := func(name, replaces string, skips ...string) *Bundle {
+		csv := &operatorsv1alpha1.ClusterServiceVersion{}
+		csv.SetName(name)
+		csv.Spec.Replaces = replaces
+		csv.Spec.Skips = skips
+		return &Bundle{Name: name, CSV: csv}
+	}
+	v1 := bundle("operator.v1.0.0", "")
+	v2 := bundle("operator.v2.0.0", "operator.v1.0.0")
+	v3 := bundle("operator.v3.0.0", "operator.v2.0.0")
+	v4 := bundle("operator.v4.0.0", "operator.v3.0.0", "operator.v3.1.0")
+	v31 := bundle("operator.v3.1.0", "operator.v3.0.0")
+	bundles := []*Bundle{v1, v2, v3, v31, v4}
+
+	pkg := &PackageManifest{
+		PackageName: "operator",
+		Channels: []PackageChannel{
+			{Name: "stable", CurrentCSVName: "operator.v2.0.0"},
+			{Name: "fast", CurrentCSVName: "operator.v4.0.0"},
+		},
+		DefaultChannelName: "stable",
+	}
+	require.NoError(t, SetPackageManifestChannels(pkg, bundles))
+	require.Equal(t, []string{"stable", "fast"}, v1.Channels)
+	require.Equal(t, []string{"stable", "fast"}, v2.Channels)
+	require.Equal(t, []string{"fast"}, v3.Channels)
+	require.Equal(t, []string{"fast"}, v31.Channels)
+	require.Equal(t, []string{"fast"}, v4.Channels)
+	for _, b := range bundles {
+		require.Equal(t, "operator", b.Package)
+		require.Equal(t, "stable", b.DefaultChannel)
+	}
+
+	// Bundles which are in no channel are reported.
+	orphan := bundle("operator.v0.1.0", "")
+	single := &PackageManifest{
+		PackageName: "operator",
+		Channels: []PackageChannel{
+			{Name: "stable", CurrentCSVName: "operator.v2.0.0"},
+			{Name: "beta", CurrentCSVName: "operator.v5.0.0"},
+		},
+	}
+	err := SetPackageManifestChannels(single, []*Bundle{v1, v2, orphan})
+	require.EqualError(t, err, "unable to convert package operator: bundle operator.v0.1.0 is not in any channel, current CSV operator.v5.0.0 of channel beta not found")
+	require.Empty(t, orphan.Channels)
+
+	// The single channel of a package is its default channel.
+	single.Channels = single.Channels[:1]
+	require.NoError(t, SetPackageManifestChannels(single, []*Bundle{v1, v2}))
+	require.Equal(t, "stable", v1.DefaultChannel)
+	require.Equal(t, []string{"stable"}, v1.Channels)
+}
+
+func TestSetPackageManifestChannelsSkipRange(t *testing.T) {
+	bundle := func(name, version, skipRange string) *Bundle {
+		csv := &operatorsv1alpha1.ClusterServiceVersion{}
+		csv.SetName(name)
+		csv.Spec.Version.Version = semver.MustParse(version)
+		if skipRange != "" {
+			csv.SetAnnotations(map[string]string{operatorsv1alpha1.SkipRangeAnnotationKey: skipRange})
+		}
+		return &Bundle{Name: name, CSV: csv}
+	}
+	// The head only skips the other bundles with its olm.skipRange.
+	v1 := bundle("operator.v1.0.0", "1.0.0", "")
+	v11 := bundle("operator.v1.1.0", "1.1.0", "")
+	v2 := bundle("operator.v2.0.0", "2.0.0", ">=1.0.0 <2.0.0")
+	pkg := &PackageManifest{
+		PackageName: "operator",
+		Channels:    []PackageChannel{{Name: "stable", CurrentCSVName: "operator.v2.0.0"}},
+	}
+	require.NoError(t, SetPackageManifestChannels(pkg, []*Bundle{v1, v11, v2}))
+	for _, b := range []*Bundle{v1, v11, v2} {
+		require.Equal(t, []string{"stable"}, b.Channels, b.Name)
+	}
+	require.Equal(t, []*Bundle{v2, v1, v11}, pkg.Channels[0].Bundles([]*Bundle{v1, v11, v2}))
+
+	// Bundles out of the range are in no channel.
+	v0 := bundle("operator.v0.1.0", "0.1.0", "")
+	err := SetPackageManifestChannels(pkg, []*Bundle{v0, v1, v2})
+	require.EqualError(t, err, "unable to convert package operator: bundle operator.v0.1.0 is not in any channel")
+}
@@ -1,5 +1,9 @@
 package manifests
 
+import (
+	"io/fs"
+)
+
 // GetManifestsDir parses all bundles and a package manifest from a directory
 func GetManifestsDir(dir string) (*PackageManifest, []*Bundle, error) {
 	loader := NewPackageManifestLoader(dir)
@@ -25,3 +29,30 @@ func GetBundleFromDir(dir string) (*Bundle, error) {
 
 	return loader.bundle, nil
 }
+
+// GetManifestsFS parses all bundles and a package manifest from the file system fsys, such as
+// one returned by DirFS, TarFS, EmbedFS or MapFS
+func GetManifestsFS(fsys fs.FS) (*PackageManifest, []*Bundle, error) {
+	loader := NewPackageManifestLoaderFS(fsys)
+
+	err := loader.LoadPackage()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return loader.pkg, loader.bundles, nil
+}
+
+// GetBundleFromFS takes a file system containing an Operator Bundle, such as one returned by
+// DirFS, TarFS, EmbedFS or MapFS, and serializes its component files (CSVs, CRDs, other native
+// kube manifests) and returns it as a Bundle
+func GetBundleFromFS(fsys fs.FS) (*Bundle, error) {
+	loader := NewBundleLoaderFS(fsys)
+
+	err := loader.LoadBundle()
+	if err != nil {
+		return nil, err
+	}
+
+	return loader.bundle, nil
+}
@@ -0,0 +1,219 @@
+package manifests
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing/fstest"
+)
+
+// dirFS is the file system of a directory on disk. The paths of the files
+// loaded from it are reported as paths on disk.
+type dirFS struct {
+	fs.FS
+	dir string
+}
+
+// DirFS returns the file system of the directory dir on disk. Unlike os.DirFS,
+// the files of bundles loaded from it are reported with their path on disk.
+func DirFS(dir string) fs.FS {
+	return dirFS{FS: os.DirFS(dir), dir: dir}
+}
+
+// EmbedFS returns the file system of the directory dir of an embed.FS, ex.
+//
+//	//go:embed testdata/bundle
+//	var bundleFS embed.FS
+//
+//	fsys, err := manifests.EmbedFS(bundleFS, "testdata/bundle")
+func EmbedFS(fsys embed.FS, dir string) (fs.FS, error) {
+	return fs.Sub(fsys, dir)
+}
+
+// MapFS returns an in-memory file system holding files, keyed by their
+// slash-separated path, ex. "manifests/etcd.clusterserviceversion.yaml".
+func MapFS(files map[string][]byte) (fs.FS, error) {
+	fsys := fstest.MapFS{}
+	for name, data := range files {
+		name = path.Clean(strings.TrimPrefix(name, "/"))
+		if !fs.ValidPath(name) {
+			return nil, fmt.Errorf("invalid file path %q", name)
+		}
+		fsys[name] = &fstest.MapFile{Data: data, Mode: 0644}
+	}
+	return fsys, nil
+}
+
+const (
+	// maxTarFileSize is the maximum size in bytes of a file read from a tar
+	// archive. Bundles are limited to ~1MiB compressed.
+	maxTarFileSize = 32 << 20
+	// maxTarSize is the maximum size in bytes of the files read from a tar
+	// archive, which bounds the memory used to extract a compressed archive.
+	maxTarSize = 128 << 20
+	// maxTarLinks is the maximum number of symbolic links followed to resolve
+	// one, as for the ELOOP of Linux.
+	maxTarLinks = 40
+)
+
+// TarFS reads the tar archive r, which may be gzip compressed, into an
+// in-memory file system. Only the directories, regular files and links of the
+// archive are kept, links being copies of the files they link to. An error is
+// returned if a file is larger than 32MiB, the files are larger than 128MiB in
+// total or a link does not link to a file of the archive.
+func TarFS(r io.Reader) (fs.FS, error) {
+	fsys := fstest.MapFS{}
+	var size int64
+	err := untar(r, &size, func(name string, hdr *tar.Header, data []byte) error {
+		fsys[name] = tarFile(hdr, data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := resolveSymlinks(fsys); err != nil {
+		return nil, err
+	}
+	return fsys, nil
+}
+
+// untar calls add with the cleaned path, header and contents of each directory,
+// regular file and link of the tar archive r, which may be gzip compressed. The
+// contents of a hard link are the ones of the file it links to. The sizes of the
+// files read are added to size, which is shared by the archives read together,
+// such as the layers of an image, to bound their total size by maxTarSize.
+func untar(r io.Reader, size *int64, add func(name string, hdr *tar.Header, data []byte) error) error {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("unable to read gzip stream: %v", err)
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+
+	tr := tar.NewReader(r)
+	files := map[string][]byte{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read tar archive: %v", err)
+		}
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "/"))
+		if name == "." {
+			continue
+		}
+		if !fs.ValidPath(name) {
+			return fmt.Errorf("invalid file path %q in tar archive", hdr.Name)
+		}
+		var data []byte
+		switch hdr.Typeflag {
+		case tar.TypeDir, tar.TypeSymlink:
+		case tar.TypeReg:
+			if hdr.Size > maxTarFileSize {
+				return fmt.Errorf("file %s in tar archive is larger than %d bytes", hdr.Name, maxTarFileSize)
+			}
+			if *size += hdr.Size; *size > maxTarSize {
+				return fmt.Errorf("files in tar archive are larger than %d bytes", maxTarSize)
+			}
+			if data, err = io.ReadAll(io.LimitReader(tr, maxTarFileSize+1)); err != nil {
+				return fmt.Errorf("unable to read %s from tar archive: %v", hdr.Name, err)
+			}
+			if len(data) > maxTarFileSize {
+				return fmt.Errorf("file %s in tar archive is larger than %d bytes", hdr.Name, maxTarFileSize)
+			}
+			files[name] = data
+		case tar.TypeLink:
+			target := path.Clean(strings.TrimPrefix(hdr.Linkname, "/"))
+			var ok bool
+			if data, ok = files[target]; !ok {
+				return fmt.Errorf("hard link %s to %s not found in tar archive", hdr.Name, hdr.Linkname)
+			}
+			files[name] = data
+		default:
+			// Devices and named pipes have no contents.
+			continue
+		}
+		if err := add(name, hdr, data); err != nil {
+			return err
+		}
+	}
+}
+
+// tarFile returns the in-memory file of the directory, regular file or link
+// of hdr.
+func tarFile(hdr *tar.Header, data []byte) *fstest.MapFile {
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		return &fstest.MapFile{Mode: fs.ModeDir | fs.FileMode(hdr.Mode).Perm(), ModTime: hdr.ModTime}
+	case tar.TypeSymlink:
+		return &fstest.MapFile{Data: []byte(hdr.Linkname), Mode: fs.ModeSymlink | fs.FileMode(hdr.Mode).Perm(), ModTime: hdr.ModTime}
+	}
+	return &fstest.MapFile{Data: data, Mode: fs.FileMode(hdr.Mode).Perm(), ModTime: hdr.ModTime}
+}
+
+// resolveSymlinks replaces the symbolic links of fsys by copies of the regular
+// files they link to. Absolute links are relative to the root of fsys.
+func resolveSymlinks(fsys fstest.MapFS) error {
+	resolved := map[string]*fstest.MapFile{}
+	for name, link := range fsys {
+		if link.Mode.Type() != fs.ModeSymlink {
+			continue
+		}
+		target, file := name, link
+		for i := 0; file != nil && file.Mode.Type() == fs.ModeSymlink; i++ {
+			if i == maxTarLinks {
+				return fmt.Errorf("too many levels of symbolic links to resolve %s in tar archive", name)
+			}
+			if linkname := string(file.Data); path.IsAbs(linkname) {
+				target = strings.TrimPrefix(path.Clean(linkname), "/")
+			} else {
+				target = path.Join(path.Dir(target), linkname)
+			}
+			file = fsys[target]
+		}
+		if file == nil || !file.Mode.IsRegular() {
+			return fmt.Errorf("symbolic link %s to %s in tar archive does not link to a file", name, link.Data)
+		}
+		resolved[name] = &fstest.MapFile{Data: file.Data, Mode: file.Mode, ModTime: link.ModTime}
+	}
+	for name, file := range resolved {
+		fsys[name] = file
+	}
+	return nil
+}
+
+// TarFileFS reads the tar archive at path, which may be gzip compressed, into
+// an in-memory file system.
+func TarFileFS(path string) (fs.FS, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return TarFS(f)
+}
+
+// displayPath returns the path the file name of fsys is reported with: its
+// path on disk for a DirFS, else name.
+func displayPath(fsys fs.FS, name string) string {
+	if d, ok := fsys.(dirFS); ok {
+		return filepath.Join(d.dir, filepath.FromSlash(name))
+	}
+	return name
+}
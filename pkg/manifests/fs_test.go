@@ -0,0 +1,328 @@
+package manifests
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+//go:embed testdata/valid_bundle
+var validBundleFS embed.FS
+
+// tarDir returns a tar archive of the directory dir, with its files under prefix.
+func tarDir(t *testing.T, dir, prefix string, compress bool) []byte {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	require.NoError(t, filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		require.NoError(t, err)
+		rel, err := filepath.Rel(dir, path)
+		require.NoError(t, err)
+		name := filepath.ToSlash(filepath.Join(prefix, rel))
+		if d.IsDir() {
+			return tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: name + "/", Mode: 0755})
+		}
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644, Size: int64(len(data))}))
+		_, err = tw.Write(data)
+		return err
+	}))
+	require.NoError(t, tw.Close())
+	if !compress {
+		return buf.Bytes()
+	}
+	gzBuf := &bytes.Buffer{}
+	gz := gzip.NewWriter(gzBuf)
+	_, err := gz.Write(buf.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return gzBuf.Bytes()
+}
+
+func TestGetBundleFromFS(t *testing.T) {
+	embedded, err := EmbedFS(validBundleFS, "testdata/valid_bundle")
+	require.NoError(t, err)
+	tarred, err := TarFS(bytes.NewReader(tarDir(t, "./testdata/valid_bundle", "manifests", false)))
+	require.NoError(t, err)
+	gzipped, err := TarFS(bytes.NewReader(tarDir(t, "./testdata/valid_bundle", "./", true)))
+	require.NoError(t, err)
+	files := map[string][]byte{}
+	entries, err := os.ReadDir("./testdata/valid_bundle")
+	require.NoError(t, err)
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join("testdata", "valid_bundle", entry.Name()))
+		require.NoError(t, err)
+		files["manifests/"+entry.Name()] = data
+	}
+	inMemory, err := MapFS(files)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		fsys    fs.FS
+		csvFile string
+	}{
+		{name: "dir", fsys: DirFS("./testdata/valid_bundle"), csvFile: filepath.Join("testdata", "valid_bundle", "etcdoperator.v0.9.4.clusterserviceversion.yaml")},
+		{name: "embed", fsys: embedded, csvFile: "etcdoperator.v0.9.4.clusterserviceversion.yaml"},
+		{name: "tar", fsys: tarred, csvFile: "manifests/etcdoperator.v0.9.4.clusterserviceversion.yaml"},
+		{name: "tar.gz", fsys: gzipped, csvFile: "etcdoperator.v0.9.4.clusterserviceversion.yaml"},
+		{name: "map", fsys: inMemory, csvFile: "manifests/etcdoperator.v0.9.4.clusterserviceversion.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle, err := GetBundleFromFS(tt.fsys)
+			require.NoError(t, err)
+			require.Equal(t, "etcdoperator.v0.9.4", bundle.Name)
+			require.NotNil(t, bundle.CSV)
+			require.Equal(t, 3, len(bundle.V1beta1CRDs))
+			require.Equal(t, 4, len(bundle.Objects))
+			require.NotZero(t, bundle.Size)
+			require.NotZero(t, bundle.CompressedSize)
+
+			pos, found := bundle.Sources.Lookup("ClusterServiceVersion", "etcdoperator.v0.9.4", "")
+			require.True(t, found)
+			require.Equal(t, tt.csvFile, pos.File)
+		})
+	}
+}
+
+func TestGetManifestsFS(t *testing.T) {
+	fsys, err := TarFS(bytes.NewReader(tarDir(t, "./testdata/valid_package", "etcd", true)))
+	require.NoError(t, err)
+
+	pkg, bundles, err := GetManifestsFS(fsys)
+	require.NoError(t, err)
+	require.Equal(t, "etcd", pkg.PackageName)
+	require.Equal(t, 2, len(bundles))
+	require.Equal(t, "etcdoperator.v0.9.2", bundles[0].Name)
+	require.Equal(t, "etcdoperator.v0.9.4", bundles[1].Name)
+}
+
+func TestLoadBundleFSErrors(t *testing.T) {
+	fsys, err := MapFS(map[string][]byte{
+		"manifests/.hidden": []byte("hidden"),
+		"manifests/foo/bar": []byte("nested"),
+	})
+	require.NoError(t, err)
+	_, err = loadBundleFS(fsys, "test-operator.v0.0.1", "manifests")
+	require.EqualError(t, err, "[bundle manifests dir has hidden file: manifests/.hidden, bundle manifests dir contains directory: manifests/foo]")
+
+	empty, err := MapFS(nil)
+	require.NoError(t, err)
+	_, err = GetBundleFromFS(empty)
+	require.EqualError(t, err, "unable to find a csv in bundle directory .")
+}
+
+func TestTarFSInvalidPath(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "../escape.yaml", Mode: 0644}))
+	require.NoError(t, tw.Close())
+
+	_, err := TarFS(buf)
+	require.EqualError(t, err, `invalid file path "../escape.yaml" in tar archive`)
+
+	_, err = TarFS(bytes.NewReader([]byte{0x1f, 0x8b, 0x00}))
+	require.Error(t, err)
+}
+
+func TestTarFSFileTooLarge(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	// Only the header is written: the size is checked before the file is read.
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "manifests/large.yaml", Mode: 0644, Size: maxTarFileSize + 1}))
+
+	_, err := TarFS(buf)
+	require.EqualError(t, err, fmt.Sprintf("file manifests/large.yaml in tar archive is larger than %d bytes", maxTarFileSize))
+}
+
+func TestUntarSharedSize(t *testing.T) {
+	layer := tarFiles(t, map[string]string{"manifests/a.yaml": "12345678"})
+	add := func(string, *tar.Header, []byte) error { return nil }
+
+	// Each archive is within the limit, but not both together.
+	size := int64(maxTarSize - 10)
+	require.NoError(t, untar(bytes.NewReader(layer), &size, add))
+	err := untar(bytes.NewReader(layer), &size, add)
+	require.EqualError(t, err, fmt.Sprintf("files in tar archive are larger than %d bytes", maxTarSize))
+}
+
+func TestTarFSLinks(t *testing.T) {
+	tarLinks := func(links ...tar.Header) io.Reader {
+		buf := &bytes.Buffer{}
+		tw := tar.NewWriter(buf)
+		data := []byte("kind: ConfigMap\n")
+		require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "manifests/cm.yaml", Mode: 0644, Size: int64(len(data))}))
+		_, err := tw.Write(data)
+		require.NoError(t, err)
+		for _, hdr := range links {
+			hdr.Mode = 0777
+			require.NoError(t, tw.WriteHeader(&hdr))
+		}
+		require.NoError(t, tw.Close())
+		return buf
+	}
+
+	fsys, err := TarFS(tarLinks(
+		tar.Header{Typeflag: tar.TypeSymlink, Name: "manifests/relative.yaml", Linkname: "cm.yaml"},
+		tar.Header{Typeflag: tar.TypeSymlink, Name: "absolute.yaml", Linkname: "/manifests/cm.yaml"},
+		tar.Header{Typeflag: tar.TypeSymlink, Name: "chained.yaml", Linkname: "manifests/relative.yaml"},
+		tar.Header{Typeflag: tar.TypeLink, Name: "manifests/hard.yaml", Linkname: "manifests/cm.yaml"},
+	))
+	require.NoError(t, err)
+	for _, name := range []string{"manifests/relative.yaml", "absolute.yaml", "chained.yaml", "manifests/hard.yaml"} {
+		data, err := fs.ReadFile(fsys, name)
+		require.NoError(t, err, name)
+		require.Equal(t, "kind: ConfigMap\n", string(data), name)
+	}
+
+	_, err = TarFS(tarLinks(tar.Header{Typeflag: tar.TypeSymlink, Name: "manifests/missing.yaml", Linkname: "../../etc/passwd"}))
+	require.EqualError(t, err, "symbolic link manifests/missing.yaml to ../../etc/passwd in tar archive does not link to a file")
+
+	_, err = TarFS(tarLinks(tar.Header{Typeflag: tar.TypeSymlink, Name: "dir", Linkname: "manifests"}))
+	require.EqualError(t, err, "symbolic link dir to manifests in tar archive does not link to a file")
+
+	_, err = TarFS(tarLinks(
+		tar.Header{Typeflag: tar.TypeSymlink, Name: "a.yaml", Linkname: "b.yaml"},
+		tar.Header{Typeflag: tar.TypeSymlink, Name: "b.yaml", Linkname: "a.yaml"},
+	))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "too many levels of symbolic links")
+
+	_, err = TarFS(tarLinks(tar.Header{Typeflag: tar.TypeLink, Name: "hard.yaml", Linkname: "missing.yaml"}))
+	require.EqualError(t, err, "hard link hard.yaml to missing.yaml not found in tar archive")
+}
+
+func TestLoadBundleMultipleDocuments(t *testing.T) {
+	files := map[string][]byte{}
+	entries, err := os.ReadDir("./testdata/valid_bundle")
+	require.NoError(t, err)
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join("testdata", "valid_bundle", entry.Name()))
+		require.NoError(t, err)
+		files["manifests/"+entry.Name()] = data
+	}
+	files["manifests/rbac.yaml"] = []byte(`---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: etcd-role
+---
+# the binding of the role
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: etcd-rolebinding
+---
+`)
+	fsys, err := MapFS(files)
+	require.NoError(t, err)
+
+	bundle, err := GetBundleFromFS(fsys)
+	require.NoError(t, err)
+	require.Equal(t, 6, len(bundle.Objects))
+	kinds := []string{}
+	for _, obj := range bundle.Objects {
+		kinds = append(kinds, obj.GetKind())
+	}
+	require.Contains(t, kinds, "Role")
+	require.Contains(t, kinds, "RoleBinding")
+	require.Equal(t, []LoadWarning{{
+		Position: Position{File: "manifests/rbac.yaml", Line: 8, Column: 1},
+		Detail:   "RoleBinding etcd-rolebinding is not the first document of manifests/rbac.yaml and is ignored by tools which only load the first document of each manifest file",
+	}}, bundle.Warnings)
+}
+
+func TestLoadBundleMultipleDocumentsPositions(t *testing.T) {
+	files := map[string][]byte{}
+	entries, err := os.ReadDir("./testdata/valid_bundle")
+	require.NoError(t, err)
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join("testdata", "valid_bundle", entry.Name()))
+		require.NoError(t, err)
+		files["manifests/"+entry.Name()] = data
+	}
+	// The objects of the second documents of rbac.yaml are also found in
+	// another file, and twice in rbac.yaml.
+	files["manifests/binding.yaml"] = []byte(`apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: etcd-rolebinding
+`)
+	files["manifests/rbac.yaml"] = []byte(`apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: etcd-role
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: etcd-rolebinding
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: etcd-rolebinding
+`)
+	fsys, err := MapFS(files)
+	require.NoError(t, err)
+
+	bundle, err := GetBundleFromFS(fsys)
+	require.NoError(t, err)
+	detail := "RoleBinding etcd-rolebinding is not the first document of manifests/rbac.yaml and is ignored by tools which only load the first document of each manifest file"
+	require.Equal(t, []LoadWarning{
+		{Position: Position{File: "manifests/rbac.yaml", Line: 6, Column: 1}, Detail: detail},
+		{Position: Position{File: "manifests/rbac.yaml", Line: 11, Column: 1}, Detail: detail},
+	}, bundle.Warnings)
+}
+
+func TestLoadBundleMultipleDocumentsCSV(t *testing.T) {
+	csv, err := os.ReadFile("./testdata/valid_bundle/etcdoperator.v0.9.4.clusterserviceversion.yaml")
+	require.NoError(t, err)
+	crd, err := os.ReadFile("./testdata/valid_bundle/etcdclusters.etcd.database.coreos.com.crd.yaml")
+	require.NoError(t, err)
+	fsys, err := MapFS(map[string][]byte{
+		"manifests/bundle.yaml": bytes.Join([][]byte{crd, csv}, []byte("\n---\n")),
+	})
+	require.NoError(t, err)
+
+	bundle, err := GetBundleFromFS(fsys)
+	require.NoError(t, err)
+	require.NotNil(t, bundle.CSV)
+	require.Equal(t, "etcdoperator.v0.9.4", bundle.Name)
+	require.Equal(t, 1, len(bundle.V1beta1CRDs))
+	require.Equal(t, 1, len(bundle.Warnings))
+}
+
+func TestLoadPackageManifestMultipleDocumentsCSV(t *testing.T) {
+	pkg, err := os.ReadFile("./testdata/valid_package/package.yaml")
+	require.NoError(t, err)
+	files := map[string][]byte{"package.yaml": pkg}
+	for _, version := range []string{"0.9.2", "0.9.4"} {
+		dir := filepath.Join("testdata", "valid_package", version)
+		csv, err := os.ReadFile(filepath.Join(dir, "etcdoperator.v"+version+".clusterserviceversion.yaml"))
+		require.NoError(t, err)
+		crd, err := os.ReadFile(filepath.Join(dir, "etcdclusters.etcd.database.coreos.com.crd.yaml"))
+		require.NoError(t, err)
+		// The CSV is not the first document of its file.
+		files[version+"/bundle.yaml"] = bytes.Join([][]byte{crd, csv}, []byte("\n---\n"))
+	}
+	fsys, err := MapFS(files)
+	require.NoError(t, err)
+
+	manifest, bundles, err := GetManifestsFS(fsys)
+	require.NoError(t, err)
+	require.Equal(t, "etcd", manifest.PackageName)
+	require.Equal(t, 2, len(bundles))
+	require.Equal(t, "etcdoperator.v0.9.2", bundles[0].Name)
+	require.Equal(t, "etcdoperator.v0.9.4", bundles[1].Name)
+}
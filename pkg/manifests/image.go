@@ -0,0 +1,241 @@
+package manifests
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"testing/fstest"
+)
+
+// Image is a bundle image read from a local OCI image layout or docker save archive.
+type Image struct {
+	// Reference is the name the image was tagged with, if any.
+	Reference string
+	// Labels are the labels of the image config, which include the
+	// operators.operatorframework.io.bundle.* labels of bundle images.
+	Labels map[string]string
+	// FS is the file system of the image, with its layers flattened.
+	FS fs.FS
+}
+
+// Media types of the image indexes and manifests of OCI image layouts.
+const (
+	mediaTypeOCIIndex     = "application/vnd.oci.image.index.v1+json"
+	mediaTypeDockerList   = "application/vnd.docker.distribution.manifest.list.v2+json"
+	refNameAnnotation     = "org.opencontainers.image.ref.name"
+	whiteoutPrefix        = ".wh."
+	whiteoutOpaqueDirName = ".wh..wh..opq"
+)
+
+// ociDescriptor is a descriptor of an OCI image index or manifest.
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ociIndex struct {
+	Manifests []ociDescriptor `json:"manifests"`
+}
+
+type ociManifest struct {
+	Config ociDescriptor   `json:"config"`
+	Layers []ociDescriptor `json:"layers"`
+}
+
+// dockerArchiveManifest is an entry of the manifest.json file of a docker save archive.
+type dockerArchiveManifest struct {
+	Config   string   `json:"Config"`
+	RepoTags []string `json:"RepoTags"`
+	Layers   []string `json:"Layers"`
+}
+
+// imageConfig is the subset of an image config holding the image labels.
+type imageConfig struct {
+	Config struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"config"`
+}
+
+// OCILayoutImage reads the image of the OCI image layout in fsys, such as
+// DirFS(dir). The layout must hold a single image. If that image is an image
+// index, such as a multi-platform image, its first manifest is read, since the
+// manifests of a bundle do not depend on the platform.
+func OCILayoutImage(fsys fs.FS) (*Image, error) {
+	if _, err := fs.Stat(fsys, "oci-layout"); err != nil {
+		return nil, fmt.Errorf("not an OCI image layout: %v", err)
+	}
+	index := ociIndex{}
+	if err := readJSON(fsys, "index.json", &index); err != nil {
+		return nil, err
+	}
+	if len(index.Manifests) != 1 {
+		return nil, fmt.Errorf("found %d images in OCI image layout, expected one", len(index.Manifests))
+	}
+	desc := index.Manifests[0]
+	reference := desc.Annotations[refNameAnnotation]
+	for desc.MediaType == mediaTypeOCIIndex || desc.MediaType == mediaTypeDockerList {
+		nested := ociIndex{}
+		if err := readJSON(fsys, blobPath(desc.Digest), &nested); err != nil {
+			return nil, err
+		}
+		if len(nested.Manifests) == 0 {
+			return nil, fmt.Errorf("image index %s has no manifests", desc.Digest)
+		}
+		desc = nested.Manifests[0]
+	}
+
+	manifest := ociManifest{}
+	if err := readJSON(fsys, blobPath(desc.Digest), &manifest); err != nil {
+		return nil, err
+	}
+	layers := make([]string, 0, len(manifest.Layers))
+	for _, layer := range manifest.Layers {
+		layers = append(layers, blobPath(layer.Digest))
+	}
+	return readImage(fsys, reference, blobPath(manifest.Config.Digest), layers)
+}
+
+// DockerArchiveImage reads the image of the docker save archive whose contents
+// are fsys, such as the file system returned by TarFileFS. The archive must
+// hold a single image.
+func DockerArchiveImage(fsys fs.FS) (*Image, error) {
+	var manifests []dockerArchiveManifest
+	if err := readJSON(fsys, "manifest.json", &manifests); err != nil {
+		return nil, fmt.Errorf("not a docker save archive: %v", err)
+	}
+	if len(manifests) != 1 {
+		return nil, fmt.Errorf("found %d images in docker save archive, expected one", len(manifests))
+	}
+	manifest := manifests[0]
+	reference := ""
+	if len(manifest.RepoTags) > 0 {
+		reference = manifest.RepoTags[0]
+	}
+	return readImage(fsys, reference, manifest.Config, manifest.Layers)
+}
+
+// readImage reads the image of fsys whose config and layers are at the given paths.
+func readImage(fsys fs.FS, reference, configPath string, layerPaths []string) (*Image, error) {
+	config := imageConfig{}
+	if err := readJSON(fsys, configPath, &config); err != nil {
+		return nil, err
+	}
+	image := fstest.MapFS{}
+	// The size of the files of all layers is bounded, not the one of each layer.
+	var size int64
+	for _, layerPath := range layerPaths {
+		layer, err := fsys.Open(layerPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read layer: %v", err)
+		}
+		err = untar(layer, &size, func(name string, hdr *tar.Header, data []byte) error {
+			applyLayerFile(image, name, hdr, data)
+			return nil
+		})
+		layer.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read layer %s: %v", layerPath, err)
+		}
+	}
+	if err := resolveSymlinks(image); err != nil {
+		return nil, err
+	}
+	return &Image{Reference: reference, Labels: config.Config.Labels, FS: image}, nil
+}
+
+// applyLayerFile adds the file name of a layer to image, applying the whiteout
+// files which remove the files of the layers below.
+func applyLayerFile(image fstest.MapFS, name string, hdr *tar.Header, data []byte) {
+	dir, base := path.Split(name)
+	switch {
+	case base == whiteoutOpaqueDirName:
+		removeAll(image, path.Clean(dir), false)
+	case strings.HasPrefix(base, whiteoutPrefix):
+		removeAll(image, path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)), true)
+	default:
+		image[name] = tarFile(hdr, data)
+	}
+}
+
+// removeAll removes the files under dir and, if self, dir itself.
+func removeAll(image fstest.MapFS, dir string, self bool) {
+	for name := range image {
+		if (self && name == dir) || strings.HasPrefix(name, dir+"/") {
+			delete(image, name)
+		}
+	}
+}
+
+// GetBundleFromImage loads the bundle of img. The package and channels of the
+// bundle are read from the operators.operatorframework.io.bundle.* labels of
+// the image, if set, rather than from its annotations file.
+func GetBundleFromImage(img *Image) (*Bundle, error) {
+	bundle, err := GetBundleFromFS(img.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := Annotations{}
+	data, err := json.Marshal(img.Labels)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &labels); err != nil {
+		return nil, fmt.Errorf("invalid bundle image labels: %v", err)
+	}
+	if labels.PackageName != "" {
+		bundle.Package = labels.PackageName
+	}
+	if labels.Channels != "" {
+		bundle.Channels = strings.Split(labels.Channels, ",")
+	}
+	if labels.DefaultChannelName != "" {
+		bundle.DefaultChannel = labels.DefaultChannelName
+	}
+	bundle.BundleImage = img.Reference
+	return bundle, nil
+}
+
+// GetBundleFromOCILayout loads the bundle image of the OCI image layout directory dir.
+func GetBundleFromOCILayout(dir string) (*Bundle, error) {
+	img, err := OCILayoutImage(os.DirFS(dir))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read image from %s: %v", dir, err)
+	}
+	return GetBundleFromImage(img)
+}
+
+// GetBundleFromDockerArchive loads the bundle image of the docker save archive at path.
+func GetBundleFromDockerArchive(path string) (*Bundle, error) {
+	fsys, err := TarFileFS(path)
+	if err != nil {
+		return nil, err
+	}
+	img, err := DockerArchiveImage(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read image from %s: %v", path, err)
+	}
+	return GetBundleFromImage(img)
+}
+
+// blobPath returns the path of the blob with the given digest, ex.
+// "sha256:abc", in an OCI image layout.
+func blobPath(digest string) string {
+	return path.Join("blobs", strings.Replace(digest, ":", "/", 1))
+}
+
+func readJSON(fsys fs.FS, name string, v interface{}) error {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("unable to decode %s: %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package manifests
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var bundleImageLabels = map[string]string{
+	"operators.operatorframework.io.bundle.mediatype.v1":       "registry+v1",
+	"operators.operatorframework.io.bundle.manifests.v1":       "manifests/",
+	"operators.operatorframework.io.bundle.metadata.v1":        "metadata/",
+	"operators.operatorframework.io.bundle.package.v1":         "etcd",
+	"operators.operatorframework.io.bundle.channels.v1":        "alpha,stable",
+	"operators.operatorframework.io.bundle.channel.default.v1": "stable",
+}
+
+// tarFiles returns a tar archive of files, keyed by their path. Paths ending
+// with a slash are directories.
+func tarFiles(t *testing.T, files map[string]string) []byte {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for _, name := range names {
+		if strings.HasSuffix(name, "/") {
+			require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: name, Mode: 0755}))
+			continue
+		}
+		require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644, Size: int64(len(files[name]))}))
+		_, err := tw.Write([]byte(files[name]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+// bundleImageLayers returns the layers of a bundle image of valid_bundle. The
+// last layer removes a stale manifest and the contents of a directory added by
+// the second one.
+func bundleImageLayers(t *testing.T, compress bool) [][]byte {
+	layers := [][]byte{
+		tarDir(t, "./testdata/valid_bundle", "manifests", false),
+		tarFiles(t, map[string]string{
+			"manifests/stale.configmap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: stale\n",
+			"extra/":                         "",
+			"extra/file.txt":                 "removed",
+		}),
+		tarFiles(t, map[string]string{
+			"manifests/.wh.stale.configmap.yaml": "",
+			"extra/.wh..wh..opq":                 "",
+		}),
+	}
+	if compress {
+		for i, layer := range layers {
+			buf := &bytes.Buffer{}
+			gz := gzip.NewWriter(buf)
+			_, err := gz.Write(layer)
+			require.NoError(t, err)
+			require.NoError(t, gz.Close())
+			layers[i] = buf.Bytes()
+		}
+	}
+	return layers
+}
+
+func digest(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
+
+func writeJSON(t *testing.T, path string, v interface{}) []byte {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	if path != "" {
+		require.NoError(t, os.WriteFile(path, data, 0644))
+	}
+	return data
+}
+
+// writeOCILayout writes an OCI image layout of a bundle image to dir.
+func writeOCILayout(t *testing.T, dir string) {
+	blobs := filepath.Join(dir, "blobs", "sha256")
+	require.NoError(t, os.MkdirAll(blobs, 0755))
+	writeBlob := func(data []byte) map[string]interface{} {
+		d := digest(data)
+		require.NoError(t, os.WriteFile(filepath.Join(blobs, d[len("sha256:"):]), data, 0644))
+		return map[string]interface{}{"digest": d, "size": len(data)}
+	}
+
+	config := writeBlob(writeJSON(t, "", map[string]interface{}{"config": map[string]interface{}{"Labels": bundleImageLabels}}))
+	config["mediaType"] = "application/vnd.oci.image.config.v1+json"
+	layers := []interface{}{}
+	for _, layer := range bundleImageLayers(t, true) {
+		desc := writeBlob(layer)
+		desc["mediaType"] = "application/vnd.oci.image.layer.v1.tar+gzip"
+		layers = append(layers, desc)
+	}
+	manifest := writeBlob(writeJSON(t, "", map[string]interface{}{"schemaVersion": 2, "config": config, "layers": layers}))
+	manifest["mediaType"] = "application/vnd.oci.image.manifest.v1+json"
+	index := writeBlob(writeJSON(t, "", map[string]interface{}{"schemaVersion": 2, "manifests": []interface{}{manifest}}))
+	index["mediaType"] = mediaTypeOCIIndex
+	index["annotations"] = map[string]string{refNameAnnotation: "quay.io/example/etcd-bundle:v0.9.4"}
+
+	writeJSON(t, filepath.Join(dir, "oci-layout"), map[string]string{"imageLayoutVersion": "1.0.0"})
+	writeJSON(t, filepath.Join(dir, "index.json"), map[string]interface{}{"schemaVersion": 2, "manifests": []interface{}{index}})
+}
+
+// writeDockerArchive writes a docker save archive of a bundle image to path.
+func writeDockerArchive(t *testing.T, path string) {
+	files := map[string]string{}
+	config := writeJSON(t, "", map[string]interface{}{"config": map[string]interface{}{"Labels": bundleImageLabels}})
+	configPath := digest(config)[len("sha256:"):] + ".json"
+	files[configPath] = string(config)
+	layers := []string{}
+	for i, layer := range bundleImageLayers(t, false) {
+		layerPath := fmt.Sprintf("layer%d/layer.tar", i)
+		files[layerPath] = string(layer)
+		layers = append(layers, layerPath)
+	}
+	files["manifest.json"] = string(writeJSON(t, "", []interface{}{map[string]interface{}{
+		"Config":   configPath,
+		"RepoTags": []string{"quay.io/example/etcd-bundle:v0.9.4"},
+		"Layers":   layers,
+	}}))
+	require.NoError(t, os.WriteFile(path, tarFiles(t, files), 0644))
+}
+
+func TestGetBundleFromImage(t *testing.T) {
+	ociDir := t.TempDir()
+	writeOCILayout(t, ociDir)
+	archive := filepath.Join(t.TempDir(), "bundle.tar")
+	writeDockerArchive(t, archive)
+
+	tests := []struct {
+		name string
+		load func() (*Bundle, error)
+	}{
+		{name: "oci layout", load: func() (*Bundle, error) { return GetBundleFromOCILayout(ociDir) }},
+		{name: "docker archive", load: func() (*Bundle, error) { return GetBundleFromDockerArchive(archive) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle, err := tt.load()
+			require.NoError(t, err)
+			require.Equal(t, "etcdoperator.v0.9.4", bundle.Name)
+			require.Equal(t, 4, len(bundle.Objects))
+			require.Equal(t, "etcd", bundle.Package)
+			require.Equal(t, []string{"alpha", "stable"}, bundle.Channels)
+			require.Equal(t, "stable", bundle.DefaultChannel)
+			require.Equal(t, "quay.io/example/etcd-bundle:v0.9.4", bundle.BundleImage)
+		})
+	}
+}
+
+func TestImageWhiteouts(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "bundle.tar")
+	writeDockerArchive(t, archive)
+	fsys, err := TarFileFS(archive)
+	require.NoError(t, err)
+	img, err := DockerArchiveImage(fsys)
+	require.NoError(t, err)
+	require.Equal(t, bundleImageLabels, img.Labels)
+
+	_, err = fs.Stat(img.FS, "manifests/stale.configmap.yaml")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	entries, err := fs.ReadDir(img.FS, "extra")
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestImageErrors(t *testing.T) {
+	_, err := OCILayoutImage(DirFS("./testdata/valid_bundle"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not an OCI image layout")
+
+	fsys, err := MapFS(map[string][]byte{"manifest.json": []byte("[]")})
+	require.NoError(t, err)
+	_, err = DockerArchiveImage(fsys)
+	require.EqualError(t, err, "found 0 images in docker save archive, expected one")
+}
@@ -1,5 +1,11 @@
 package manifests
 
+import (
+	"github.com/blang/semver/v4"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
 // PackageManifest holds information about a package, which is a reference to one (or more)
 // channels under a single package.
 type PackageManifest struct {
@@ -30,3 +36,49 @@ type PackageChannel struct {
 	// for the channel.
 	CurrentCSVName string `json:"currentCSV" yaml:"currentCSV"`
 }
+
+// Bundles returns the bundles of the channel among bundles, as loaded by
+// GetManifestsDir: the bundle of its current CSV, first, and the bundles which
+// the current CSV replaces or skips, directly or not, or whose version is in
+// the olm.skipRange of the current CSV. Nil is returned if the bundle of the
+// current CSV is not found.
+func (c PackageChannel) Bundles(bundles []*Bundle) []*Bundle {
+	byName := map[string]*Bundle{}
+	for _, bundle := range bundles {
+		if bundle.CSV != nil {
+			byName[bundle.CSV.GetName()] = bundle
+		}
+	}
+	head, ok := byName[c.CurrentCSVName]
+	if !ok {
+		return nil
+	}
+
+	next := []string{c.CurrentCSVName}
+	// An invalid skip range is reported by the validators, and skips nothing here.
+	if inRange, err := semver.ParseRange(head.CSV.GetAnnotations()[operatorsv1alpha1.SkipRangeAnnotationKey]); err == nil {
+		for _, bundle := range bundles {
+			if bundle.CSV != nil && bundle != head && inRange(bundle.CSV.Spec.Version.Version) {
+				next = append(next, bundle.CSV.GetName())
+			}
+		}
+	}
+
+	var channel []*Bundle
+	visited := map[string]bool{}
+	for len(next) > 0 {
+		name := next[0]
+		next = next[1:]
+		bundle, ok := byName[name]
+		if !ok || visited[name] {
+			continue
+		}
+		visited[name] = true
+		channel = append(channel, bundle)
+		if bundle.CSV.Spec.Replaces != "" {
+			next = append(next, bundle.CSV.Spec.Replaces)
+		}
+		next = append(next, bundle.CSV.Spec.Skips...)
+	}
+	return channel
+}
@@ -2,19 +2,18 @@ package manifests
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/yaml"
-
-	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 )
 
-// bundleLoader loads a bundle directory from disk
+// packageManifestLoader loads a package manifest directory from a file system
 type packageManifestLoader struct {
+	fsys    fs.FS
 	dir     string
 	bundles []*Bundle
 	pkg     *PackageManifest
@@ -22,17 +21,27 @@ type packageManifestLoader struct {
 
 func NewPackageManifestLoader(dir string) packageManifestLoader {
 	return packageManifestLoader{
-		dir: dir,
+		fsys: DirFS(dir),
+		dir:  dir,
+	}
+}
+
+// NewPackageManifestLoaderFS returns a loader of the package manifest and bundles
+// in fsys. The paths passed to its walk funcs are slash-separated paths in fsys.
+func NewPackageManifestLoaderFS(fsys fs.FS) packageManifestLoader {
+	return packageManifestLoader{
+		fsys: fsys,
+		dir:  displayPath(fsys, "."),
 	}
 }
 
 func (p *packageManifestLoader) LoadPackage() error {
 	errs := make([]error, 0)
-	if err := filepath.Walk(p.dir, collectWalkErrs(p.LoadPackagesWalkFunc, &errs)); err != nil {
+	if err := walkFS(p.fsys, collectWalkErrs(p.LoadPackagesWalkFunc, &errs)); err != nil {
 		errs = append(errs, err)
 	}
 
-	if err := filepath.Walk(p.dir, collectWalkErrs(p.LoadBundleWalkFunc, &errs)); err != nil {
+	if err := walkFS(p.fsys, collectWalkErrs(p.LoadBundleWalkFunc, &errs)); err != nil {
 		errs = append(errs, err)
 	}
 
@@ -50,7 +59,7 @@ func (p *packageManifestLoader) LoadPackagesWalkFunc(path string, f os.FileInfo,
 	}
 
 	if f.IsDir() {
-		if strings.HasPrefix(f.Name(), ".") {
+		if strings.HasPrefix(f.Name(), ".") && path != "." {
 			return filepath.SkipDir
 		}
 		return nil
@@ -60,9 +69,9 @@ func (p *packageManifestLoader) LoadPackagesWalkFunc(path string, f os.FileInfo,
 		return nil
 	}
 
-	fileReader, err := os.Open(path)
+	fileReader, err := p.fsys.Open(path)
 	if err != nil {
-		return fmt.Errorf("unable to load package from file %s: %s", path, err)
+		return fmt.Errorf("unable to load package from file %s: %s", displayPath(p.fsys, path), err)
 	}
 	defer fileReader.Close()
 
@@ -70,7 +79,7 @@ func (p *packageManifestLoader) LoadPackagesWalkFunc(path string, f os.FileInfo,
 	manifest := PackageManifest{}
 	if err = decoder.Decode(&manifest); err != nil {
 		if err != nil {
-			return fmt.Errorf("could not decode contents of file %s into package: %s", path, err)
+			return fmt.Errorf("could not decode contents of file %s into package: %s", displayPath(p.fsys, path), err)
 		}
 	}
 
@@ -96,7 +105,7 @@ func (p *packageManifestLoader) LoadBundleWalkFunc(path string, f os.FileInfo, e
 	}
 
 	if f.IsDir() {
-		if strings.HasPrefix(f.Name(), ".") {
+		if strings.HasPrefix(f.Name(), ".") && path != "." {
 			return filepath.SkipDir
 		}
 		return nil
@@ -106,25 +115,18 @@ func (p *packageManifestLoader) LoadBundleWalkFunc(path string, f os.FileInfo, e
 		return nil
 	}
 
-	fileReader, err := os.Open(path)
+	data, err := fs.ReadFile(p.fsys, path)
 	if err != nil {
-		return fmt.Errorf("unable to load file %s: %s", path, err)
-	}
-	defer fileReader.Close()
-
-	decoder := yaml.NewYAMLOrJSONDecoder(fileReader, 30)
-	csv := unstructured.Unstructured{}
-
-	if err = decoder.Decode(&csv); err != nil {
-		return nil
+		return fmt.Errorf("unable to load file %s: %s", displayPath(p.fsys, path), err)
 	}
 
-	if csv.GetKind() != operatorsv1alpha1.ClusterServiceVersionKind {
+	csv := findCSV(data)
+	if csv == nil {
 		return nil
 	}
 
 	var errs []error
-	bundle, err := loadBundle(csv.GetName(), filepath.Dir(path))
+	bundle, err := loadBundleFS(p.fsys, csv.GetName(), dirOf(path))
 	if err != nil {
 		errs = append(errs, fmt.Errorf("error loading objs in directory: %s", err))
 	}
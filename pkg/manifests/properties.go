@@ -0,0 +1,232 @@
+package manifests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/constraints"
+)
+
+// Types of the properties and dependencies of bundles known to OLM.
+const (
+	PropertyPackage             = "olm.package"
+	PropertyGVK                 = "olm.gvk"
+	PropertyPackageRequired     = "olm.package.required"
+	PropertyGVKRequired         = "olm.gvk.required"
+	PropertyLabel               = "olm.label"
+	PropertyConstraint          = constraints.OLMConstraintType
+	PropertyMaxOpenShiftVersion = "olm.maxOpenShiftVersion"
+)
+
+// PropertiesFile holds the properties of a bundle, as declared in its
+// metadata/properties.yaml file.
+type PropertiesFile struct {
+	// Properties is a list of properties for a given bundle
+	Properties []Property `json:"properties" yaml:"properties"`
+}
+
+// Property is a typed property of a bundle.
+type Property struct {
+	// Type is the type of the property, ex. `olm.package`. This field is required.
+	Type string `json:"type" yaml:"type"`
+
+	// Value is the JSON encoded value of the property, whose schema depends on its type.
+	Value json.RawMessage `json:"value" yaml:"value"`
+}
+
+// PackageProperty is the value of an olm.package property, which declares the
+// package and version of a bundle.
+type PackageProperty struct {
+	PackageName string `json:"packageName" yaml:"packageName"`
+	Version     string `json:"version" yaml:"version"`
+}
+
+// GVKProperty is the value of an olm.gvk property, which declares an API
+// provided by a bundle, and of an olm.gvk.required property or dependency,
+// which declares an API required by a bundle.
+type GVKProperty struct {
+	Group   string `json:"group" yaml:"group"`
+	Kind    string `json:"kind" yaml:"kind"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// PackageRequiredProperty is the value of an olm.package.required property,
+// which declares a package a bundle depends on.
+type PackageRequiredProperty struct {
+	PackageName  string `json:"packageName" yaml:"packageName"`
+	VersionRange string `json:"versionRange" yaml:"versionRange"`
+}
+
+// PackageDependency is the value of an olm.package dependency of the
+// metadata/dependencies.yaml file, which declares a package a bundle depends on.
+type PackageDependency struct {
+	PackageName string `json:"packageName" yaml:"packageName"`
+	// Version is the semver range of the versions of the package required.
+	Version string `json:"version" yaml:"version"`
+}
+
+// LabelProperty is the value of an olm.label property or dependency.
+type LabelProperty struct {
+	Label string `json:"label" yaml:"label"`
+}
+
+// Parse returns the typed value of p: a PackageProperty, GVKProperty,
+// PackageRequiredProperty, LabelProperty, constraints.Constraint or, for
+// olm.maxOpenShiftVersion, the version string. The raw value is returned for
+// the properties of other types.
+func (p Property) Parse() (interface{}, error) {
+	switch p.Type {
+	case PropertyPackage:
+		v := PackageProperty{}
+		err := strictUnmarshal(p.Type, p.Value, &v)
+		return v, err
+	case PropertyGVK, PropertyGVKRequired:
+		v := GVKProperty{}
+		err := strictUnmarshal(p.Type, p.Value, &v)
+		return v, err
+	case PropertyPackageRequired:
+		v := PackageRequiredProperty{}
+		err := strictUnmarshal(p.Type, p.Value, &v)
+		return v, err
+	case PropertyLabel:
+		v := LabelProperty{}
+		err := strictUnmarshal(p.Type, p.Value, &v)
+		return v, err
+	case PropertyConstraint:
+		return constraints.Parse(p.Value)
+	case PropertyMaxOpenShiftVersion:
+		return parseMaxOpenShiftVersion(p.Value)
+	}
+	return p.Value, nil
+}
+
+// Parse returns the typed value of d: a PackageDependency, GVKProperty,
+// LabelProperty or constraints.Constraint. The raw value is returned for the
+// dependencies of other types.
+func (d Dependency) Parse() (interface{}, error) {
+	switch d.Type {
+	case PropertyPackage:
+		v := PackageDependency{}
+		err := strictUnmarshal(d.Type, []byte(d.Value), &v)
+		return v, err
+	case PropertyGVK:
+		return Property{Type: PropertyGVKRequired, Value: json.RawMessage(d.Value)}.Parse()
+	case PropertyLabel, PropertyConstraint:
+		return Property{Type: d.Type, Value: json.RawMessage(d.Value)}.Parse()
+	}
+	return json.RawMessage(d.Value), nil
+}
+
+// Candidate returns b as a candidate for constraints.Evaluate. As OLM does, the
+// olm.package property of its package and CSV version and the olm.gvk properties
+// of the APIs owned by its CSV are added to the properties declared by b, unless
+// b declares properties of those types.
+func (b *Bundle) Candidate() constraints.Candidate {
+	candidate := constraints.Candidate{Name: b.Name}
+	declared := map[string]bool{}
+	for _, p := range b.Properties {
+		candidate.Properties = append(candidate.Properties, constraints.Property{Type: p.Type, Value: p.Value})
+		declared[p.Type] = true
+	}
+	add := func(typ string, value interface{}) {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return
+		}
+		candidate.Properties = append(candidate.Properties, constraints.Property{Type: typ, Value: data})
+	}
+	if b.CSV == nil {
+		return candidate
+	}
+	if b.Name == "" {
+		candidate.Name = b.CSV.GetName()
+	}
+	if !declared[PropertyPackage] && b.Package != "" {
+		add(PropertyPackage, PackageProperty{PackageName: b.Package, Version: b.CSV.Spec.Version.String()})
+	}
+	if !declared[PropertyGVK] {
+		for _, crd := range b.CSV.Spec.CustomResourceDefinitions.Owned {
+			group := crd.Name
+			if i := strings.Index(crd.Name, "."); i >= 0 {
+				group = crd.Name[i+1:]
+			}
+			add(PropertyGVK, GVKProperty{Group: group, Kind: crd.Kind, Version: crd.Version})
+		}
+		for _, api := range b.CSV.Spec.APIServiceDefinitions.Owned {
+			add(PropertyGVK, GVKProperty{Group: api.Group, Kind: api.Kind, Version: api.Version})
+		}
+	}
+	return candidate
+}
+
+// Candidate returns b as a candidate for constraints.Evaluate.
+func (b CatalogBundle) Candidate() constraints.Candidate {
+	candidate := constraints.Candidate{Name: b.Name}
+	for _, p := range b.Properties {
+		candidate.Properties = append(candidate.Properties, constraints.Property{Type: p.Type, Value: p.Value})
+	}
+	return candidate
+}
+
+// parseMaxOpenShiftVersion returns the version of an olm.maxOpenShiftVersion
+// value, which may be a JSON string or number, ex. "4.8" or 4.8.
+func parseMaxOpenShiftVersion(value json.RawMessage) (string, error) {
+	var version interface{}
+	if err := json.Unmarshal(value, &version); err != nil {
+		return "", fmt.Errorf("invalid %s value: %v", PropertyMaxOpenShiftVersion, err)
+	}
+	switch v := version.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strings.TrimSpace(string(value)), nil
+	}
+	return "", fmt.Errorf("invalid %s value: %s is not a version", PropertyMaxOpenShiftVersion, value)
+}
+
+// strictUnmarshal decodes the value data of a property of type typ into v,
+// rejecting unknown fields.
+func strictUnmarshal(typ string, data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid %s value: %v", typ, err)
+	}
+	return nil
+}
+
+// dependencyFile holds the dependencies of a metadata/dependencies.yaml file,
+// whose values are objects rather than the strings of Dependency.
+type dependencyFile struct {
+	Dependencies []Property `json:"dependencies"`
+}
+
+// dependencies returns the dependencies of f with their JSON encoded values.
+func (f dependencyFile) dependencies() []*Dependency {
+	var deps []*Dependency
+	for _, d := range f.Dependencies {
+		deps = append(deps, &Dependency{Type: d.Type, Value: string(unescapeHTML(d.Value))})
+	}
+	return deps
+}
+
+// unescapeHTML returns the JSON value with the characters escaped for HTML,
+// such as the ">" of version ranges, which converting YAML to JSON escapes,
+// written as is.
+func unescapeHTML(value json.RawMessage) json.RawMessage {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(value))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return value
+	}
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return value
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
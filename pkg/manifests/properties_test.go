@@ -0,0 +1,179 @@
+package manifests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/api/pkg/constraints"
+	"github.com/operator-framework/api/pkg/lib/version"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+func TestPropertyParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		property Property
+		want     interface{}
+		errStr   string
+	}{
+		{
+			name:     "package",
+			property: Property{Type: PropertyPackage, Value: json.RawMessage(`{"packageName":"etcd","version":"0.9.4"}`)},
+			want:     PackageProperty{PackageName: "etcd", Version: "0.9.4"},
+		},
+		{
+			name:     "gvk",
+			property: Property{Type: PropertyGVK, Value: json.RawMessage(`{"group":"etcd.database.coreos.com","kind":"EtcdCluster","version":"v1beta2"}`)},
+			want:     GVKProperty{Group: "etcd.database.coreos.com", Kind: "EtcdCluster", Version: "v1beta2"},
+		},
+		{
+			name:     "package required",
+			property: Property{Type: PropertyPackageRequired, Value: json.RawMessage(`{"packageName":"etcd","versionRange":">=0.9.0"}`)},
+			want:     PackageRequiredProperty{PackageName: "etcd", VersionRange: ">=0.9.0"},
+		},
+		{
+			name:     "label",
+			property: Property{Type: PropertyLabel, Value: json.RawMessage(`{"label":"beta"}`)},
+			want:     LabelProperty{Label: "beta"},
+		},
+		{
+			name:     "constraint",
+			property: Property{Type: PropertyConstraint, Value: json.RawMessage(`{"failureMessage":"requires etcd","package":{"packageName":"etcd","versionRange":">=0.9.0"}}`)},
+			want: constraints.Constraint{
+				FailureMessage: "requires etcd",
+				Package:        &constraints.PackageConstraint{PackageName: "etcd", VersionRange: ">=0.9.0"},
+			},
+		},
+		{
+			name:     "max OpenShift version string",
+			property: Property{Type: PropertyMaxOpenShiftVersion, Value: json.RawMessage(`"4.8"`)},
+			want:     "4.8",
+		},
+		{
+			name:     "max OpenShift version number",
+			property: Property{Type: PropertyMaxOpenShiftVersion, Value: json.RawMessage(`4.8`)},
+			want:     "4.8",
+		},
+		{
+			name:     "unknown type",
+			property: Property{Type: "example.com/custom", Value: json.RawMessage(`{"any":"value"}`)},
+			want:     json.RawMessage(`{"any":"value"}`),
+		},
+		{
+			name:     "unknown field",
+			property: Property{Type: PropertyPackage, Value: json.RawMessage(`{"packageName":"etcd","versionRange":"0.9.4"}`)},
+			errStr:   `invalid olm.package value: json: unknown field "versionRange"`,
+		},
+		{
+			name:     "invalid max OpenShift version",
+			property: Property{Type: PropertyMaxOpenShiftVersion, Value: json.RawMessage(`["4.8"]`)},
+			errStr:   `invalid olm.maxOpenShiftVersion value: ["4.8"] is not a version`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.property.Parse()
+			if tt.errStr != "" {
+				require.EqualError(t, err, tt.errStr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDependencyParse(t *testing.T) {
+	got, err := Dependency{Type: PropertyPackage, Value: `{"packageName":"etcd","version":">0.9.0"}`}.Parse()
+	require.NoError(t, err)
+	require.Equal(t, PackageDependency{PackageName: "etcd", Version: ">0.9.0"}, got)
+
+	got, err = Dependency{Type: PropertyGVK, Value: `{"group":"etcd.database.coreos.com","kind":"EtcdCluster","version":"v1beta2"}`}.Parse()
+	require.NoError(t, err)
+	require.Equal(t, GVKProperty{Group: "etcd.database.coreos.com", Kind: "EtcdCluster", Version: "v1beta2"}, got)
+}
+
+func TestLoadBundleMetadataFiles(t *testing.T) {
+	files := map[string][]byte{
+		"metadata/annotations.yaml": []byte(`annotations:
+  operators.operatorframework.io.bundle.package.v1: etcd
+  operators.operatorframework.io.bundle.channels.v1: alpha
+`),
+		"metadata/properties.yaml": []byte(`properties:
+- type: olm.maxOpenShiftVersion
+  value: "4.8"
+- type: olm.constraint
+  value:
+    failureMessage: requires a cert-manager
+    gvk:
+      group: cert-manager.io
+      kind: Certificate
+      version: v1
+`),
+		"metadata/dependencies.yaml": []byte(`dependencies:
+- type: olm.package
+  value:
+    packageName: prometheus
+    version: ">0.27.0"
+`),
+	}
+	entries, err := os.ReadDir("./testdata/valid_bundle")
+	require.NoError(t, err)
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join("testdata", "valid_bundle", entry.Name()))
+		require.NoError(t, err)
+		files["manifests/"+entry.Name()] = data
+	}
+	fsys, err := MapFS(files)
+	require.NoError(t, err)
+
+	bundle, err := GetBundleFromFS(fsys)
+	require.NoError(t, err)
+	require.Equal(t, "etcd", bundle.Package)
+	require.Equal(t, []Property{
+		{Type: PropertyMaxOpenShiftVersion, Value: json.RawMessage(`"4.8"`)},
+		{Type: PropertyConstraint, Value: json.RawMessage(`{"failureMessage":"requires a cert-manager","gvk":{"group":"cert-manager.io","kind":"Certificate","version":"v1"}}`)},
+	}, bundle.Properties)
+	require.Equal(t, []*Dependency{
+		{Type: PropertyPackage, Value: `{"packageName":"prometheus","version":">0.27.0"}`},
+	}, bundle.Dependencies)
+
+	files["metadata/properties.yaml"] = []byte("properties: {}")
+	fsys, err = MapFS(files)
+	require.NoError(t, err)
+	_, err = GetBundleFromFS(fsys)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unable to load the metadata file metadata/properties.yaml")
+}
+
+func TestBundleCandidate(t *testing.T) {
+	csv := &operatorsv1alpha1.ClusterServiceVersion{}
+	csv.SetName("etcdoperator.v0.9.4")
+	csv.Spec.Version = version.OperatorVersion{Version: semver.MustParse("0.9.4")}
+	csv.Spec.CustomResourceDefinitions.Owned = []operatorsv1alpha1.CRDDescription{
+		{Name: "etcdclusters.etcd.database.coreos.com", Kind: "EtcdCluster", Version: "v1beta2"},
+	}
+	bundle := &Bundle{Package: "etcd", CSV: csv, Properties: []Property{
+		{Type: PropertyLabel, Value: json.RawMessage(`{"label":"stable"}`)},
+	}}
+	require.Equal(t, constraints.Candidate{Name: "etcdoperator.v0.9.4", Properties: []constraints.Property{
+		{Type: PropertyLabel, Value: json.RawMessage(`{"label":"stable"}`)},
+		{Type: PropertyPackage, Value: json.RawMessage(`{"packageName":"etcd","version":"0.9.4"}`)},
+		{Type: PropertyGVK, Value: json.RawMessage(`{"group":"etcd.database.coreos.com","kind":"EtcdCluster","version":"v1beta2"}`)},
+	}}, bundle.Candidate())
+
+	// Declared properties are not derived from the CSV.
+	bundle.Properties = []Property{{Type: PropertyPackage, Value: json.RawMessage(`{"packageName":"etcd","version":"0.9.5"}`)}}
+	bundle.Name = "etcd.v0.9.5"
+	results, err := constraints.Evaluate(constraints.Constraint{All: &constraints.CompoundConstraint{Constraints: []constraints.Constraint{
+		{Package: &constraints.PackageConstraint{PackageName: "etcd", VersionRange: ">0.9.4"}},
+		{GVK: &constraints.GVKConstraint{Group: "etcd.database.coreos.com", Kind: "EtcdCluster", Version: "v1beta2"}},
+	}}}, []constraints.Candidate{bundle.Candidate()})
+	require.NoError(t, err)
+	require.Equal(t, []string{"etcd.v0.9.5"}, constraints.Satisfying(results))
+}
@@ -0,0 +1,189 @@
+package manifests
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Position is the position of a value in a manifest file.
+type Position struct {
+	// File is the path of the manifest file.
+	File string
+	// Line and Column are 1-based.
+	Line   int
+	Column int
+}
+
+// SourceMap records the manifest file each object of a bundle was loaded
+// from, and resolves the fields of those objects to their position in the file.
+type SourceMap struct {
+	objects []objectSource
+}
+
+// objectSource is an object of a manifest file.
+type objectSource struct {
+	kind, name, file string
+	// node is the root mapping of the object.
+	node *yaml.Node
+}
+
+// addFile records the objects of the manifest file at path, whose contents are
+// data. The source map is best effort: documents which cannot be parsed are
+// ignored, since reporting those is the job of the loader.
+func (m *SourceMap) addFile(path string, data []byte) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		doc := yaml.Node{}
+		if err := dec.Decode(&doc); err != nil {
+			// io.EOF, or a document which is not valid YAML.
+			return
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		obj := struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name string `yaml:"name"`
+			} `yaml:"metadata"`
+		}{}
+		if err := doc.Content[0].Decode(&obj); err != nil || obj.Metadata.Name == "" {
+			continue
+		}
+		m.objects = append(m.objects, objectSource{kind: obj.Kind, name: obj.Metadata.Name, file: path, node: doc.Content[0]})
+	}
+}
+
+// Lookup returns the position of the field at path of the object of the given
+// kind and name, and whether the object is known. An empty kind matches objects
+// of any kind.
+//
+// The path is either a JSON pointer, ex. "/spec/install/spec/deployments/0",
+// or a dotted path as used by the Field of validation errors, ex.
+// "spec.versions[0].name" or "Spec.InstallModes", whose keys are matched
+// case-insensitively. An empty path is the position of the object itself. When
+// the field is missing, the position of its closest existing parent is returned.
+func (m *SourceMap) Lookup(kind, name, path string) (Position, bool) {
+	if m == nil {
+		return Position{}, false
+	}
+	for _, obj := range m.objects {
+		if obj.name != name || (kind != "" && obj.kind != kind) {
+			continue
+		}
+		line, column := resolve(obj.node, pathTokens(path), strings.HasPrefix(path, "/"))
+		return Position{File: obj.file, Line: line, Column: column}, true
+	}
+	return Position{}, false
+}
+
+// objectPosition returns the position of the n-th object, counting from 0, of
+// the given kind and name in the manifest file at path, and whether it is found.
+func (m *SourceMap) objectPosition(path, kind, name string, n int) (Position, bool) {
+	if m == nil {
+		return Position{}, false
+	}
+	for _, obj := range m.objects {
+		if obj.file != path || obj.kind != kind || obj.name != name {
+			continue
+		}
+		if n == 0 {
+			return Position{File: obj.file, Line: obj.node.Line, Column: obj.node.Column}, true
+		}
+		n--
+	}
+	return Position{}, false
+}
+
+// pathTokens splits a JSON pointer or dotted path into its tokens.
+func pathTokens(path string) []string {
+	if path == "" {
+		return nil
+	}
+	if strings.HasPrefix(path, "/") {
+		tokens := strings.Split(path[1:], "/")
+		for i, token := range tokens {
+			tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		}
+		return tokens
+	}
+	var tokens []string
+	for _, token := range strings.Split(path, ".") {
+		// Split indexes, ex. "versions[0]", into their own tokens.
+		for token != "" {
+			i := strings.IndexByte(token, '[')
+			if i < 0 {
+				tokens = append(tokens, token)
+				break
+			}
+			if i > 0 {
+				tokens = append(tokens, token[:i])
+			}
+			j := strings.IndexByte(token[i:], ']')
+			if j < 0 {
+				tokens = append(tokens, token[i:])
+				break
+			}
+			tokens = append(tokens, token[i+1:i+j])
+			token = token[i+j+1:]
+		}
+	}
+	return tokens
+}
+
+// resolve returns the line and column of the value at tokens under node, or of
+// its closest existing parent. Mapping values are positioned at their key.
+// Keys are matched exactly if exact, else case-insensitively and, since
+// keys of dotted paths may contain dots, possibly across several tokens.
+func resolve(node *yaml.Node, tokens []string, exact bool) (int, int) {
+	line, column := node.Line, node.Column
+	for len(tokens) > 0 {
+		for node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+		switch node.Kind {
+		case yaml.MappingNode:
+			key, value, n := mappingValue(node, tokens, exact)
+			if key == nil {
+				return line, column
+			}
+			line, column = key.Line, key.Column
+			node, tokens = value, tokens[n:]
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(tokens[0])
+			if err != nil || i < 0 || i >= len(node.Content) {
+				return line, column
+			}
+			node, tokens = node.Content[i], tokens[1:]
+			line, column = node.Line, node.Column
+		default:
+			return line, column
+		}
+	}
+	return line, column
+}
+
+// mappingValue returns the key and value of the mapping node matched by the
+// first tokens, along with the number of tokens matched.
+func mappingValue(node *yaml.Node, tokens []string, exact bool) (*yaml.Node, *yaml.Node, int) {
+	if exact {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == tokens[0] {
+				return node.Content[i], node.Content[i+1], 1
+			}
+		}
+		return nil, nil, 0
+	}
+	for n := 1; n <= len(tokens); n++ {
+		key := strings.Join(tokens[:n], ".")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if strings.EqualFold(node.Content[i].Value, key) {
+				return node.Content[i], node.Content[i+1], n
+			}
+		}
+	}
+	return nil, nil, 0
+}
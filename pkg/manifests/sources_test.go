@@ -0,0 +1,89 @@
+package manifests
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceMapLookup(t *testing.T) {
+	bundle, err := GetBundleFromDir("./testdata/valid_bundle")
+	require.NoError(t, err)
+	csvFile := filepath.Join("testdata", "valid_bundle", "etcdoperator.v0.9.4.clusterserviceversion.yaml")
+	crdFile := filepath.Join("testdata", "valid_bundle", "etcdclusters.etcd.database.coreos.com.crd.yaml")
+
+	tests := []struct {
+		name      string
+		kind      string
+		object    string
+		path      string
+		want      Position
+		wantFound bool
+	}{
+		{
+			name:      "object",
+			kind:      "ClusterServiceVersion",
+			object:    "etcdoperator.v0.9.4",
+			want:      Position{File: csvFile, Line: 1, Column: 1},
+			wantFound: true,
+		},
+		{
+			name:      "JSON pointer",
+			kind:      "ClusterServiceVersion",
+			object:    "etcdoperator.v0.9.4",
+			path:      "/metadata/annotations/capabilities",
+			want:      Position{File: csvFile, Line: 18, Column: 5},
+			wantFound: true,
+		},
+		{
+			name:      "dotted path with indexes",
+			object:    "etcdoperator.v0.9.4",
+			path:      "spec.customresourcedefinitions.owned[0].resources[1].version",
+			want:      Position{File: csvFile, Line: 38, Column: 9},
+			wantFound: true,
+		},
+		{
+			name:      "Go struct field path",
+			object:    "etcdoperator.v0.9.4",
+			path:      "Spec.InstallModes",
+			want:      Position{File: csvFile, Line: 273, Column: 3},
+			wantFound: true,
+		},
+		{
+			name:      "missing field resolves to its parent",
+			object:    "etcdoperator.v0.9.4",
+			path:      "spec.minKubeVersion",
+			want:      Position{File: csvFile, Line: 27, Column: 1},
+			wantFound: true,
+		},
+		{
+			name:      "other object",
+			kind:      "CustomResourceDefinition",
+			object:    "etcdclusters.etcd.database.coreos.com",
+			path:      "spec.scope",
+			want:      Position{File: crdFile, Line: 15, Column: 3},
+			wantFound: true,
+		},
+		{
+			name:   "unknown kind",
+			kind:   "Deployment",
+			object: "etcdoperator.v0.9.4",
+		},
+		{
+			name:   "unknown object",
+			object: "missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos, found := bundle.Sources.Lookup(tt.kind, tt.object, tt.path)
+			require.Equal(t, tt.wantFound, found)
+			require.Equal(t, tt.want, pos)
+		})
+	}
+
+	var nilMap *SourceMap
+	_, found := nilMap.Lookup("", "etcdoperator.v0.9.4", "")
+	require.False(t, found)
+}
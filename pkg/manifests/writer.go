@@ -0,0 +1,316 @@
+package manifests
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+// Labels of registry+v1 bundle images, also written to the metadata/annotations.yaml
+// file of bundle directories.
+const (
+	MediatypeLabel      = "operators.operatorframework.io.bundle.mediatype.v1"
+	ManifestsLabel      = "operators.operatorframework.io.bundle.manifests.v1"
+	MetadataLabel       = "operators.operatorframework.io.bundle.metadata.v1"
+	PackageLabel        = "operators.operatorframework.io.bundle.package.v1"
+	ChannelsLabel       = "operators.operatorframework.io.bundle.channels.v1"
+	DefaultChannelLabel = "operators.operatorframework.io.bundle.channel.default.v1"
+
+	// RegistryV1Mediatype is the mediatype of registry+v1 bundles.
+	RegistryV1Mediatype = "registry+v1"
+	// ManifestsDir and MetadataDir are the directories of the manifests and
+	// metadata of registry+v1 bundles.
+	ManifestsDir = "manifests"
+	MetadataDir  = "metadata"
+)
+
+// BundleLabels returns the labels of the bundle image of bundle, which are also
+// the annotations of its metadata/annotations.yaml file. The package, channels
+// and default channel labels are omitted if bundle has none.
+func BundleLabels(bundle *Bundle) map[string]string {
+	labels := map[string]string{
+		MediatypeLabel: RegistryV1Mediatype,
+		ManifestsLabel: ManifestsDir + "/",
+		MetadataLabel:  MetadataDir + "/",
+	}
+	if bundle.Package != "" {
+		labels[PackageLabel] = bundle.Package
+	}
+	if len(bundle.Channels) > 0 {
+		labels[ChannelsLabel] = strings.Join(bundle.Channels, ",")
+	}
+	if bundle.DefaultChannel != "" {
+		labels[DefaultChannelLabel] = bundle.DefaultChannel
+	}
+	return labels
+}
+
+// BundleDockerfile returns the Dockerfile building the bundle image of bundle
+// from the bundle directory at dir, relative to the build context.
+func BundleDockerfile(bundle *Bundle, dir string) []byte {
+	labels := BundleLabels(bundle)
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	b := &strings.Builder{}
+	b.WriteString("FROM scratch\n\n# Core bundle labels.\n")
+	for _, key := range keys {
+		fmt.Fprintf(b, "LABEL %s=%s\n", key, dockerfileQuote(labels[key]))
+	}
+	b.WriteString("\n# Copy files to locations specified by labels.\n")
+	for _, d := range []string{ManifestsDir, MetadataDir} {
+		fmt.Fprintf(b, "COPY %s /%s/\n", path.Join(filepath.ToSlash(dir), d), d)
+	}
+	return []byte(b.String())
+}
+
+// dockerfileQuote returns s as a double-quoted Dockerfile word, escaping the
+// characters which are special in one.
+func dockerfileQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`).Replace(s) + `"`
+}
+
+// BundleFiles returns the files of bundle in the registry+v1 format, keyed by
+// their slash-separated path: the manifests of its objects under manifests/,
+// and its metadata/annotations.yaml and, if the bundle has any properties or
+// dependencies, metadata/properties.yaml and metadata/dependencies.yaml files.
+//
+// Manifests are named after their object, ex. "etcdoperator.v0.9.4.clusterserviceversion.yaml"
+// for CSVs, "etcd.database.coreos.com_etcdclusters.yaml" for CRDs and
+// "etcd-operator_v1_serviceaccount.yaml" for other objects, and their fields are
+// sorted by name. The CSV and CRDs are written from bundle.CSV, bundle.V1CRDs and
+// bundle.V1beta1CRDs, so that changes to those are written, and the other
+// objects from bundle.Objects.
+func BundleFiles(bundle *Bundle) (map[string][]byte, error) {
+	objects, err := bundleObjects(bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	for _, obj := range objects {
+		name := path.Join(ManifestsDir, manifestFileName(obj))
+		if _, ok := files[name]; ok {
+			return nil, fmt.Errorf("objects %s %s and another object would both be written to %s", obj.GetKind(), obj.GetName(), name)
+		}
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, fmt.Errorf("unable to write %s %s: %v", obj.GetKind(), obj.GetName(), err)
+		}
+		files[name] = data
+	}
+
+	annotations, err := yaml.Marshal(map[string]interface{}{"annotations": BundleLabels(bundle)})
+	if err != nil {
+		return nil, err
+	}
+	files[path.Join(MetadataDir, "annotations.yaml")] = annotations
+
+	if len(bundle.Properties) > 0 {
+		data, err := marshalYAML(PropertiesFile{Properties: bundle.Properties})
+		if err != nil {
+			return nil, fmt.Errorf("unable to write properties: %v", err)
+		}
+		files[path.Join(MetadataDir, "properties.yaml")] = data
+	}
+	if len(bundle.Dependencies) > 0 {
+		deps := dependencyFile{}
+		for _, d := range bundle.Dependencies {
+			value := json.RawMessage(d.Value)
+			if !json.Valid(value) {
+				// Dependencies built by hand may hold a plain string.
+				if value, err = json.Marshal(d.Value); err != nil {
+					return nil, err
+				}
+			}
+			deps.Dependencies = append(deps.Dependencies, Property{Type: d.Type, Value: value})
+		}
+		data, err := marshalYAML(deps)
+		if err != nil {
+			return nil, fmt.Errorf("unable to write dependencies: %v", err)
+		}
+		files[path.Join(MetadataDir, "dependencies.yaml")] = data
+	}
+	return files, nil
+}
+
+// WriteBundle writes the files of bundle, as returned by BundleFiles, to the
+// directory dir, which is created if needed. Existing files are overwritten,
+// but other files of dir are left as is.
+func WriteBundle(dir string, bundle *Bundle) error {
+	files, err := BundleFiles(bundle)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(file, files[name], 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typedManifest is a typed object of a bundle, along with its GroupVersionKind
+// for the objects built without their TypeMeta.
+type typedManifest struct {
+	obj runtime.Object
+	gvk schema.GroupVersionKind
+}
+
+// bundleObjects returns the objects of bundle to write, with its CSV and CRDs
+// taken from their typed fields.
+func bundleObjects(bundle *Bundle) ([]*unstructured.Unstructured, error) {
+	var typed []typedManifest
+	if bundle.CSV != nil {
+		typed = append(typed, typedManifest{bundle.CSV, operatorsv1alpha1.SchemeGroupVersion.WithKind(operatorsv1alpha1.ClusterServiceVersionKind)})
+	}
+	for _, crd := range bundle.V1CRDs {
+		typed = append(typed, typedManifest{crd, apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition")})
+	}
+	for _, crd := range bundle.V1beta1CRDs {
+		typed = append(typed, typedManifest{crd, apiextensionsv1beta1.SchemeGroupVersion.WithKind("CustomResourceDefinition")})
+	}
+
+	var objects []*unstructured.Unstructured
+	for _, obj := range bundle.Objects {
+		if !isTypedKind(obj) {
+			objects = append(objects, obj)
+		}
+	}
+	for _, t := range typed {
+		obj, err := typedObject(bundle, t)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// isTypedKind returns whether obj is loaded into the typed fields of bundles.
+func isTypedKind(obj *unstructured.Unstructured) bool {
+	switch obj.GetKind() {
+	case operatorsv1alpha1.ClusterServiceVersionKind:
+		return true
+	case "CustomResourceDefinition":
+		version := obj.GetAPIVersion()
+		return version == apiextensionsv1.SchemeGroupVersion.String() || version == apiextensionsv1beta1.SchemeGroupVersion.String()
+	}
+	return false
+}
+
+// typedObject returns the object of the typed CSV or CRD t. The object of
+// bundle.Objects t was loaded from is returned if t was not changed, which
+// keeps the fields unknown to its type.
+func typedObject(bundle *Bundle, t typedManifest) (*unstructured.Unstructured, error) {
+	typed := t.obj
+	if typed.GetObjectKind().GroupVersionKind().Empty() {
+		typed = typed.DeepCopyObject()
+		typed.GetObjectKind().SetGroupVersionKind(t.gvk)
+	}
+	data, err := json.Marshal(typed)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+
+	for _, loaded := range bundle.Objects {
+		if loaded.GetKind() != obj.GetKind() || loaded.GetName() != obj.GetName() || loaded.GetAPIVersion() != obj.GetAPIVersion() {
+			continue
+		}
+		data, err := loaded.MarshalJSON()
+		if err != nil {
+			break
+		}
+		original := reflect.New(reflect.TypeOf(t.obj).Elem()).Interface()
+		if err := json.Unmarshal(data, original); err == nil && reflect.DeepEqual(original, t.obj) {
+			return loaded, nil
+		}
+		break
+	}
+
+	// Drop the empty fields of the typed object which are not part of manifests.
+	unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
+	if status, ok := obj.Object["status"].(map[string]interface{}); ok && isEmptyStatus(status) {
+		delete(obj.Object, "status")
+	}
+	return obj, nil
+}
+
+// isEmptyStatus returns whether the status of a typed object holds no value.
+func isEmptyStatus(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		for _, value := range v {
+			if !isEmptyStatus(value) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		return len(v) == 0
+	case string:
+		return v == ""
+	}
+	return false
+}
+
+// manifestFileName returns the name of the manifest file of obj.
+func manifestFileName(obj *unstructured.Unstructured) string {
+	gvk := obj.GroupVersionKind()
+	switch {
+	case gvk.Kind == operatorsv1alpha1.ClusterServiceVersionKind:
+		return fmt.Sprintf("%s.clusterserviceversion.yaml", obj.GetName())
+	case isTypedKind(obj):
+		// CRDs are named <plural>.<group>.
+		if i := strings.Index(obj.GetName(), "."); i > 0 {
+			return fmt.Sprintf("%s_%s.yaml", obj.GetName()[i+1:], obj.GetName()[:i])
+		}
+	}
+	parts := []string{obj.GetName()}
+	if gvk.Group != "" {
+		parts = append(parts, gvk.Group)
+	}
+	parts = append(parts, gvk.Version, gvk.Kind)
+	return strings.ToLower(strings.Join(parts, "_")) + ".yaml"
+}
+
+// marshalYAML returns the YAML of v, whose fields are sorted by name.
+func marshalYAML(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.JSONToYAML(data)
+}
@@ -0,0 +1,189 @@
+package manifests
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+)
+
+func TestWriteBundleRoundTrip(t *testing.T) {
+	bundle, err := GetBundleFromDir("./testdata/valid_bundle")
+	require.NoError(t, err)
+	bundle.Package = "etcd"
+	bundle.Channels = []string{"alpha", "stable"}
+	bundle.DefaultChannel = "stable"
+	bundle.Properties = []Property{
+		{Type: PropertyMaxOpenShiftVersion, Value: json.RawMessage(`"4.8"`)},
+		{Type: PropertyPackageRequired, Value: json.RawMessage(`{"packageName":"prometheus","versionRange":">=0.27.0"}`)},
+	}
+	bundle.Dependencies = []*Dependency{
+		{Type: PropertyPackage, Value: `{"packageName":"prometheus","version":">0.27.0"}`},
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, WriteBundle(dir, bundle))
+
+	var names []string
+	require.NoError(t, filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		require.NoError(t, err)
+		if !info.IsDir() {
+			rel, err := filepath.Rel(dir, path)
+			require.NoError(t, err)
+			names = append(names, filepath.ToSlash(rel))
+		}
+		return nil
+	}))
+	require.Equal(t, []string{
+		"manifests/etcd.database.coreos.com_etcdbackups.yaml",
+		"manifests/etcd.database.coreos.com_etcdclusters.yaml",
+		"manifests/etcd.database.coreos.com_etcdrestores.yaml",
+		"manifests/etcdoperator.v0.9.4.clusterserviceversion.yaml",
+		"metadata/annotations.yaml",
+		"metadata/dependencies.yaml",
+		"metadata/properties.yaml",
+	}, names)
+
+	annotations, err := os.ReadFile(filepath.Join(dir, "metadata", "annotations.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, `annotations:
+  operators.operatorframework.io.bundle.channel.default.v1: stable
+  operators.operatorframework.io.bundle.channels.v1: alpha,stable
+  operators.operatorframework.io.bundle.manifests.v1: manifests/
+  operators.operatorframework.io.bundle.mediatype.v1: registry+v1
+  operators.operatorframework.io.bundle.metadata.v1: metadata/
+  operators.operatorframework.io.bundle.package.v1: etcd
+`, string(annotations))
+
+	written, err := GetBundleFromDir(dir)
+	require.NoError(t, err)
+	require.Equal(t, bundle.Name, written.Name)
+	require.Equal(t, bundle.Package, written.Package)
+	require.Equal(t, bundle.Channels, written.Channels)
+	require.Equal(t, bundle.DefaultChannel, written.DefaultChannel)
+	require.Equal(t, bundle.CSV, written.CSV)
+	require.ElementsMatch(t, bundle.V1beta1CRDs, written.V1beta1CRDs)
+	require.ElementsMatch(t, bundle.Objects, written.Objects)
+	require.Equal(t, bundle.Properties, written.Properties)
+	require.Equal(t, bundle.Dependencies, written.Dependencies)
+
+	// Writing the loaded bundle again produces the same files.
+	files, err := BundleFiles(bundle)
+	require.NoError(t, err)
+	rewritten, err := BundleFiles(written)
+	require.NoError(t, err)
+	require.Equal(t, files, rewritten)
+}
+
+func TestWriteBundleTypedChanges(t *testing.T) {
+	bundle, err := GetBundleFromDir("./testdata/valid_bundle")
+	require.NoError(t, err)
+	bundle.CSV.Spec.Description = "A changed description"
+
+	// Objects built in code may not set their TypeMeta.
+	csv := &operatorsv1alpha1.ClusterServiceVersion{}
+	csv.SetName("etcdoperator.v0.9.5")
+	csv.Spec.DisplayName = "etcd"
+	built := &Bundle{CSV: csv, Package: "etcd", Channels: []string{"alpha"}}
+
+	files, err := BundleFiles(bundle)
+	require.NoError(t, err)
+	fsys, err := MapFS(files)
+	require.NoError(t, err)
+	written, err := GetBundleFromFS(fsys)
+	require.NoError(t, err)
+	require.Equal(t, "A changed description", written.CSV.Spec.Description)
+
+	files, err = BundleFiles(built)
+	require.NoError(t, err)
+	require.Contains(t, files, "manifests/etcdoperator.v0.9.5.clusterserviceversion.yaml")
+	require.NotContains(t, string(files["manifests/etcdoperator.v0.9.5.clusterserviceversion.yaml"]), "creationTimestamp")
+	require.NotContains(t, string(files["manifests/etcdoperator.v0.9.5.clusterserviceversion.yaml"]), "status")
+	fsys, err = MapFS(files)
+	require.NoError(t, err)
+	written, err = GetBundleFromFS(fsys)
+	require.NoError(t, err)
+	require.Equal(t, "etcdoperator.v0.9.5", written.Name)
+	require.Equal(t, operatorsv1alpha1.ClusterServiceVersionKind, written.CSV.Kind)
+}
+
+func TestManifestFileName(t *testing.T) {
+	bundle, err := GetBundleFromFS(mustMapFS(t, map[string][]byte{
+		"manifests/csv.yaml": mustReadFile(t, "./testdata/valid_bundle/etcdoperator.v0.9.4.clusterserviceversion.yaml"),
+		"manifests/sa.yaml":  []byte("apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: etcd-operator\n"),
+		"manifests/role.yaml": []byte("apiVersion: rbac.authorization.k8s.io/v1\nkind: ClusterRole\nmetadata:\n" +
+			"  name: etcd-metrics-reader\n"),
+	}))
+	require.NoError(t, err)
+	files, err := BundleFiles(bundle)
+	require.NoError(t, err)
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	require.Equal(t, []string{
+		"manifests/etcd-metrics-reader_rbac.authorization.k8s.io_v1_clusterrole.yaml",
+		"manifests/etcd-operator_v1_serviceaccount.yaml",
+		"manifests/etcdoperator.v0.9.4.clusterserviceversion.yaml",
+		"metadata/annotations.yaml",
+	}, names)
+}
+
+func TestBundleDockerfile(t *testing.T) {
+	bundle := &Bundle{Package: "etcd", Channels: []string{"alpha", "stable"}, DefaultChannel: "stable"}
+	require.Equal(t, `FROM scratch
+
+# Core bundle labels.
+LABEL operators.operatorframework.io.bundle.channel.default.v1="stable"
+LABEL operators.operatorframework.io.bundle.channels.v1="alpha,stable"
+LABEL operators.operatorframework.io.bundle.manifests.v1="manifests/"
+LABEL operators.operatorframework.io.bundle.mediatype.v1="registry+v1"
+LABEL operators.operatorframework.io.bundle.metadata.v1="metadata/"
+LABEL operators.operatorframework.io.bundle.package.v1="etcd"
+
+# Copy files to locations specified by labels.
+COPY bundle/manifests /manifests/
+COPY bundle/metadata /metadata/
+`, string(BundleDockerfile(bundle, "bundle")))
+
+	// Values are escaped, and empty labels omitted.
+	bundle = &Bundle{Package: `my "etcd" $HOME\`}
+	dockerfile := string(BundleDockerfile(bundle, "bundle"))
+	require.Contains(t, dockerfile, `LABEL operators.operatorframework.io.bundle.package.v1="my \"etcd\" \$HOME\\"`+"\n")
+	require.NotContains(t, dockerfile, ChannelsLabel)
+	require.NotContains(t, dockerfile, DefaultChannelLabel)
+}
+
+func TestBundleLabels(t *testing.T) {
+	require.Equal(t, map[string]string{
+		MediatypeLabel: RegistryV1Mediatype,
+		ManifestsLabel: "manifests/",
+		MetadataLabel:  "metadata/",
+	}, BundleLabels(&Bundle{}))
+	require.Equal(t, map[string]string{
+		MediatypeLabel: RegistryV1Mediatype,
+		ManifestsLabel: "manifests/",
+		MetadataLabel:  "metadata/",
+		PackageLabel:   "etcd",
+		ChannelsLabel:  "alpha,stable",
+	}, BundleLabels(&Bundle{Package: "etcd", Channels: []string{"alpha", "stable"}}))
+}
+
+func mustMapFS(t *testing.T, files map[string][]byte) fs.FS {
+	fsys, err := MapFS(files)
+	require.NoError(t, err)
+	return fsys
+}
+
+func mustReadFile(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	return data
+}
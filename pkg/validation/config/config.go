@@ -0,0 +1,310 @@
+// Package config loads the configuration used to accept known validation
+// findings: suppressing them, or overriding their severity.
+//
+// A configuration file looks like:
+//
+//	suppressions:
+//	- rule: good-practices/channel-naming
+//	  bundle: memcached-operator.v0.0.1
+//	  justification: the alpha channel is kept for existing subscribers
+//	- rule: multiarch/image-platform-missing
+//	  detail: "*kube-rbac-proxy*"
+//	  justification: kube-rbac-proxy is only used on amd64 clusters
+//	overrides:
+//	- rule: good-practices/resource-requests
+//	  level: Error
+//	  justification: every container must request resources in our catalog
+//
+// Every entry must have a justification. Patterns are matched against the whole
+// value: '*' matches any sequence of characters, including '/', '?' matches any
+// single character, '[...]' matches a character class, as in path.Match, and
+// '\' escapes the next character.
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/validation/errors"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Config lists the findings to suppress or whose level must be overridden.
+type Config struct {
+	// Suppressions remove matching findings from validation results.
+	Suppressions []Suppression `json:"suppressions,omitempty"`
+	// Overrides change the level of matching findings.
+	Overrides []Override `json:"overrides,omitempty"`
+}
+
+// Matcher selects findings by rule code and, optionally, by the result, field,
+// object and detail they were reported with. Empty fields match any value.
+type Matcher struct {
+	// Rule is the errors.RuleCode of the findings.
+	Rule errors.RuleCode `json:"rule"`
+	// Bundle is a pattern matched against the name of the result, such as the
+	// bundle or ClusterServiceVersion name.
+	Bundle string `json:"bundle,omitempty"`
+	// Field is a pattern matched against the field of the finding.
+	Field string `json:"field,omitempty"`
+	// Object is a pattern matched against the name of the object the finding
+	// was reported for: its BadValue or, when it has none, the name of the result.
+	Object string `json:"object,omitempty"`
+	// Detail is a pattern matched against the detail of the finding.
+	Detail string `json:"detail,omitempty"`
+}
+
+// Suppression removes the findings selected by its Matcher.
+type Suppression struct {
+	Matcher `json:",inline"`
+	// Justification explains why the findings are accepted.
+	Justification string `json:"justification"`
+}
+
+// Override sets the level of the findings selected by its Matcher.
+type Override struct {
+	Matcher `json:",inline"`
+	// Level is the new level of the findings, one of Error or Warning.
+	Level errors.Level `json:"level"`
+	// Justification explains why the level is changed.
+	Justification string `json:"justification"`
+}
+
+// SuppressedResult holds the findings of a result which were suppressed.
+type SuppressedResult struct {
+	// Name is the name of the errors.ManifestResult the findings were part of.
+	Name string
+	// Findings are the suppressed findings.
+	Findings []SuppressedFinding
+}
+
+// SuppressedFinding is a finding removed by a Suppression.
+type SuppressedFinding struct {
+	errors.Error
+	// Justification is the justification of the matching Suppression.
+	Justification string
+}
+
+// Load reads and validates the configuration file at path.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading validation config: %v", err)
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid validation config %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse decodes and validates a YAML or JSON configuration.
+func Parse(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate returns an error if any entry of c is incomplete or invalid.
+func (c *Config) Validate() error {
+	for i, s := range c.Suppressions {
+		if err := s.Matcher.validate(s.Justification); err != nil {
+			return fmt.Errorf("suppressions[%d]: %v", i, err)
+		}
+	}
+	for i, o := range c.Overrides {
+		if err := o.Matcher.validate(o.Justification); err != nil {
+			return fmt.Errorf("overrides[%d]: %v", i, err)
+		}
+		level, ok := parseLevel(o.Level)
+		if !ok {
+			return fmt.Errorf("overrides[%d]: invalid level %q, must be one of: %s, %s", i, o.Level, errors.LevelError, errors.LevelWarn)
+		}
+		c.Overrides[i].Level = level
+	}
+	return nil
+}
+
+func (m Matcher) validate(justification string) error {
+	if m.Rule == "" {
+		return fmt.Errorf("rule must be set")
+	}
+	if _, ok := errors.LookupRule(m.Rule); !ok {
+		return fmt.Errorf("unknown rule %q", m.Rule)
+	}
+	if strings.TrimSpace(justification) == "" {
+		return fmt.Errorf("justification must be set for rule %q", m.Rule)
+	}
+	for _, pattern := range []string{m.Bundle, m.Field, m.Object, m.Detail} {
+		if _, err := compilePattern(pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q for rule %q: %v", pattern, m.Rule, err)
+		}
+	}
+	return nil
+}
+
+func parseLevel(level errors.Level) (errors.Level, bool) {
+	switch strings.ToLower(string(level)) {
+	case strings.ToLower(errors.LevelError):
+		return errors.LevelError, true
+	case strings.ToLower(errors.LevelWarn):
+		return errors.LevelWarn, true
+	}
+	return "", false
+}
+
+// Matches returns true if err, reported in the result named name, is selected by m.
+func (m Matcher) Matches(name string, err errors.Error) bool {
+	if err.Code != m.Rule {
+		return false
+	}
+	object := name
+	if err.BadValue != nil && err.BadValue != "" {
+		object = fmt.Sprintf("%v", err.BadValue)
+	}
+	return match(m.Bundle, name) && match(m.Field, err.Field) && match(m.Object, object) && match(m.Detail, err.Detail)
+}
+
+func match(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	re, err := compilePattern(pattern)
+	return err == nil && re.MatchString(value)
+}
+
+// errBadPattern is returned for the malformed patterns, like path.ErrBadPattern.
+var errBadPattern = fmt.Errorf("syntax error in pattern")
+
+// compilePattern returns the regular expression matching the same values as
+// pattern. Unlike path.Match, whose '*' stops at '/', '*' matches any sequence
+// of characters, so that patterns match the image references and paths in the
+// details of findings.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString(`^(?s:`)
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '*':
+			b.WriteString(`.*`)
+		case '?':
+			b.WriteString(`.`)
+		case '\\':
+			i++
+			if i == len(runes) {
+				return nil, errBadPattern
+			}
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '[':
+			end, class, err := compileClass(runes, i+1)
+			if err != nil {
+				return nil, err
+			}
+			b.WriteString(class)
+			i = end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString(`)$`)
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		// Such as the ranges of classes whose bounds are out of order.
+		return nil, errBadPattern
+	}
+	return re, nil
+}
+
+// compileClass returns the index of the ']' closing the character class of
+// pattern starting at start, after its '[', and the class as a regular
+// expression.
+func compileClass(pattern []rune, start int) (int, string, error) {
+	var b strings.Builder
+	b.WriteString(`[`)
+	i := start
+	if i < len(pattern) && pattern[i] == '^' {
+		b.WriteString(`^`)
+		i++
+	}
+	chars := 0
+	for ; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == ']' && chars > 0:
+			b.WriteString(`]`)
+			return i, b.String(), nil
+		case c == ']':
+			return 0, "", errBadPattern
+		case c == '\\':
+			i++
+			if i == len(pattern) {
+				return 0, "", errBadPattern
+			}
+			c = pattern[i]
+		case c == '-' && chars > 0 && i+1 < len(pattern) && pattern[i+1] != ']':
+			b.WriteString(`-`)
+			continue
+		}
+		fmt.Fprintf(&b, `\x{%x}`, c)
+		chars++
+	}
+	return 0, "", errBadPattern
+}
+
+// Apply returns results with the findings of c's overrides set to their new
+// level and the findings of c's suppressions removed. Suppressed findings are
+// returned separately, with their justification. Overrides are applied before
+// suppressions, and the first matching entry wins.
+func (c *Config) Apply(results []errors.ManifestResult) ([]errors.ManifestResult, []SuppressedResult) {
+	if c == nil {
+		return results, nil
+	}
+
+	var kept []errors.ManifestResult
+	var suppressed []SuppressedResult
+	for _, result := range results {
+		k := errors.ManifestResult{Name: result.Name}
+		s := SuppressedResult{Name: result.Name}
+		for _, err := range append(append([]errors.Error{}, result.Errors...), result.Warnings...) {
+			err = c.override(result.Name, err)
+			if justification, ok := c.suppression(result.Name, err); ok {
+				s.Findings = append(s.Findings, SuppressedFinding{Error: err, Justification: justification})
+				continue
+			}
+			k.Add(err)
+		}
+		kept = append(kept, k)
+		if len(s.Findings) > 0 {
+			suppressed = append(suppressed, s)
+		}
+	}
+	return kept, suppressed
+}
+
+func (c *Config) override(name string, err errors.Error) errors.Error {
+	for _, o := range c.Overrides {
+		if o.Matches(name, err) {
+			err.Level = o.Level
+			return err
+		}
+	}
+	return err
+}
+
+func (c *Config) suppression(name string, err errors.Error) (string, bool) {
+	for _, s := range c.Suppressions {
+		if s.Matches(name, err) {
+			return s.Justification, true
+		}
+	}
+	return "", false
+}
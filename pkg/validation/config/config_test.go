@@ -0,0 +1,217 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/validation/errors"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		description string
+		data        string
+		wantErr     string
+	}{
+		{
+			description: "valid",
+			data: `
+suppressions:
+- rule: good-practices/channel-naming
+  bundle: memcached-operator.*
+  justification: kept for existing subscribers
+overrides:
+- rule: good-practices/resource-requests
+  level: error
+  justification: required by our catalog
+`,
+		},
+		{
+			description: "empty",
+			data:        "",
+		},
+		{
+			description: "missing justification",
+			data: `
+suppressions:
+- rule: good-practices/channel-naming
+`,
+			wantErr: `suppressions[0]: justification must be set for rule "good-practices/channel-naming"`,
+		},
+		{
+			description: "missing rule",
+			data: `
+overrides:
+- level: Error
+  justification: required
+`,
+			wantErr: "overrides[0]: rule must be set",
+		},
+		{
+			description: "unknown rule",
+			data: `
+suppressions:
+- rule: good-practices/unknown
+  justification: accepted
+`,
+			wantErr: `suppressions[0]: unknown rule "good-practices/unknown"`,
+		},
+		{
+			description: "invalid level",
+			data: `
+overrides:
+- rule: good-practices/resource-requests
+  level: Info
+  justification: not important
+`,
+			wantErr: `overrides[0]: invalid level "Info", must be one of: Error, Warning`,
+		},
+		{
+			description: "invalid pattern",
+			data: `
+suppressions:
+- rule: good-practices/channel-naming
+  object: "[memcached"
+  justification: accepted
+`,
+			wantErr: `suppressions[0]: invalid pattern "[memcached" for rule "good-practices/channel-naming": syntax error in pattern`,
+		},
+		{
+			description: "unknown field",
+			data: `
+suppressions:
+- rule: good-practices/channel-naming
+  reason: accepted
+`,
+			wantErr: `error unmarshaling JSON: while decoding JSON: json: unknown field "reason"`,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+overrides:
+- rule: good-practices/resource-requests
+  level: error
+  justification: required by our catalog
+`), 0600))
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, cfg.Overrides, 1)
+	require.Equal(t, errors.Level(errors.LevelError), cfg.Overrides[0].Level)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+}
+
+func TestApply(t *testing.T) {
+	cfg, err := Parse([]byte(`
+suppressions:
+- rule: good-practices/channel-naming
+  bundle: memcached-operator.*
+  justification: kept for existing subscribers
+- rule: csv/examples-missing
+  object: etcd*
+  justification: examples are documented elsewhere
+overrides:
+- rule: good-practices/resource-requests
+  level: Error
+  justification: required by our catalog
+- rule: csv/install-modes-missing
+  level: Warning
+  justification: install modes are defaulted
+`))
+	require.NoError(t, err)
+
+	memcached := errors.ManifestResult{Name: "memcached-operator.v0.0.1"}
+	memcached.Add(
+		errors.WarnFailedValidation("channel(s) [\"alpha\"] are not following the recommended naming convention", "memcached-operator.v0.0.1").WithCode(errors.RuleGoodPracticesChannelNaming),
+		errors.WarnFailedValidation("unable to find the resource requests for the container: (manager)", "memcached-operator.v0.0.1").WithCode(errors.RuleGoodPracticesResourceRequests),
+		errors.ErrInvalidCSV("install modes not found", "memcached-operator.v0.0.1").WithCode(errors.RuleCSVInstallModesMissing),
+	)
+	etcd := errors.ManifestResult{Name: "etcdoperator.v0.9.4"}
+	etcd.Add(
+		errors.WarnFailedValidation("channel(s) [\"alpha\"] are not following the recommended naming convention", "etcdoperator.v0.9.4").WithCode(errors.RuleGoodPracticesChannelNaming),
+		errors.WarnInvalidCSV("examples not found", "etcdoperator.v0.9.4").WithCode(errors.RuleCSVExamplesMissing),
+	)
+
+	kept, suppressed := cfg.Apply([]errors.ManifestResult{memcached, etcd})
+	require.Equal(t, []errors.ManifestResult{
+		{
+			Name:     "memcached-operator.v0.0.1",
+			Errors:   []errors.Error{errors.ErrFailedValidation("unable to find the resource requests for the container: (manager)", "memcached-operator.v0.0.1").WithCode(errors.RuleGoodPracticesResourceRequests)},
+			Warnings: []errors.Error{errors.WarnInvalidCSV("install modes not found", "memcached-operator.v0.0.1").WithCode(errors.RuleCSVInstallModesMissing)},
+		},
+		{
+			Name:     "etcdoperator.v0.9.4",
+			Warnings: []errors.Error{errors.WarnFailedValidation("channel(s) [\"alpha\"] are not following the recommended naming convention", "etcdoperator.v0.9.4").WithCode(errors.RuleGoodPracticesChannelNaming)},
+		},
+	}, kept)
+	require.Equal(t, []SuppressedResult{
+		{
+			Name: "memcached-operator.v0.0.1",
+			Findings: []SuppressedFinding{{
+				Error:         errors.WarnFailedValidation("channel(s) [\"alpha\"] are not following the recommended naming convention", "memcached-operator.v0.0.1").WithCode(errors.RuleGoodPracticesChannelNaming),
+				Justification: "kept for existing subscribers",
+			}},
+		},
+		{
+			Name: "etcdoperator.v0.9.4",
+			Findings: []SuppressedFinding{{
+				Error:         errors.WarnInvalidCSV("examples not found", "etcdoperator.v0.9.4").WithCode(errors.RuleCSVExamplesMissing),
+				Justification: "examples are documented elsewhere",
+			}},
+		},
+	}, suppressed)
+}
+
+func TestApplyNil(t *testing.T) {
+	var cfg *Config
+	results := []errors.ManifestResult{{Name: "memcached-operator.v0.0.1"}}
+	kept, suppressed := cfg.Apply(results)
+	require.Equal(t, results, kept)
+	require.Empty(t, suppressed)
+}
+
+func TestMatches(t *testing.T) {
+	detail := `check if the image gcr.io/kubebuilder/kube-rbac-proxy:v0.8.0 should not support ["arm64"]. ` +
+		`Note that this CSV has labels for this Arch(s) Your manager image "quay.io/example/memcached-operator:v0.0.1" ` +
+		`are providing this support OR the CSV is configured via labels to support it. Then, please verify if this image should not support it`
+	finding := errors.WarnFailedValidation(detail, "memcached-operator.v0.0.1").WithCode(errors.RuleMultiArchImagePlatform)
+
+	cases := []struct {
+		description string
+		matcher     Matcher
+		want        bool
+	}{
+		{"any", Matcher{Rule: errors.RuleMultiArchImagePlatform}, true},
+		{"star across slashes", Matcher{Rule: errors.RuleMultiArchImagePlatform, Detail: "*kube-rbac-proxy*"}, true},
+		{"image reference", Matcher{Rule: errors.RuleMultiArchImagePlatform, Detail: "*gcr.io/kubebuilder/kube-rbac-proxy:v0.?.0 *"}, true},
+		{"character class", Matcher{Rule: errors.RuleMultiArchImagePlatform, Detail: `*\["[a-z]rm64"\]*`}, true},
+		{"negated class", Matcher{Rule: errors.RuleMultiArchImagePlatform, Detail: `*\["[^a]rm64"\]*`}, false},
+		{"whole value", Matcher{Rule: errors.RuleMultiArchImagePlatform, Detail: "kube-rbac-proxy"}, false},
+		{"other image", Matcher{Rule: errors.RuleMultiArchImagePlatform, Detail: "*/manager:*"}, false},
+		{"bundle", Matcher{Rule: errors.RuleMultiArchImagePlatform, Bundle: "memcached-operator.*"}, true},
+		{"other rule", Matcher{Rule: errors.RuleGoodPracticesChannelNaming, Detail: "*kube-rbac-proxy*"}, false},
+	}
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.matcher.Matches("memcached-operator.v0.0.1", finding))
+		})
+	}
+}
@@ -55,6 +55,31 @@ type Error struct {
 	BadValue interface{}
 	// Detail represents the error message as a string.
 	Detail string
+	// Code is the stable RuleCode of the check which found the Error, if any.
+	// See Rules for the catalog of codes.
+	Code RuleCode
+	// Fix is the change to the manifests resolving the Error, if it can be fixed automatically.
+	Fix *Fix
+	// Position is the position of the Error in the manifests, if known.
+	Position *Position
+}
+
+// WithCode returns a copy of e with its Code set to code.
+func (e Error) WithCode(code RuleCode) Error {
+	e.Code = code
+	return e
+}
+
+// WithFix returns a copy of e with its Fix set to fix.
+func (e Error) WithFix(fix Fix) Error {
+	e.Fix = &fix
+	return e
+}
+
+// WithPosition returns a copy of e with its Position set to pos.
+func (e Error) WithPosition(pos Position) Error {
+	e.Position = &pos
+	return e
 }
 
 // Error implements the 'error' interface to define custom error formatting.
@@ -103,14 +128,15 @@ const (
 	ErrorObjectFailedValidation   ErrorType = "ObjectFailedValidation"
 	ErrorPropertiesAnnotationUsed ErrorType = "PropertiesAnnotationUsed"
 	ErrorDeprecatedValidator      ErrorType = "DeprecatedValidator"
+	ErrorInvalidUpgradeGraph      ErrorType = "UpgradeGraphNotValid"
 )
 
 func NewError(t ErrorType, detail, field string, v interface{}) Error {
-	return Error{t, LevelError, field, v, detail}
+	return Error{Type: t, Level: LevelError, Field: field, BadValue: v, Detail: detail}
 }
 
 func NewWarn(t ErrorType, detail, field string, v interface{}) Error {
-	return Error{t, LevelWarn, field, v, detail}
+	return Error{Type: t, Level: LevelWarn, Field: field, BadValue: v, Detail: detail}
 }
 
 func ErrInvalidBundle(detail string, value interface{}) Error {
@@ -122,7 +148,7 @@ func WarnInvalidBundle(detail string, value interface{}) Error {
 }
 
 func invalidBundle(lvl Level, detail string, value interface{}) Error {
-	return Error{ErrorInvalidBundle, lvl, "", value, detail}
+	return Error{Type: ErrorInvalidBundle, Level: lvl, BadValue: value, Detail: detail}
 }
 
 func ErrInvalidManifestStructure(detail string) Error {
@@ -134,7 +160,7 @@ func WarnInvalidManifestStructure(detail string) Error {
 }
 
 func invalidManifestStructure(lvl Level, detail string) Error {
-	return Error{ErrorInvalidManifestStructure, lvl, "", "", detail}
+	return Error{Type: ErrorInvalidManifestStructure, Level: lvl, BadValue: "", Detail: detail}
 }
 
 func ErrInvalidCSV(detail, csvName string) Error {
@@ -146,7 +172,7 @@ func WarnInvalidCSV(detail, csvName string) Error {
 }
 
 func invalidCSV(lvl Level, detail, csvName string) Error {
-	return Error{ErrorInvalidCSV, lvl, "", "", fmt.Sprintf("(%s) %s", csvName, detail)}
+	return Error{Type: ErrorInvalidCSV, Level: lvl, BadValue: "", Detail: fmt.Sprintf("(%s) %s", csvName, detail)}
 }
 
 func ErrFieldMissing(detail string, field string, value interface{}) Error {
@@ -158,7 +184,7 @@ func WarnFieldMissing(detail string, field string, value interface{}) Error {
 }
 
 func fieldMissing(lvl Level, detail string, field string, value interface{}) Error {
-	return Error{ErrorFieldMissing, lvl, field, value, detail}
+	return Error{Type: ErrorFieldMissing, Level: lvl, Field: field, BadValue: value, Detail: detail}
 }
 
 func ErrUnsupportedType(detail string) Error {
@@ -170,7 +196,7 @@ func WarnUnsupportedType(detail string) Error {
 }
 
 func unsupportedType(lvl Level, detail string) Error {
-	return Error{ErrorUnsupportedType, lvl, "", "", detail}
+	return Error{Type: ErrorUnsupportedType, Level: lvl, BadValue: "", Detail: detail}
 }
 
 // TODO: see if more information can be extracted out of 'unmarshall/parsing' errors.
@@ -183,7 +209,7 @@ func WarnInvalidParse(detail string, value interface{}) Error {
 }
 
 func invalidParse(lvl Level, detail string, value interface{}) Error {
-	return Error{ErrorInvalidParse, lvl, "", value, detail}
+	return Error{Type: ErrorInvalidParse, Level: lvl, BadValue: value, Detail: detail}
 }
 
 func ErrInvalidPackageManifest(detail string, pkgName string) Error {
@@ -195,7 +221,19 @@ func WarnInvalidPackageManifest(detail string, pkgName string) Error {
 }
 
 func invalidPackageManifest(lvl Level, detail string, pkgName string) Error {
-	return Error{ErrorInvalidPackageManifest, lvl, "", "", fmt.Sprintf("(%s) %s", pkgName, detail)}
+	return Error{Type: ErrorInvalidPackageManifest, Level: lvl, BadValue: "", Detail: fmt.Sprintf("(%s) %s", pkgName, detail)}
+}
+
+func ErrInvalidUpgradeGraph(detail string, value interface{}) Error {
+	return invalidUpgradeGraph(LevelError, detail, value)
+}
+
+func WarnInvalidUpgradeGraph(detail string, value interface{}) Error {
+	return invalidUpgradeGraph(LevelWarn, detail, value)
+}
+
+func invalidUpgradeGraph(lvl Level, detail string, value interface{}) Error {
+	return Error{Type: ErrorInvalidUpgradeGraph, Level: lvl, BadValue: value, Detail: detail}
 }
 
 func ErrIOError(detail string, value interface{}) Error {
@@ -207,7 +245,7 @@ func WarnIOError(detail string, value interface{}) Error {
 }
 
 func iOError(lvl Level, detail string, value interface{}) Error {
-	return Error{ErrorIO, lvl, "", value, detail}
+	return Error{Type: ErrorIO, Level: lvl, BadValue: value, Detail: detail}
 }
 
 func ErrFailedValidation(detail string, value interface{}) Error {
@@ -219,7 +257,7 @@ func WarnFailedValidation(detail string, value interface{}) Error {
 }
 
 func failedValidation(lvl Level, detail string, value interface{}) Error {
-	return Error{ErrorFailedValidation, lvl, "", value, detail}
+	return Error{Type: ErrorFailedValidation, Level: lvl, BadValue: value, Detail: detail}
 }
 
 func ErrInvalidOperation(detail string, value interface{}) Error {
@@ -231,7 +269,7 @@ func WarnInvalidOperation(detail string, value interface{}) Error {
 }
 
 func invalidOperation(lvl Level, detail string, value interface{}) Error {
-	return Error{ErrorInvalidOperation, lvl, "", value, detail}
+	return Error{Type: ErrorInvalidOperation, Level: lvl, BadValue: value, Detail: detail}
 }
 
 func ErrInvalidObject(value interface{}, detail string) Error {
@@ -239,7 +277,7 @@ func ErrInvalidObject(value interface{}, detail string) Error {
 }
 
 func invalidObject(lvl Level, detail string, value interface{}) Error {
-	return Error{ErrorObjectFailedValidation, lvl, "", value, detail}
+	return Error{Type: ErrorObjectFailedValidation, Level: lvl, BadValue: value, Detail: detail}
 }
 
 func WarnInvalidObject(detail string, value interface{}) Error {
@@ -247,9 +285,9 @@ func WarnInvalidObject(detail string, value interface{}) Error {
 }
 
 func WarnPropertiesAnnotationUsed(detail string) Error {
-	return Error{ErrorPropertiesAnnotationUsed, LevelWarn, "", "", detail}
+	return Error{Type: ErrorPropertiesAnnotationUsed, Level: LevelWarn, BadValue: "", Detail: detail}
 }
 
 func WarnDeprecatedValidator(detail string) Error {
-	return Error{ErrorDeprecatedValidator, LevelWarn, "", "", detail}
+	return Error{Type: ErrorDeprecatedValidator, Level: LevelWarn, BadValue: "", Detail: detail}
 }
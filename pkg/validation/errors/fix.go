@@ -0,0 +1,58 @@
+package errors
+
+import (
+	"strings"
+)
+
+// Fix is a machine-applicable change to a manifest resolving an Error, as a
+// JSON patch against the object the Error was found in.
+type Fix struct {
+	// Description is a short, human readable description of the change.
+	Description string `json:"description"`
+	// Kind is the kind of the object to patch, ex. "ClusterServiceVersion".
+	Kind string `json:"kind,omitempty"`
+	// Name is the metadata.name of the object to patch.
+	Name string `json:"name,omitempty"`
+	// File is the path, relative to the bundle directory, of the manifest to
+	// patch when it is not an object identified by Kind and Name, ex.
+	// "metadata/properties.yaml". The file is created if it does not exist, and
+	// the add operations of Patch create the missing parents of their path.
+	File string `json:"file,omitempty"`
+	// Patch is the JSON patch (RFC 6902) to apply to the object.
+	Patch []PatchOperation `json:"patch"`
+	// Also are the changes of other manifests completing the fix, ex. adding
+	// to metadata/properties.yaml the properties removed from an annotation.
+	// The fix is applied only if all of them can be.
+	Also []Fix `json:"also,omitempty"`
+}
+
+// Supported operations of a PatchOperation.
+const (
+	PatchAdd     = "add"
+	PatchRemove  = "remove"
+	PatchReplace = "replace"
+	PatchMove    = "move"
+)
+
+// PatchOperation is a single operation of a JSON patch (RFC 6902).
+type PatchOperation struct {
+	// Op is the operation, one of PatchAdd, PatchRemove, PatchReplace or PatchMove.
+	Op string `json:"op"`
+	// Path is the JSON pointer (RFC 6901) to the value the operation is applied to.
+	Path string `json:"path"`
+	// From is the JSON pointer of the value to move, for PatchMove.
+	From string `json:"from,omitempty"`
+	// Value is the value to add or the replacement value.
+	Value interface{} `json:"value,omitempty"`
+}
+
+// JSONPointer returns the JSON pointer (RFC 6901) made of tokens, ex.
+// JSONPointer("metadata", "annotations", "olm.skipRange").
+func JSONPointer(tokens ...string) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteByte('/')
+		sb.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(token))
+	}
+	return sb.String()
+}
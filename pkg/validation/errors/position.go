@@ -0,0 +1,26 @@
+package errors
+
+import (
+	"fmt"
+)
+
+// Position is the position of a finding in the manifests it was found in.
+type Position struct {
+	// File is the path of the manifest file.
+	File string `json:"file"`
+	// Line and Column are the 1-based line and column of the finding in File,
+	// or 0 if unknown.
+	Line   int `json:"line,omitempty"`
+	Column int `json:"column,omitempty"`
+}
+
+// String returns p in the file:line:column form understood by editors.
+func (p Position) String() string {
+	switch {
+	case p.Line == 0:
+		return p.File
+	case p.Column == 0:
+		return fmt.Sprintf("%s:%d", p.File, p.Line)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
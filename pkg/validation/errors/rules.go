@@ -0,0 +1,281 @@
+package errors
+
+// RuleCode is the stable identifier of a single check performed by a Validator,
+// ex. "good-practices/resource-requests". Unlike ErrorType, which describes the
+// kind of an Error, a RuleCode identifies the check which found it, so that
+// findings can be suppressed, reported and counted per check.
+type RuleCode string
+
+// Rule describes a check identified by a RuleCode.
+type Rule struct {
+	// Code is the stable identifier of the check.
+	Code RuleCode
+	// Description is a short, human readable description of what is checked.
+	Description string
+	// Level is the severity of the Errors found by the check when no optional
+	// values change it.
+	Level Level
+	// Docs is a link to the documentation of the check, if any.
+	Docs string
+}
+
+const (
+	docsBundle          = "https://github.com/operator-framework/operator-registry/blob/v1.19.5/docs/design/operator-bundle.md"
+	docsGoodPractices   = "https://sdk.operatorframework.io/docs/best-practices/"
+	docsMultiArch       = "https://olm.operatorframework.io/docs/advanced-tasks/ship-operator-supporting-multiarch/"
+	docsDeprecationList = "https://kubernetes.io/docs/reference/using-api/deprecation-guide/"
+	docsValidationPkg   = "https://pkg.go.dev/github.com/operator-framework/api/pkg/validation"
+	docsUpgradeGraph    = "https://olm.operatorframework.io/docs/concepts/olm-architecture/operator-catalog/creating-an-update-graph/"
+)
+
+// Bundle rules.
+const (
+	RuleBundleMissing                   RuleCode = "bundle/missing"
+	RuleBundleDuplicateCRD              RuleCode = "bundle/duplicate-crd"
+	RuleBundleOwnedCRDMissing           RuleCode = "bundle/owned-crd-missing"
+	RuleBundleCRDNotInCSV               RuleCode = "bundle/crd-not-in-csv"
+	RuleBundleDuplicateServiceAccount   RuleCode = "bundle/duplicate-service-account"
+	RuleBundleSizeUnknown               RuleCode = "bundle/size-unknown"
+	RuleBundleSizeExceeded              RuleCode = "bundle/size-exceeded"
+	RuleBundleSizeNearLimit             RuleCode = "bundle/size-near-limit"
+	RuleBundleNameMismatch              RuleCode = "bundle/name-mismatch"
+	RuleBundleRelatedImageInvalid       RuleCode = "bundle/related-image-invalid"
+	RuleBundleMultipleDocuments         RuleCode = "bundle/multiple-documents"
+	RuleBundlePropertyInvalid           RuleCode = "bundle/property-invalid"
+	RuleBundleDependencyInvalid         RuleCode = "bundle/dependency-invalid"
+	RuleBundleConstraintTooLarge        RuleCode = "bundle/constraint-too-large"
+	RuleBundleConstraintUnsatisfiable   RuleCode = "bundle/constraint-unsatisfiable"
+	RuleBundleConstraintAlwaysSatisfied RuleCode = "bundle/constraint-always-satisfied"
+	RuleBundleConstraintRedundant       RuleCode = "bundle/constraint-redundant"
+	RulePackageManifestNameMissing      RuleCode = "packagemanifest/name-missing"
+	RulePackageManifestChannelsMissing  RuleCode = "packagemanifest/channels-missing"
+	RulePackageManifestDefaultChannel   RuleCode = "packagemanifest/default-channel"
+	RulePackageManifestChannelInvalid   RuleCode = "packagemanifest/channel-invalid"
+)
+
+// ClusterServiceVersion rules.
+const (
+	RuleCSVNameInvalid               RuleCode = "csv/name-invalid"
+	RuleCSVReplacesInvalid           RuleCode = "csv/replaces-invalid"
+	RuleCSVTypeMetaMissing           RuleCode = "csv/type-meta-missing"
+	RuleCSVRequiredFieldMissing      RuleCode = "csv/required-field-missing"
+	RuleCSVExamplesMissing           RuleCode = "csv/examples-missing"
+	RuleCSVExamplesDuplicated        RuleCode = "csv/examples-duplicated"
+	RuleCSVExamplesInvalid           RuleCode = "csv/examples-invalid"
+	RuleCSVExampleAPINotProvided     RuleCode = "csv/example-api-not-provided"
+	RuleCSVProvidedAPIExampleMissing RuleCode = "csv/provided-api-example-missing"
+	RuleCSVOwnedCRDNameInvalid       RuleCode = "csv/owned-crd-name-invalid"
+	RuleCSVInstallModesMissing       RuleCode = "csv/install-modes-missing"
+	RuleCSVInstallModesDuplicated    RuleCode = "csv/install-modes-duplicated"
+	RuleCSVInstallModesUnsupported   RuleCode = "csv/install-modes-unsupported"
+	RuleCSVInstallModesConversion    RuleCode = "csv/install-modes-conversion-crds"
+	RuleCSVMinKubeVersionMissing     RuleCode = "csv/min-kube-version-missing"
+	RuleCSVMinKubeVersionInvalid     RuleCode = "csv/min-kube-version-invalid"
+	RuleAnnotationCase               RuleCode = "annotations/case"
+	RuleAnnotationProperties         RuleCode = "annotations/olm-properties"
+)
+
+// CustomResourceDefinition and object rules.
+const (
+	RuleCRDInvalid                       RuleCode = "crd/invalid"
+	RuleObjectInvalid                    RuleCode = "object/invalid"
+	RuleObjectPDBMaxUnavailable          RuleCode = "object/pdb-max-unavailable"
+	RuleObjectPDBMinAvailable            RuleCode = "object/pdb-min-available"
+	RuleObjectPriorityClassGlobalDefault RuleCode = "object/priorityclass-global-default"
+	RuleObjectRBACPodDisruptionBudgets   RuleCode = "object/rbac-poddisruptionbudgets"
+	RuleObjectRBACDefaultSCC             RuleCode = "object/rbac-default-scc"
+)
+
+// OperatorHub rules.
+const (
+	RuleOperatorHubProviderMissing      RuleCode = "operatorhub/provider-missing"
+	RuleOperatorHubMaintainerIncomplete RuleCode = "operatorhub/maintainer-incomplete"
+	RuleOperatorHubMaintainerEmail      RuleCode = "operatorhub/maintainer-email-invalid"
+	RuleOperatorHubLinkIncomplete       RuleCode = "operatorhub/link-incomplete"
+	RuleOperatorHubLinkURL              RuleCode = "operatorhub/link-url-invalid"
+	RuleOperatorHubVersionMissing       RuleCode = "operatorhub/version-missing"
+	RuleOperatorHubIconMissing          RuleCode = "operatorhub/icon-missing"
+	RuleOperatorHubIconCount            RuleCode = "operatorhub/icon-count"
+	RuleOperatorHubIconIncomplete       RuleCode = "operatorhub/icon-incomplete"
+	RuleOperatorHubIconMediaType        RuleCode = "operatorhub/icon-mediatype-invalid"
+	RuleOperatorHubMinKubeVersion       RuleCode = "operatorhub/min-kube-version-missing"
+	RuleOperatorHubMinKubeVersionFormat RuleCode = "operatorhub/min-kube-version-invalid"
+	RuleOperatorHubDeprecated           RuleCode = "operatorhub/deprecated-validator"
+	RuleCapabilitiesInvalid             RuleCode = "capabilities/invalid"
+	RuleCategoriesInvalid               RuleCode = "categories/invalid"
+	RuleCategoriesCustomFile            RuleCode = "categories/custom-file-invalid"
+	RuleCommunityCriteria               RuleCode = "community/criteria"
+)
+
+// Removed APIs rules.
+const (
+	RuleRemovedAPIsK8sVersionInvalid     RuleCode = "removed-apis/k8s-version-invalid"
+	RuleRemovedAPIsMinKubeVersionInvalid RuleCode = "removed-apis/min-kube-version-invalid"
+	RuleRemovedAPIs1_22                  RuleCode = "removed-apis/v1.22"
+	RuleRemovedAPIs1_25                  RuleCode = "removed-apis/v1.25"
+	RuleRemovedAPIs1_26                  RuleCode = "removed-apis/v1.26"
+)
+
+// Good practices rules.
+const (
+	RuleGoodPracticesDeploymentMissing    RuleCode = "good-practices/deployment-missing"
+	RuleGoodPracticesResourceRequests     RuleCode = "good-practices/resource-requests"
+	RuleGoodPracticesCRDDescription       RuleCode = "good-practices/crd-description"
+	RuleGoodPracticesChannelNaming        RuleCode = "good-practices/channel-naming"
+	RuleGoodPracticesRBACForCRDs          RuleCode = "good-practices/rbac-for-crds"
+	RuleGoodPracticesCSVNameSemver        RuleCode = "good-practices/csv-name-semver"
+	RuleGoodPracticesCSVNameConvention    RuleCode = "good-practices/csv-name-convention"
+	RuleGoodPracticesRelatedImagesMissing RuleCode = "good-practices/related-images-missing"
+)
+
+// Multiple architectures rules.
+const (
+	RuleMultiArchContainerTool       RuleCode = "multiarch/container-tool-invalid"
+	RuleMultiArchInspectFailed       RuleCode = "multiarch/inspect-failed"
+	RuleMultiArchLabelMissing        RuleCode = "multiarch/label-missing"
+	RuleMultiArchImagePlatform       RuleCode = "multiarch/image-platform-missing"
+	RuleMultiArchNodeAffinityMissing RuleCode = "multiarch/node-affinity-missing"
+	RuleMultiArchNodeAffinity        RuleCode = "multiarch/node-affinity-mismatch"
+	RuleMultiArchUnsupported         RuleCode = "multiarch/unsupported-platform"
+)
+
+// Upgrade graph rules.
+const (
+	RuleGraphInvalid          RuleCode = "graph/invalid"
+	RuleGraphCycle            RuleCode = "graph/cycle"
+	RuleGraphMultipleHeads    RuleCode = "graph/multiple-heads"
+	RuleGraphUnreachable      RuleCode = "graph/unreachable-bundle"
+	RuleGraphDanglingReplaces RuleCode = "graph/dangling-replaces"
+	RuleGraphSkipRangeInvalid RuleCode = "graph/skiprange-invalid"
+	RuleGraphSkipRangeNoMatch RuleCode = "graph/skiprange-no-match"
+)
+
+// Optional values rules.
+const (
+	RuleOptionsUnknownKey RuleCode = "options/unknown-key"
+)
+
+// rules is the catalog of every RuleCode emitted by this library.
+var rules = []Rule{
+	{RuleBundleMissing, "The bundle or its ClusterServiceVersion is missing", LevelError, docsBundle},
+	{RuleBundleDuplicateCRD, "The bundle contains the same CRD version more than once", LevelError, docsBundle},
+	{RuleBundleOwnedCRDMissing, "A CRD owned by the ClusterServiceVersion is not in the bundle", LevelError, docsBundle},
+	{RuleBundleCRDNotInCSV, "A CRD in the bundle is not owned by the ClusterServiceVersion", LevelError, docsBundle},
+	{RuleBundleDuplicateServiceAccount, "A ServiceAccount in the bundle is also defined by the ClusterServiceVersion deployments", LevelError, docsBundle},
+	{RuleBundleSizeUnknown, "The bundle size could not be checked", LevelWarn, docsBundle},
+	{RuleBundleSizeExceeded, "The compressed bundle is larger than the maximum size of ~1MB", LevelError, docsBundle},
+	{RuleBundleSizeNearLimit, "The compressed bundle is close to the maximum size of ~1MB", LevelWarn, docsBundle},
+	{RuleBundleNameMismatch, "The bundle name does not match <package>-v<version>-<release>", LevelError, docsBundle},
+	{RuleBundleRelatedImageInvalid, "A related image is empty or not a valid pullspec", LevelError, docsBundle},
+	{RuleBundleMultipleDocuments, "A manifest file holds more than one object, which tools loading only its first document ignore", LevelWarn, docsBundle},
+	{RuleBundlePropertyInvalid, "A property of metadata/properties.yaml is malformed", LevelError, docsBundle},
+	{RuleBundleDependencyInvalid, "A dependency of metadata/dependencies.yaml is malformed", LevelError, docsBundle},
+	{RuleBundleConstraintTooLarge, "An olm.constraint value is larger than the maximum constraint size", LevelError, docsBundle},
+	{RuleBundleConstraintUnsatisfiable, "An olm.constraint, or a constraint nested in it, can never be satisfied", LevelError, docsBundle},
+	{RuleBundleConstraintAlwaysSatisfied, "An olm.constraint, or a constraint nested in it, is satisfied by every bundle", LevelWarn, docsBundle},
+	{RuleBundleConstraintRedundant, "A constraint nested in an olm.constraint is a duplicate or never changes whether it is satisfied", LevelWarn, docsBundle},
+	{RulePackageManifestNameMissing, "The package manifest has no packageName", LevelError, ""},
+	{RulePackageManifestChannelsMissing, "The package manifest has no channels", LevelError, ""},
+	{RulePackageManifestDefaultChannel, "The package manifest default channel is empty or not declared", LevelError, ""},
+	{RulePackageManifestChannelInvalid, "A package manifest channel has no name or currentCSV, or is duplicated", LevelError, ""},
+
+	{RuleCSVNameInvalid, "metadata.name is not a valid DNS subdomain and label value", LevelError, ""},
+	{RuleCSVReplacesInvalid, "spec.replaces is not a valid DNS subdomain and label value", LevelError, ""},
+	{RuleCSVTypeMetaMissing, "apiVersion or kind is missing", LevelError, ""},
+	{RuleCSVRequiredFieldMissing, "A required field is missing", LevelError, ""},
+	{RuleCSVExamplesMissing, "The alm-examples or olm.examples annotation is missing", LevelWarn, ""},
+	{RuleCSVExamplesDuplicated, "Both the alm-examples and olm.examples annotations are set", LevelWarn, ""},
+	{RuleCSVExamplesInvalid, "The example annotation cannot be parsed", LevelError, ""},
+	{RuleCSVExampleAPINotProvided, "An example is not of an API provided by the ClusterServiceVersion", LevelError, ""},
+	{RuleCSVProvidedAPIExampleMissing, "A provided API has no example", LevelWarn, ""},
+	{RuleCSVOwnedCRDNameInvalid, "An owned CRD name is not of the form <plural>.<group>", LevelError, ""},
+	{RuleCSVInstallModesMissing, "spec.installModes is empty", LevelError, ""},
+	{RuleCSVInstallModesDuplicated, "An install mode is declared more than once", LevelError, ""},
+	{RuleCSVInstallModesUnsupported, "None of the install modes are supported", LevelError, ""},
+	{RuleCSVInstallModesConversion, "Only AllNamespaces is supported when conversion webhooks are defined", LevelError, ""},
+	{RuleCSVMinKubeVersionMissing, "spec.minKubeVersion is not set", LevelWarn, ""},
+	{RuleCSVMinKubeVersionInvalid, "spec.minKubeVersion is not a valid semantic version", LevelError, ""},
+	{RuleAnnotationCase, "A case sensitive annotation key uses the wrong case", LevelError, ""},
+	{RuleAnnotationProperties, "The olm.properties annotation is used instead of metadata/properties.yaml", LevelWarn, ""},
+
+	{RuleCRDInvalid, "The CRD fails the Kubernetes API server validation", LevelError, ""},
+	{RuleObjectInvalid, "The object cannot be decoded", LevelError, ""},
+	{RuleObjectPDBMaxUnavailable, "A PodDisruptionBudget sets maxUnavailable to 0 or 0%", LevelError,
+		"https://github.com/operator-framework/operator-lifecycle-manager/blob/master/doc/design/adding-pod-disruption-budgets.md#limitations-on-pod-disruption-budgets"},
+	{RuleObjectPDBMinAvailable, "A PodDisruptionBudget sets minAvailable to 100%", LevelError,
+		"https://github.com/operator-framework/operator-lifecycle-manager/blob/master/doc/design/adding-pod-disruption-budgets.md#limitations-on-pod-disruption-budgets"},
+	{RuleObjectPriorityClassGlobalDefault, "A PriorityClass sets globalDefault to true", LevelError,
+		"https://github.com/operator-framework/operator-lifecycle-manager/blob/master/doc/design/adding-priority-classes.md"},
+	{RuleObjectRBACPodDisruptionBudgets, "RBAC allows to create or update PodDisruptionBudgets", LevelWarn, ""},
+	{RuleObjectRBACDefaultSCC, "RBAC allows to modify the default SecurityContextConstraints", LevelError,
+		"https://docs.openshift.com/container-platform/4.5/authentication/managing-security-context-constraints.html#security-context-constraints-about_configuring-internal-oauth"},
+
+	{RuleOperatorHubProviderMissing, "spec.provider.name is not set", LevelError, docsValidationPkg + "#OperatorHubV2Validator"},
+	{RuleOperatorHubMaintainerIncomplete, "A maintainer has no name or email", LevelError, docsValidationPkg + "#OperatorHubV2Validator"},
+	{RuleOperatorHubMaintainerEmail, "A maintainer email is not valid", LevelError, docsValidationPkg + "#OperatorHubV2Validator"},
+	{RuleOperatorHubLinkIncomplete, "A link has no name or url", LevelError, docsValidationPkg + "#OperatorHubV2Validator"},
+	{RuleOperatorHubLinkURL, "A link url is not valid", LevelError, docsValidationPkg + "#OperatorHubV2Validator"},
+	{RuleOperatorHubVersionMissing, "spec.version is not set", LevelError, docsValidationPkg + "#OperatorHubV2Validator"},
+	{RuleOperatorHubIconMissing, "spec.icon is not set", LevelWarn, docsValidationPkg + "#OperatorHubV2Validator"},
+	{RuleOperatorHubIconCount, "spec.icon has more than one element", LevelError, docsValidationPkg + "#OperatorHubV2Validator"},
+	{RuleOperatorHubIconIncomplete, "An icon has no data or mediatype", LevelError, docsValidationPkg + "#OperatorHubV2Validator"},
+	{RuleOperatorHubIconMediaType, "An icon mediatype is not one of image/gif, image/jpeg, image/png or image/svg+xml", LevelError, docsValidationPkg + "#OperatorHubV2Validator"},
+	{RuleOperatorHubMinKubeVersion, "spec.minKubeVersion is not set", LevelWarn, docsValidationPkg + "#OperatorHubV2Validator"},
+	{RuleOperatorHubMinKubeVersionFormat, "spec.minKubeVersion is not a valid semantic version", LevelError, docsValidationPkg + "#OperatorHubV2Validator"},
+	{RuleOperatorHubDeprecated, "The operatorhub validator is deprecated", LevelWarn, docsValidationPkg + "#OperatorHubValidator"},
+	{RuleCapabilitiesInvalid, "The capabilities annotation is not a valid capability level", LevelError, docsValidationPkg + "#StandardCapabilitiesValidator"},
+	{RuleCategoriesInvalid, "The categories annotation has a category which is not allowed", LevelError, docsValidationPkg + "#StandardCategoriesValidator"},
+	{RuleCategoriesCustomFile, "The custom categories file set by OPERATOR_BUNDLE_CATEGORIES cannot be read", LevelError, docsValidationPkg + "#StandardCategoriesValidator"},
+	{RuleCommunityCriteria, "The bundle does not meet the criteria of the community operators", LevelError, "https://github.com/redhat-openshift-ecosystem/ocp-olm-catalog-validator"},
+
+	{RuleRemovedAPIsK8sVersionInvalid, "The k8s-version optional value is not a valid version", LevelError, docsDeprecationList},
+	{RuleRemovedAPIsMinKubeVersionInvalid, "spec.minKubeVersion cannot be used to check for removed APIs", LevelError, docsDeprecationList},
+	{RuleRemovedAPIs1_22, "The bundle uses APIs removed in Kubernetes 1.22", LevelWarn, docsDeprecationList + "#v1-22"},
+	{RuleRemovedAPIs1_25, "The bundle uses APIs removed in Kubernetes 1.25", LevelWarn, docsDeprecationList + "#v1-25"},
+	{RuleRemovedAPIs1_26, "The bundle uses APIs removed in Kubernetes 1.26", LevelWarn, docsDeprecationList + "#v1-26"},
+
+	{RuleGoodPracticesDeploymentMissing, "The ClusterServiceVersion has no deployment to install", LevelError, docsGoodPractices},
+	{RuleGoodPracticesResourceRequests, "A container does not request CPU and memory", LevelWarn,
+		"https://master.sdk.operatorframework.io/docs/best-practices/managing-resources/"},
+	{RuleGoodPracticesCRDDescription, "An owned or required CRD has an empty description", LevelWarn, docsGoodPractices},
+	{RuleGoodPracticesChannelNaming, "A channel does not follow the naming convention", LevelWarn,
+		"https://olm.operatorframework.io/docs/best-practices/channel-naming/"},
+	{RuleGoodPracticesRBACForCRDs, "The ClusterServiceVersion has permissions to create CRDs", LevelWarn,
+		"https://sdk.operatorframework.io/docs/best-practices/common-recommendation/"},
+	{RuleGoodPracticesCSVNameSemver, "The ClusterServiceVersion name does not end with a semantic version", LevelWarn, "https://semver.org/"},
+	{RuleGoodPracticesCSVNameConvention, "The ClusterServiceVersion name does not follow <operator-name>.v<semver>", LevelWarn, docsGoodPractices},
+	{RuleGoodPracticesRelatedImagesMissing, "An image of the ClusterServiceVersion deployments is not listed in its relatedImages", LevelWarn, docsGoodPractices},
+
+	{RuleMultiArchContainerTool, "The container-tools optional value is not one of docker, podman or none", LevelError, docsMultiArch},
+	{RuleMultiArchInspectFailed, "An image could not be inspected", LevelWarn, docsMultiArch},
+	{RuleMultiArchLabelMissing, "The ClusterServiceVersion has no label for an OS or architecture supported by the manager image", LevelWarn, docsMultiArch},
+	{RuleMultiArchImagePlatform, "An image does not support an OS or architecture supported by the manager image", LevelWarn, docsMultiArch},
+	{RuleMultiArchNodeAffinityMissing, "A deployment has no node affinity for the platforms of its image", LevelWarn, docsMultiArch},
+	{RuleMultiArchNodeAffinity, "A deployment node affinity does not match the platforms of its image", LevelWarn, docsMultiArch},
+	{RuleMultiArchUnsupported, "An image does not support a platform declared by the ClusterServiceVersion labels", LevelError, docsMultiArch},
+
+	{RuleGraphInvalid, "The upgrade graph of a channel cannot be built from the bundles of its package", LevelError, docsUpgradeGraph},
+	{RuleGraphCycle, "Bundles of a channel upgrade to one another in a cycle", LevelError, docsUpgradeGraph},
+	{RuleGraphMultipleHeads, "A channel has more than one bundle which no bundle upgrades from", LevelError, docsUpgradeGraph},
+	{RuleGraphUnreachable, "A bundle of a channel cannot be upgraded to the head of the channel", LevelError, docsUpgradeGraph},
+	{RuleGraphDanglingReplaces, "A bundle replaces a bundle which is not in its channel", LevelWarn, docsUpgradeGraph},
+	{RuleGraphSkipRangeInvalid, "The olm.skipRange of a bundle is not a valid semver range", LevelError, docsUpgradeGraph},
+	{RuleGraphSkipRangeNoMatch, "The olm.skipRange of a bundle matches no bundle of its channel", LevelWarn, docsUpgradeGraph},
+
+	{RuleOptionsUnknownKey, "An optional value has a key which is not accepted by any validator", LevelError, docsValidationPkg},
+}
+
+// Rules returns the catalog of every RuleCode emitted by this library.
+func Rules() []Rule {
+	return append([]Rule{}, rules...)
+}
+
+// LookupRule returns the Rule identified by code.
+func LookupRule(code RuleCode) (Rule, bool) {
+	for _, r := range rules {
+		if r.Code == code {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
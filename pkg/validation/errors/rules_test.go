@@ -0,0 +1,33 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRules(t *testing.T) {
+	seen := map[RuleCode]struct{}{}
+	for _, r := range Rules() {
+		require.NotEmpty(t, r.Code)
+		require.NotEmpty(t, r.Description, "rule %q", r.Code)
+		require.Contains(t, []Level{LevelError, LevelWarn}, r.Level, "rule %q", r.Code)
+		_, dup := seen[r.Code]
+		require.False(t, dup, "rule %q is in the catalog twice", r.Code)
+		seen[r.Code] = struct{}{}
+	}
+
+	r, ok := LookupRule(RuleGoodPracticesResourceRequests)
+	require.True(t, ok)
+	require.Equal(t, Level(LevelWarn), r.Level)
+	_, ok = LookupRule("unknown/rule")
+	require.False(t, ok)
+}
+
+func TestErrorWithCode(t *testing.T) {
+	err := ErrInvalidBundle("bad bundle", "foo")
+	coded := err.WithCode(RuleBundleMissing)
+	require.Equal(t, RuleBundleMissing, coded.Code)
+	require.Equal(t, RuleCode(""), err.Code)
+	require.Equal(t, err.Error(), coded.Error())
+}
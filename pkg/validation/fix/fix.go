@@ -0,0 +1,368 @@
+// Package fix applies the fixes attached to validation findings to the
+// manifests of a bundle directory.
+//
+// Fixes are JSON patches against the object a finding was reported for,
+// identified by its kind and name. They are applied to the YAML manifests in
+// place: only the entries changed by the fixes are rewritten, and the other
+// lines of the files are kept as they are:
+//
+//	fixes := fix.Fixes(validators.Validate(bundle.ObjectsToValidate()...))
+//	changes, err := fix.Plan(dir, fixes)
+//	for _, change := range changes {
+//		fmt.Print(change.Diff())
+//	}
+//	err = fix.Write(changes)
+package fix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/validation/errors"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"go.yaml.in/yaml/v3"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Change is the change of a manifest file made by applying fixes.
+type Change struct {
+	// Path is the path of the manifest file.
+	Path string
+	// Before and After are the contents of the file before and after the
+	// fixes. Before is nil for the files created by the fixes.
+	Before, After []byte
+	// Fixes are the fixes applied to the file.
+	Fixes []errors.Fix
+}
+
+// Diff returns the unified diff of the change.
+func (c Change) Diff() string {
+	path := strings.TrimPrefix(filepath.ToSlash(c.Path), "/")
+	from := "a/" + path
+	if c.Before == nil {
+		from = "/dev/null"
+	}
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(c.Before)),
+		B:        difflib.SplitLines(string(c.After)),
+		FromFile: from,
+		ToFile:   "b/" + path,
+		Context:  3,
+	})
+	return diff
+}
+
+// Fixes returns the fixes of the findings of results, without duplicates.
+func Fixes(results []errors.ManifestResult) []errors.Fix {
+	var fixes []errors.Fix
+	seen := map[string]struct{}{}
+	for _, result := range results {
+		for _, err := range append(append([]errors.Error{}, result.Errors...), result.Warnings...) {
+			if err.Fix == nil {
+				continue
+			}
+			key, _ := json.Marshal(err.Fix)
+			if _, ok := seen[string(key)]; ok {
+				continue
+			}
+			seen[string(key)] = struct{}{}
+			fixes = append(fixes, *err.Fix)
+		}
+	}
+	return fixes
+}
+
+// Plan returns the changes to the YAML manifests in dir made by applying fixes,
+// in the order of the files in dir, followed by the files the fixes create.
+// A fix is applied along with the changes of its Also fixes, or not at all.
+// Fixes which cannot be applied, or whose object is not found, are reported in
+// the returned error, and the others are still part of the changes.
+func Plan(dir string, fixes []errors.Fix) ([]Change, error) {
+	files, err := readManifests(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, fix := range fixes {
+		var err error
+		if files, err = plan(dir, files, fix); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	var changes []Change
+	for _, f := range files {
+		if len(f.fixes) == 0 {
+			continue
+		}
+		after, err := encode(f.docs, f.before)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error encoding %s: %v", f.path, err))
+			continue
+		}
+		changes = append(changes, Change{Path: f.path, Before: f.before, After: after, Fixes: f.fixes})
+	}
+	return changes, utilerrors.NewAggregate(errs)
+}
+
+// manifest is a YAML manifest file of a bundle directory being fixed.
+type manifest struct {
+	path   string
+	before []byte
+	docs   []*yaml.Node
+	fixes  []errors.Fix
+}
+
+// readManifests returns the YAML manifests in dir, in order.
+func readManifests(dir string) ([]*manifest, error) {
+	var files []*manifest
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isYAML(path) {
+			return nil
+		}
+
+		before, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		docs, err := decode(before)
+		if err != nil {
+			// Manifests which cannot be decoded are reported by the bundle loader.
+			return nil
+		}
+		files = append(files, &manifest{path: path, before: before, docs: docs})
+		return nil
+	})
+	return files, err
+}
+
+// plan applies fix and its Also fixes to the documents of files, or returns
+// an error and leaves them unchanged if any of them cannot be applied. The
+// files created by the fixes are added to the returned files.
+func plan(dir string, files []*manifest, fix errors.Fix) ([]*manifest, error) {
+	patched := map[*yaml.Node]*yaml.Node{}
+	var applied []*manifest
+	var appliedFixes []errors.Fix
+	for _, part := range append([]errors.Fix{fix}, fix.Also...) {
+		targets := files
+		if part.File != "" {
+			path := filepath.Join(dir, filepath.FromSlash(part.File))
+			targets = nil
+			for _, f := range files {
+				if filepath.Clean(f.path) == path {
+					targets = []*manifest{f}
+				}
+			}
+			if targets == nil {
+				created := &manifest{path: path, docs: []*yaml.Node{{
+					Kind:    yaml.DocumentNode,
+					Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+				}}}
+				files = append(files, created)
+				targets = []*manifest{created}
+			}
+		}
+
+		found := false
+		for _, f := range targets {
+			inFile := false
+			for _, doc := range f.docs {
+				if part.File == "" && !matches(doc, part) {
+					continue
+				}
+				inFile = true
+				current, ok := patched[doc]
+				if !ok {
+					current = doc
+				}
+				next := deepCopy(current)
+				if err := applyPatch(next, part.Patch, part.File != ""); err != nil {
+					return dropCreated(files), fmt.Errorf("unable to apply fix %q to %s: %v", part.Description, f.path, err)
+				}
+				patched[doc] = next
+			}
+			if inFile {
+				found = true
+				applied = append(applied, f)
+				appliedFixes = append(appliedFixes, part)
+			}
+		}
+		if !found {
+			return dropCreated(files), fmt.Errorf("unable to apply fix %q: %s %q not found in %s", part.Description, part.Kind, part.Name, dir)
+		}
+	}
+
+	for doc, next := range patched {
+		*doc = *next
+	}
+	for i, f := range applied {
+		f.fixes = append(f.fixes, appliedFixes[i])
+	}
+	return files, nil
+}
+
+// dropCreated returns files without the files created by a fix which was not
+// applied.
+func dropCreated(files []*manifest) []*manifest {
+	kept := files[:0]
+	for _, f := range files {
+		if f.before != nil || len(f.fixes) > 0 {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// Write writes the fixed contents of the files of changes, creating the files
+// which do not exist.
+func Write(changes []Change) error {
+	for _, change := range changes {
+		perm := os.FileMode(0644)
+		info, err := os.Stat(change.Path)
+		switch {
+		case err == nil:
+			perm = info.Mode().Perm()
+		case os.IsNotExist(err):
+			if err := os.MkdirAll(filepath.Dir(change.Path), 0755); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+		if err := os.WriteFile(change.Path, change.After, perm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// matches returns true if doc is the object fix applies to.
+func matches(doc *yaml.Node, fix errors.Fix) bool {
+	if len(doc.Content) == 0 {
+		return false
+	}
+	obj := struct {
+		Kind     string `yaml:"kind"`
+		Metadata struct {
+			Name string `yaml:"name"`
+		} `yaml:"metadata"`
+	}{}
+	if err := doc.Content[0].Decode(&obj); err != nil {
+		return false
+	}
+	return obj.Kind == fix.Kind && obj.Metadata.Name == fix.Name
+}
+
+func decode(data []byte) ([]*yaml.Node, error) {
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		doc := &yaml.Node{}
+		if err := dec.Decode(doc); err == io.EOF {
+			return docs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// encoders are the encodings a manifest may have been written with: the
+// indentation of kubebuilder and the Operator SDK, with sequences indented or
+// not, and the defaults of yaml.v3.
+var encoders = []func(io.Writer) *yaml.Encoder{
+	func(w io.Writer) *yaml.Encoder {
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		enc.CompactSeqIndent()
+		return enc
+	},
+	func(w io.Writer) *yaml.Encoder {
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		return enc
+	},
+	yaml.NewEncoder,
+}
+
+// encode returns docs encoded like original, the contents they were decoded
+// from, or with the first of encoders if original is nil. Only the entries of
+// original changed in docs are rewritten, unless their lines cannot be located.
+func encode(docs []*yaml.Node, original []byte) ([]byte, error) {
+	if original == nil {
+		// The file is created by the fixes.
+		return encodeWith(encoders[0], docs)
+	}
+	style, err := encoding(original)
+	if err != nil {
+		return nil, err
+	}
+	originals, err := decode(original)
+	if err != nil {
+		return nil, err
+	}
+	if data, ok, err := splice(original, originals, docs, style); err != nil || ok {
+		return data, err
+	}
+	return encodeWith(style, docs)
+}
+
+// encoding returns the encoder reproducing original most closely.
+func encoding(original []byte) (func(io.Writer) *yaml.Encoder, error) {
+	docs, err := decode(original)
+	if err != nil {
+		return nil, err
+	}
+	best, bestDistance := encoders[0], -1
+	for _, newEncoder := range encoders {
+		data, err := encodeWith(newEncoder, docs)
+		if err != nil {
+			return nil, err
+		}
+		distance := distance(original, data)
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = newEncoder, distance
+		}
+	}
+	return best, nil
+}
+
+func encodeWith(newEncoder func(io.Writer) *yaml.Encoder, docs []*yaml.Node) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	enc := newEncoder(buf)
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// distance returns the number of lines of a and b which are not matched.
+func distance(a, b []byte) int {
+	linesA, linesB := difflib.SplitLines(string(a)), difflib.SplitLines(string(b))
+	matched := 0
+	for _, block := range difflib.NewMatcher(linesA, linesB).GetMatchingBlocks() {
+		matched += block.Size
+	}
+	return len(linesA) + len(linesB) - 2*matched
+}
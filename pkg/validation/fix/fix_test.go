@@ -0,0 +1,340 @@
+package fix
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/validation"
+	"github.com/operator-framework/api/pkg/validation/errors"
+
+	"github.com/stretchr/testify/require"
+)
+
+// copyDir copies the files of the directory src to a temporary directory.
+func copyDir(t *testing.T, src string) string {
+	dir := t.TempDir()
+	require.NoError(t, filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dir, rel), 0755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, rel), data, 0644)
+	}))
+	return dir
+}
+
+func validate(t *testing.T, dir string) []errors.ManifestResult {
+	bundle, err := manifests.GetBundleFromDir(dir)
+	require.NoError(t, err)
+	validators := validation.DefaultBundleValidators.WithValidators(validation.GoodPracticesValidator)
+	return validators.Validate(bundle.ObjectsToValidate()...)
+}
+
+func TestFixBundle(t *testing.T) {
+	dir := copyDir(t, "./testdata/bundle")
+	csvPath := filepath.Join(dir, "manifests", "memcached-operator.clusterserviceversion.yaml")
+	propertiesPath := filepath.Join(dir, "metadata", "properties.yaml")
+
+	fixes := Fixes(validate(t, dir))
+	require.Len(t, fixes, 5)
+
+	changes, err := Plan(dir, fixes)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, csvPath, changes[0].Path)
+	require.Len(t, changes[0].Fixes, 5)
+	diffPath := strings.TrimPrefix(filepath.ToSlash(csvPath), "/")
+	require.Equal(t, `--- a/`+diffPath+`
++++ b/`+diffPath+`
+@@ -16,8 +16,7 @@
+         }
+       ]
+     capabilities: Basic Install
+-    olm.properties: '[{"type":"olm.maxOpenShiftVersion","value":"4.8"}]'
+-    olm.skiprange: <0.0.1
++    olm.skipRange: <0.0.1
+   name: memcached-operator.v0.0.1
+   namespace: placeholder
+ spec:
+@@ -121,7 +120,10 @@
+                 ports:
+                 - containerPort: 8443
+                   name: https
+-                resources: {}
++                resources:
++                  requests:
++                    cpu: 10m
++                    memory: 64Mi
+               - args:
+                 - --health-probe-bind-address=:8081
+                 - --metrics-bind-address=127.0.0.1:8080
+@@ -148,6 +150,10 @@
+                   periodSeconds: 10
+                 securityContext:
+                   allowPrivilegeEscalation: false
++                resources:
++                  requests:
++                    cpu: 10m
++                    memory: 64Mi
+               securityContext:
+                 runAsNonRoot: true
+               serviceAccountName: memcached-operator-controller-manager
+@@ -245,4 +251,9 @@
+     targetPort: 9443
+     type: MutatingAdmissionWebhook
+     webhookPath: /mutate-cache-example-com-v1alpha1-memcached
++  relatedImages:
++  - name: kube-rbac-proxy
++    image: gcr.io/kubebuilder/kube-rbac-proxy:v0.8.0
++  - name: manager
++    image: quay.io/example/memcached-operator:v0.0.1
+ 
+`, changes[0].Diff())
+
+	// The properties of the olm.properties annotation are moved to a new
+	// metadata/properties.yaml file.
+	require.Equal(t, propertiesPath, changes[1].Path)
+	require.Nil(t, changes[1].Before)
+	require.Equal(t, "properties:\n- type: olm.maxOpenShiftVersion\n  value: \"4.8\"\n", string(changes[1].After))
+	require.Contains(t, changes[1].Diff(), "--- /dev/null\n")
+
+	before, err := os.ReadFile(csvPath)
+	require.NoError(t, err)
+	require.Equal(t, before, changes[0].Before, "Plan must not write the changes")
+	require.NoFileExists(t, propertiesPath)
+
+	require.NoError(t, Write(changes))
+	for _, change := range changes {
+		after, err := os.ReadFile(change.Path)
+		require.NoError(t, err)
+		require.Equal(t, change.After, after)
+	}
+	require.Empty(t, Fixes(validate(t, dir)))
+}
+
+func TestPlanErrors(t *testing.T) {
+	dir := copyDir(t, "./testdata/bundle")
+	fixes := []errors.Fix{
+		{
+			Description: "rename a missing annotation",
+			Kind:        "ClusterServiceVersion",
+			Name:        "memcached-operator.v0.0.1",
+			Patch:       []errors.PatchOperation{{Op: errors.PatchMove, From: "/metadata/annotations/missing", Path: "/metadata/annotations/found"}},
+		},
+		{
+			Description: "set the capabilities",
+			Kind:        "ClusterServiceVersion",
+			Name:        "memcached-operator.v0.0.1",
+			Patch:       []errors.PatchOperation{{Op: errors.PatchReplace, Path: "/metadata/annotations/capabilities", Value: "Seamless Upgrades"}},
+		},
+		{
+			Description: "patch a missing object",
+			Kind:        "ClusterServiceVersion",
+			Name:        "missing",
+			Patch:       []errors.PatchOperation{{Op: errors.PatchRemove, Path: "/spec"}},
+		},
+		{
+			Description: "set the display name",
+			Kind:        "ClusterServiceVersion",
+			Name:        "memcached-operator.v0.0.1",
+			Patch:       []errors.PatchOperation{{Op: errors.PatchReplace, Path: "/spec/displayName", Value: "Memcached"}},
+			Also: []errors.Fix{{
+				Description: "remove a missing property",
+				File:        "metadata/properties.yaml",
+				Patch:       []errors.PatchOperation{{Op: errors.PatchRemove, Path: "/properties/0"}},
+			}},
+		},
+	}
+
+	changes, err := Plan(dir, fixes)
+	require.EqualError(t, err, `[unable to apply fix "rename a missing annotation" to `+
+		filepath.Join(dir, "manifests", "memcached-operator.clusterserviceversion.yaml")+`: move /metadata/annotations/found: key "missing" not found, `+
+		`unable to apply fix "patch a missing object": ClusterServiceVersion "missing" not found in `+dir+`, `+
+		`unable to apply fix "remove a missing property" to `+filepath.Join(dir, "metadata", "properties.yaml")+`: remove /properties/0: key "properties" not found]`)
+	require.Len(t, changes, 1)
+	require.Equal(t, fixes[1:2], changes[0].Fixes)
+	require.Contains(t, changes[0].Diff(), "+    capabilities: Seamless Upgrades\n")
+	require.NotContains(t, changes[0].Diff(), "displayName", "fixes are applied with their Also fixes or not at all")
+}
+
+func TestPlanKeepsUntouchedLines(t *testing.T) {
+	// The formatting of the manifest is not the one of any of the encoders.
+	const before = `# A ClusterServiceVersion formatted by hand.
+apiVersion:   operators.coreos.com/v1alpha1
+kind: "ClusterServiceVersion"
+metadata:
+    annotations:
+        capabilities: 'Basic Install'
+        olm.skiprange: "<0.0.1"   # the range of the replaced versions
+    name: memcached-operator.v0.0.1
+spec:
+    description: A long description which the encoders would fold, because it is written on a single line of more than eighty characters.
+    install:
+        spec:
+            deployments:
+                -   name: memcached-operator-controller-manager
+                    spec:
+                        template:
+                            spec:
+                                containers:
+                                    -   image: quay.io/example/memcached-operator:v0.0.1
+                                        name: manager
+                                        resources: {}
+                                        args: [--leader-elect, "--metrics-bind-address=:8080"]
+
+    keywords: ["memcached", 'cache']
+---
+# Another object of the file.
+apiVersion: v1
+kind:   ServiceAccount
+metadata: {name: memcached-operator-controller-manager}
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "memcached-operator.clusterserviceversion.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(before), 0644))
+
+	fixes := []errors.Fix{
+		{
+			Description: "rename the skip range annotation",
+			Kind:        "ClusterServiceVersion",
+			Name:        "memcached-operator.v0.0.1",
+			Patch:       []errors.PatchOperation{{Op: errors.PatchMove, From: "/metadata/annotations/olm.skiprange", Path: "/metadata/annotations/olm.skipRange"}},
+		},
+		{
+			Description: "set the resource requests of the manager",
+			Kind:        "ClusterServiceVersion",
+			Name:        "memcached-operator.v0.0.1",
+			Patch: []errors.PatchOperation{{
+				Op:    errors.PatchAdd,
+				Path:  "/spec/install/spec/deployments/0/spec/template/spec/containers/0/resources/requests",
+				Value: map[string]string{"cpu": "10m"},
+			}},
+		},
+		{
+			Description: "add the related images",
+			Kind:        "ClusterServiceVersion",
+			Name:        "memcached-operator.v0.0.1",
+			Patch: []errors.PatchOperation{{
+				Op:    errors.PatchAdd,
+				Path:  "/spec/relatedImages",
+				Value: []map[string]string{{"name": "manager", "image": "quay.io/example/memcached-operator:v0.0.1"}},
+			}},
+		},
+	}
+	changes, err := Plan(dir, fixes)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+
+	// Only the lines of the patched entries are changed.
+	want := strings.NewReplacer(
+		"        olm.skiprange: \"<0.0.1\"   # the range of the replaced versions\n",
+		"        olm.skipRange: \"<0.0.1\" # the range of the replaced versions\n",
+		"                                        resources: {}\n",
+		"                                        resources:\n"+
+			"                                            requests:\n"+
+			"                                                cpu: 10m\n",
+		"    keywords: [\"memcached\", 'cache']\n",
+		"    keywords: [\"memcached\", 'cache']\n"+
+			"    relatedImages:\n"+
+			"        - image: quay.io/example/memcached-operator:v0.0.1\n"+
+			"          name: manager\n",
+	).Replace(before)
+	require.Equal(t, want, string(changes[0].After))
+}
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		doc           string
+		ops           []errors.PatchOperation
+		createParents bool
+		want          string
+		wantErr       string
+	}{
+		{
+			name: "add to a flow mapping",
+			doc:  "spec:\n  resources: {}\n",
+			ops:  []errors.PatchOperation{{Op: errors.PatchAdd, Path: "/spec/resources/requests", Value: map[string]string{"cpu": "10m"}}},
+			want: "spec:\n  resources:\n    requests:\n      cpu: 10m\n",
+		},
+		{
+			name: "add to a sequence",
+			doc:  "items:\n- a\n- c\n",
+			ops: []errors.PatchOperation{
+				{Op: errors.PatchAdd, Path: "/items/1", Value: "b"},
+				{Op: errors.PatchAdd, Path: "/items/-", Value: "d"},
+			},
+			want: "items:\n- a\n- b\n- c\n- d\n",
+		},
+		{
+			name: "escaped keys",
+			doc:  "metadata:\n  annotations:\n    a/b: x\n    c~d: y\n",
+			ops: []errors.PatchOperation{
+				{Op: errors.PatchRemove, Path: "/metadata/annotations/a~1b"},
+				{Op: errors.PatchReplace, Path: "/metadata/annotations/c~0d", Value: "true"},
+			},
+			want: "metadata:\n  annotations:\n    c~d: \"true\"\n",
+		},
+		{
+			name: "move keeps the position and comments",
+			doc:  "metadata:\n  annotations:\n    # skip range\n    olm.skiprange: <1.0.0\n    other: x\n",
+			ops:  []errors.PatchOperation{{Op: errors.PatchMove, From: "/metadata/annotations/olm.skiprange", Path: "/metadata/annotations/olm.skipRange"}},
+			want: "metadata:\n  annotations:\n    # skip range\n    olm.skipRange: <1.0.0\n    other: x\n",
+		},
+		{
+			name:          "add creating the parents",
+			doc:           "metadata: {}\n",
+			ops:           []errors.PatchOperation{{Op: errors.PatchAdd, Path: "/properties/-", Value: map[string]string{"type": "olm.maxOpenShiftVersion", "value": "4.8"}}},
+			createParents: true,
+			want:          "metadata: {}\nproperties:\n- type: olm.maxOpenShiftVersion\n  value: \"4.8\"\n",
+		},
+		{
+			name:    "add without the parents",
+			doc:     "metadata: {}\n",
+			ops:     []errors.PatchOperation{{Op: errors.PatchAdd, Path: "/properties/-", Value: "x"}},
+			wantErr: `add /properties/-: key "properties" not found`,
+		},
+		{
+			name:    "failed patches are not applied",
+			doc:     "items:\n- a\n",
+			ops:     []errors.PatchOperation{{Op: errors.PatchRemove, Path: "/items/0"}, {Op: errors.PatchRemove, Path: "/items/1"}},
+			wantErr: `remove /items/1: invalid index "1"`,
+		},
+		{
+			name:    "unsupported operation",
+			doc:     "items: []\n",
+			ops:     []errors.PatchOperation{{Op: "test", Path: "/items"}},
+			wantErr: `test /items: unsupported operation "test"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := decode([]byte(tt.doc))
+			require.NoError(t, err)
+			err = applyPatch(docs[0], tt.ops, tt.createParents)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				tt.want = tt.doc
+			} else {
+				require.NoError(t, err)
+			}
+			got, err := encode(docs, []byte(tt.doc))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package fix
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/validation/errors"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// applyPatch applies ops to the YAML document node doc. The node is left
+// unchanged if any operation fails. If createParents is true, add operations
+// create the missing mappings and sequences of their path.
+func applyPatch(doc *yaml.Node, ops []errors.PatchOperation, createParents bool) error {
+	patched := deepCopy(doc)
+	for _, op := range ops {
+		if op.Op == errors.PatchAdd && createParents {
+			if err := ensureParents(patched, op.Path); err != nil {
+				return fmt.Errorf("%s %s: %v", op.Op, op.Path, err)
+			}
+		}
+		if err := applyOperation(patched, op); err != nil {
+			return fmt.Errorf("%s %s: %v", op.Op, op.Path, err)
+		}
+	}
+	*doc = *patched
+	return nil
+}
+
+func applyOperation(doc *yaml.Node, op errors.PatchOperation) error {
+	switch op.Op {
+	case errors.PatchAdd:
+		value, err := valueNode(op.Value)
+		if err != nil {
+			return err
+		}
+		return add(doc, op.Path, value)
+	case errors.PatchRemove:
+		_, err := remove(doc, op.Path)
+		return err
+	case errors.PatchReplace:
+		value, err := valueNode(op.Value)
+		if err != nil {
+			return err
+		}
+		if _, err := remove(doc, op.Path); err != nil {
+			return err
+		}
+		return add(doc, op.Path, value)
+	case errors.PatchMove:
+		if op.From == op.Path {
+			return nil
+		}
+		// Renaming a mapping key keeps the entry in place.
+		fromParent, fromKey, err := parent(doc, op.From)
+		if err != nil {
+			return err
+		}
+		toParent, toKey, err := parent(doc, op.Path)
+		if err != nil {
+			return err
+		}
+		if fromParent == toParent && fromParent.Kind == yaml.MappingNode && mappingIndex(fromParent, toKey) < 0 {
+			i := mappingIndex(fromParent, fromKey)
+			if i < 0 {
+				return fmt.Errorf("key %q not found", fromKey)
+			}
+			fromParent.Content[i].Value = toKey
+			return nil
+		}
+		value, err := remove(doc, op.From)
+		if err != nil {
+			return err
+		}
+		return add(doc, op.Path, value)
+	}
+	return fmt.Errorf("unsupported operation %q", op.Op)
+}
+
+// add sets the value at path, which parent must exist. Values are inserted
+// into sequences, or appended with the "-" index.
+func add(doc *yaml.Node, path string, value *yaml.Node) error {
+	p, key, err := parent(doc, path)
+	if err != nil {
+		return err
+	}
+	switch p.Kind {
+	case yaml.MappingNode:
+		if i := mappingIndex(p, key); i >= 0 {
+			p.Content[i+1] = value
+			return nil
+		}
+		p.Style &^= yaml.FlowStyle
+		p.Content = append(p.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+		return nil
+	case yaml.SequenceNode:
+		i := len(p.Content)
+		if key != "-" {
+			if i, err = sequenceIndex(key, len(p.Content)); err != nil {
+				return err
+			}
+		}
+		p.Style &^= yaml.FlowStyle
+		p.Content = append(p.Content[:i], append([]*yaml.Node{value}, p.Content[i:]...)...)
+		return nil
+	}
+	return fmt.Errorf("cannot add %q to a scalar", key)
+}
+
+// ensureParents adds the missing mappings and sequences holding the value at
+// path: a sequence if the token following them is an index, or a mapping.
+func ensureParents(doc *yaml.Node, path string) error {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return err
+	}
+	node := doc
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for i := 0; i+1 < len(tokens); i++ {
+		if node.Kind != yaml.MappingNode {
+			// Existing sequences and scalars are left to add to report.
+			return nil
+		}
+		j := mappingIndex(node, tokens[i])
+		if j < 0 {
+			child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			if next := tokens[i+1]; next == "-" || strings.Trim(next, "0123456789") == "" {
+				child = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			}
+			node.Style &^= yaml.FlowStyle
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: tokens[i]}, child)
+			j = len(node.Content) - 2
+		}
+		node = node.Content[j+1]
+		for node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+	}
+	return nil
+}
+
+// remove deletes the value at path, which must exist, and returns it.
+func remove(doc *yaml.Node, path string) (*yaml.Node, error) {
+	p, key, err := parent(doc, path)
+	if err != nil {
+		return nil, err
+	}
+	switch p.Kind {
+	case yaml.MappingNode:
+		i := mappingIndex(p, key)
+		if i < 0 {
+			return nil, fmt.Errorf("key %q not found", key)
+		}
+		value := p.Content[i+1]
+		p.Content = append(p.Content[:i], p.Content[i+2:]...)
+		return value, nil
+	case yaml.SequenceNode:
+		i, err := sequenceIndex(key, len(p.Content)-1)
+		if err != nil {
+			return nil, err
+		}
+		value := p.Content[i]
+		p.Content = append(p.Content[:i], p.Content[i+1:]...)
+		return value, nil
+	}
+	return nil, fmt.Errorf("cannot remove %q from a scalar", key)
+}
+
+// parent returns the node holding the value at path, along with the last
+// token of path.
+func parent(doc *yaml.Node, path string) (*yaml.Node, string, error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(tokens) == 0 {
+		return nil, "", fmt.Errorf("the document root cannot be patched")
+	}
+	node := doc
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for _, token := range tokens[:len(tokens)-1] {
+		switch node.Kind {
+		case yaml.MappingNode:
+			i := mappingIndex(node, token)
+			if i < 0 {
+				return nil, "", fmt.Errorf("key %q not found", token)
+			}
+			node = node.Content[i+1]
+		case yaml.SequenceNode:
+			i, err := sequenceIndex(token, len(node.Content)-1)
+			if err != nil {
+				return nil, "", err
+			}
+			node = node.Content[i]
+		default:
+			return nil, "", fmt.Errorf("%q is not found in a scalar", token)
+		}
+		for node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+	}
+	return node, tokens[len(tokens)-1], nil
+}
+
+// parsePointer returns the unescaped tokens of the JSON pointer path.
+func parsePointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", path)
+	}
+	tokens := strings.Split(path[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// mappingIndex returns the index of key in the content of the mapping node,
+// or -1 if it is not found.
+func mappingIndex(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func sequenceIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid index %q", token)
+	}
+	return i, nil
+}
+
+// valueNode returns value as a block style YAML node.
+func valueNode(value interface{}) (*yaml.Node, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	node := doc.Content[0]
+	resetStyle(node)
+	return node, nil
+}
+
+// resetStyle removes the flow and quoting styles of the JSON decoded node,
+// leaving the encoder to pick them.
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, n := range node.Content {
+		resetStyle(n)
+	}
+}
+
+func deepCopy(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	n := *node
+	n.Content = make([]*yaml.Node, len(node.Content))
+	for i, c := range node.Content {
+		n.Content[i] = deepCopy(c)
+	}
+	return &n
+}
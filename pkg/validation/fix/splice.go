@@ -0,0 +1,403 @@
+package fix
+
+import (
+	"io"
+	"reflect"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// splicer rewrites the entries of the documents of a manifest which were
+// changed by the fixes, and keeps the other lines of the manifest byte for
+// byte. The entries of block mappings and sequences are located with the line
+// and column of their nodes: an entry spans the lines from its key, or the
+// dash of a sequence item, to the next entry of its collection, without the
+// blank lines and comments preceding it.
+type splicer struct {
+	data  []byte
+	lines []string
+	// starts are the offsets of the lines in data, followed by len(data).
+	starts []int
+	style  func(io.Writer) *yaml.Encoder
+	edits  []edit
+}
+
+// edit replaces the bytes [from, to) of the manifest with text.
+type edit struct {
+	from, to int
+	text     string
+}
+
+// splice returns original, the contents originals were decoded from, with the
+// changes of docs. It returns false if the changes cannot be located in the
+// lines of original, and the documents must be encoded as a whole.
+func splice(original []byte, originals, docs []*yaml.Node, style func(io.Writer) *yaml.Encoder) ([]byte, bool, error) {
+	if len(originals) != len(docs) {
+		return nil, false, nil
+	}
+	s := &splicer{data: original, lines: strings.SplitAfter(string(original), "\n"), style: style}
+	offset := 0
+	for _, line := range s.lines {
+		s.starts = append(s.starts, offset)
+		offset += len(line)
+	}
+	s.starts = append(s.starts, offset)
+
+	for i := range docs {
+		if equal(originals[i], docs[i]) {
+			continue
+		}
+		end := len(s.lines)
+		for _, next := range originals[i+1:] {
+			if root := rootNode(next); root != nil {
+				end = root.Line - 1
+				break
+			}
+		}
+		ok, err := s.collection(rootNode(originals[i]), rootNode(docs[i]), end)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+	}
+
+	buf := &strings.Builder{}
+	pos := 0
+	for _, e := range s.edits {
+		if e.from < pos {
+			return nil, false, nil
+		}
+		buf.WriteString(string(original[pos:e.from]))
+		buf.WriteString(e.text)
+		pos = e.to
+	}
+	buf.WriteString(string(original[pos:]))
+	spliced := []byte(buf.String())
+
+	// The spliced contents must hold the same objects as docs.
+	decoded, err := decode(spliced)
+	if err != nil || len(decoded) != len(docs) {
+		return nil, false, nil
+	}
+	for i := range docs {
+		var want, got interface{}
+		if docs[i].Decode(&want) != nil || decoded[i].Decode(&got) != nil || !reflect.DeepEqual(want, got) {
+			return nil, false, nil
+		}
+	}
+	return spliced, true, nil
+}
+
+// collection adds the edits changing the entries of the block mapping or
+// sequence orig to the ones of patched. The entries of orig end before the
+// line end. It returns false if the changes cannot be spliced, leaving the
+// edits of the entries to the caller.
+func (s *splicer) collection(orig, patched *yaml.Node, end int) (bool, error) {
+	if orig == nil || patched == nil || !isBlock(orig) || !isBlock(patched) || orig.Kind != patched.Kind || !shallowEqual(orig, patched) {
+		return false, nil
+	}
+	if orig.Kind == yaml.MappingNode {
+		return s.mapping(orig, patched, end)
+	}
+	return s.sequence(orig, patched, end)
+}
+
+// mapping adds the edits of the entries of the block mappings orig and
+// patched, matched by their keys.
+func (s *splicer) mapping(orig, patched *yaml.Node, end int) (bool, error) {
+	var starts, indents []int
+	for i := 0; i < len(orig.Content); i += 2 {
+		starts = append(starts, orig.Content[i].Line-1)
+		indents = append(indents, orig.Content[i].Column-1)
+	}
+	ends := s.ends(starts, indents, end)
+
+	if _, ok := keyIndex(orig); !ok {
+		return false, nil
+	}
+	index, ok := keyIndex(patched)
+	if !ok {
+		return false, nil
+	}
+
+	next := 0
+	for i := 0; i < len(orig.Content); i += 2 {
+		n := i / 2
+		j, ok := index[orig.Content[i].Value]
+		if !ok {
+			if !s.remove(starts[n], ends[n], indents[n]) {
+				return false, nil
+			}
+			continue
+		}
+		if j < next {
+			// The keys were reordered.
+			return false, nil
+		}
+		if !s.insert(s.insertionLine(n, starts, ends, indents), indents[n], patched.Content[next:j], true) {
+			return false, nil
+		}
+		next = j + 2
+		if ok, err := s.entry(orig.Content[i], orig.Content[i+1], patched.Content[j], patched.Content[j+1], starts[n], ends[n], indents[n]); err != nil || !ok {
+			return ok, err
+		}
+	}
+	last := len(starts) - 1
+	return s.insert(ends[last], indents[last], patched.Content[next:], true), nil
+}
+
+// sequence adds the edits of the items of the block sequences orig and
+// patched, which are matched pairwise if they have the same length, or
+// replaced between their common prefix and suffix otherwise.
+func (s *splicer) sequence(orig, patched *yaml.Node, end int) (bool, error) {
+	var starts, indents []int
+	for _, item := range orig.Content {
+		start := item.Line - 1
+		if start < 0 || start >= len(s.lines) {
+			return false, nil
+		}
+		// The indentation of an item is the one of its dash.
+		line := s.lines[start]
+		if item.Column-1 > len(line) {
+			return false, nil
+		}
+		prefix := strings.TrimRight(line[:item.Column-1], " ")
+		if !strings.HasSuffix(prefix, "-") {
+			return false, nil
+		}
+		starts = append(starts, start)
+		indents = append(indents, len(prefix)-1)
+	}
+	ends := s.ends(starts, indents, end)
+
+	n, m := len(orig.Content), len(patched.Content)
+	if n == m {
+		for i := range orig.Content {
+			if ok, err := s.entry(nil, orig.Content[i], nil, patched.Content[i], starts[i], ends[i], indents[i]); err != nil || !ok {
+				return ok, err
+			}
+		}
+		return true, nil
+	}
+
+	prefix := 0
+	for prefix < n && prefix < m && equal(orig.Content[prefix], patched.Content[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < n-prefix && suffix < m-prefix && equal(orig.Content[n-1-suffix], patched.Content[m-1-suffix]) {
+		suffix++
+	}
+	at, indent := ends[n-1], indents[n-1]
+	if prefix < n {
+		at, indent = s.insertionLine(prefix, starts, ends, indents), indents[prefix]
+	}
+	if !s.insert(at, indent, patched.Content[prefix:m-suffix], false) {
+		return false, nil
+	}
+	for i := prefix; i < n-suffix; i++ {
+		if !s.remove(starts[i], ends[i], indents[i]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// entry adds the edits changing the entry of a mapping, or the item of a
+// sequence if the keys are nil, spanning the lines [start, end). The entries
+// of a block value are spliced, and other values are rewritten as a whole.
+func (s *splicer) entry(origKey, origValue, patchedKey, patchedValue *yaml.Node, start, end, indent int) (bool, error) {
+	if equal(origKey, patchedKey) {
+		if equal(origValue, patchedValue) {
+			return true, nil
+		}
+		edits := len(s.edits)
+		ok, err := s.collection(origValue, patchedValue, end)
+		if err != nil || ok {
+			return ok, err
+		}
+		s.edits = s.edits[:edits]
+	}
+
+	// The line is kept up to the entry, which may follow the dash of a
+	// sequence item.
+	if !s.hasPrefix(start, indent, " -") {
+		return false, nil
+	}
+	text, err := s.render(patchedKey, patchedValue, indent)
+	if err != nil {
+		return false, err
+	}
+	s.edits = append(s.edits, edit{from: s.starts[start] + indent, to: s.starts[end], text: text})
+	return true, nil
+}
+
+// insert adds the edit inserting the entries of a mapping, as key and value
+// pairs, or the items of a sequence at the start of the line at.
+func (s *splicer) insert(at, indent int, nodes []*yaml.Node, pairs bool) bool {
+	if len(nodes) == 0 {
+		return true
+	}
+	if at < 0 {
+		return false
+	}
+	text := ""
+	if at == len(s.lines) && len(s.data) > 0 && s.data[len(s.data)-1] != '\n' {
+		text = "\n"
+	}
+	for i := 0; i < len(nodes); i++ {
+		var key *yaml.Node
+		if pairs {
+			key = nodes[i]
+			i++
+		}
+		rendered, err := s.render(key, nodes[i], indent)
+		if err != nil {
+			return false
+		}
+		text += strings.Repeat(" ", indent) + rendered
+	}
+	s.edits = append(s.edits, edit{from: s.starts[at], to: s.starts[at], text: text})
+	return true
+}
+
+// remove adds the edit removing the lines [start, end) of an entry, which
+// must not share its first line with its parent.
+func (s *splicer) remove(start, end, indent int) bool {
+	if !s.hasPrefix(start, indent, " ") {
+		return false
+	}
+	s.edits = append(s.edits, edit{from: s.starts[start], to: s.starts[end]})
+	return true
+}
+
+// insertionLine returns the line the entries inserted before the entry i are
+// inserted at: the end of the previous entry, or the line of the first one if
+// it does not share it with its parent, or -1.
+func (s *splicer) insertionLine(i int, starts, ends, indents []int) int {
+	if i > 0 {
+		return ends[i-1]
+	}
+	if !s.hasPrefix(starts[0], indents[0], " ") {
+		return -1
+	}
+	return starts[0]
+}
+
+// ends returns the lines ending the entries starting at the lines starts: the
+// start of the next entry, or end for the last one, before the blank lines
+// and the comments which are not indented more than the entry.
+func (s *splicer) ends(starts, indents []int, end int) []int {
+	ends := make([]int, len(starts))
+	for i := range starts {
+		e := end
+		if i+1 < len(starts) {
+			e = starts[i+1]
+		}
+		for e > starts[i]+1 && trailing(s.lines[e-1], indents[i]) {
+			e--
+		}
+		ends[i] = e
+	}
+	return ends
+}
+
+// hasPrefix returns true if the line consists of characters of chars up to
+// indent.
+func (s *splicer) hasPrefix(line, indent int, chars string) bool {
+	return line >= 0 && line < len(s.lines) && indent <= len(s.lines[line]) && strings.Trim(s.lines[line][:indent], chars) == ""
+}
+
+// render returns the entry of a mapping, or the item of a sequence if key is
+// nil, encoded with the style of the manifest and indented by indent, but for
+// its first line. Comments preceding the entry are kept in the manifest.
+func (s *splicer) render(key, value *yaml.Node, indent int) (string, error) {
+	var node *yaml.Node
+	if key != nil {
+		k := *key
+		k.HeadComment, k.FootComment = "", ""
+		node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{&k, value}}
+	} else {
+		v := *value
+		v.HeadComment, v.FootComment = "", ""
+		node = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{&v}}
+	}
+	data, err := encodeWith(s.style, []*yaml.Node{node})
+	if err != nil {
+		return "", err
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" && lines[i] != "\n" {
+			lines[i] = strings.Repeat(" ", indent) + lines[i]
+		}
+	}
+	return strings.Join(lines, ""), nil
+}
+
+// trailing returns true if line is blank, a document marker, or a comment not
+// indented more than indent, which cannot be part of the value of an entry
+// indented by indent.
+func trailing(line string, indent int) bool {
+	content := strings.TrimLeft(line, " ")
+	if strings.TrimSpace(content) == "" {
+		return true
+	}
+	lineIndent := len(line) - len(content)
+	if lineIndent > indent {
+		return false
+	}
+	return strings.HasPrefix(content, "#") || (lineIndent == 0 && (strings.HasPrefix(content, "---") || strings.HasPrefix(content, "...")))
+}
+
+// keyIndex returns the indexes of the keys in the content of the mapping node,
+// or false if they are not distinct scalars.
+func keyIndex(node *yaml.Node) (map[string]int, bool) {
+	index := map[string]int{}
+	for i := 0; i < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if _, ok := index[key.Value]; ok || key.Kind != yaml.ScalarNode {
+			return nil, false
+		}
+		index[key.Value] = i
+	}
+	return index, true
+}
+
+func rootNode(doc *yaml.Node) *yaml.Node {
+	if doc.Kind == yaml.DocumentNode {
+		if len(doc.Content) == 0 {
+			return nil
+		}
+		return doc.Content[0]
+	}
+	return doc
+}
+
+// isBlock returns true if node is a block mapping or sequence with entries.
+func isBlock(node *yaml.Node) bool {
+	return (node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode) && node.Style&yaml.FlowStyle == 0 && len(node.Content) > 0
+}
+
+// shallowEqual returns true if a and b are equal, but for their position and
+// content.
+func shallowEqual(a, b *yaml.Node) bool {
+	return a.Kind == b.Kind && a.Style == b.Style && a.Tag == b.Tag && a.Value == b.Value && a.Anchor == b.Anchor &&
+		a.HeadComment == b.HeadComment && a.LineComment == b.LineComment && a.FootComment == b.FootComment
+}
+
+// equal returns true if a and b are equal, but for their positions.
+func equal(a, b *yaml.Node) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if !shallowEqual(a, b) || len(a.Content) != len(b.Content) {
+		return false
+	}
+	for i := range a.Content {
+		if !equal(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
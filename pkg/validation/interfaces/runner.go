@@ -0,0 +1,70 @@
+package validator
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/operator-framework/api/pkg/validation/errors"
+)
+
+// Runner runs Validators over many sets of objects, such as the objects of
+// every bundle in a catalog, with a bounded pool of workers. Each validator is
+// run concurrently on each set of objects, so validators must not modify the
+// objects they validate.
+type Runner struct {
+	// Validators are run on every set of objects.
+	Validators Validators
+	// Workers is the maximum number of validators run at the same time.
+	// If zero or negative, runtime.GOMAXPROCS(0) is used.
+	Workers int
+}
+
+// Run validates each set of objects with the validators of r and returns the
+// results of each set, in the order of sets. Results of a set are in the same
+// order as if Validators.ValidateContext were called on it, regardless of the
+// order in which the validators complete.
+//
+// Once ctx is done, the validators not started yet are not run, so callers
+// should check ctx.Err() to know whether validation completed.
+func (r Runner) Run(ctx context.Context, sets ...[]interface{}) [][]errors.ManifestResult {
+	// Each job is identified by its index in results, flattened by set then validator.
+	jobs := len(sets) * len(r.Validators)
+	results := make([][]errors.ManifestResult, jobs)
+
+	workers := r.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > jobs {
+		workers = jobs
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if ctx.Err() != nil {
+					continue
+				}
+				set, validator := sets[i/len(r.Validators)], r.Validators[i%len(r.Validators)]
+				results[i] = WithContext(validator).ValidateContext(ctx, set...)
+			}
+		}()
+	}
+	for i := 0; i < jobs; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	setResults := make([][]errors.ManifestResult, len(sets))
+	for i, result := range results {
+		set := i / len(r.Validators)
+		setResults[set] = append(setResults[set], result...)
+	}
+	return setResults
+}
@@ -0,0 +1,67 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/operator-framework/api/pkg/validation/errors"
+
+	"github.com/stretchr/testify/require"
+)
+
+// namingValidator returns a result named after itself and each object, after
+// a delay making later validators complete first.
+func namingValidator(name string, delay time.Duration, running, maxRunning *int32) Validator {
+	return ValidatorFunc(func(objs ...interface{}) (results []errors.ManifestResult) {
+		n := atomic.AddInt32(running, 1)
+		defer atomic.AddInt32(running, -1)
+		for {
+			max := atomic.LoadInt32(maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(maxRunning, max, n) {
+				break
+			}
+		}
+		time.Sleep(delay)
+		for _, obj := range objs {
+			results = append(results, errors.ManifestResult{Name: fmt.Sprintf("%s/%v", name, obj)})
+		}
+		return results
+	})
+}
+
+func TestRunnerRun(t *testing.T) {
+	var running, maxRunning int32
+	validators := Validators{
+		namingValidator("a", 30*time.Millisecond, &running, &maxRunning),
+		namingValidator("b", 20*time.Millisecond, &running, &maxRunning),
+		namingValidator("c", 10*time.Millisecond, &running, &maxRunning),
+	}
+	sets := [][]interface{}{{"bundle-1", "options"}, {"bundle-2"}, {}, {"bundle-3"}}
+
+	results := Runner{Validators: validators, Workers: 2}.Run(context.Background(), sets...)
+	require.LessOrEqual(t, maxRunning, int32(2))
+	require.Len(t, results, len(sets))
+	for i, set := range sets {
+		require.Equal(t, validators.Validate(set...), results[i])
+	}
+}
+
+func TestRunnerRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var running, maxRunning int32
+	results := Runner{
+		Validators: Validators{namingValidator("a", 0, &running, &maxRunning)},
+	}.Run(ctx, []interface{}{"bundle-1"}, []interface{}{"bundle-2"})
+	require.Equal(t, [][]errors.ManifestResult{nil, nil}, results)
+	require.Zero(t, maxRunning)
+}
+
+func TestRunnerRunEmpty(t *testing.T) {
+	require.Empty(t, Runner{}.Run(context.Background()))
+	require.Equal(t, [][]errors.ManifestResult{nil}, Runner{}.Run(context.Background(), []interface{}{"bundle-1"}))
+}
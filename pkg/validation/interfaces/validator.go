@@ -1,6 +1,8 @@
 package validator
 
 import (
+	"context"
+
 	"github.com/operator-framework/api/pkg/validation/errors"
 )
 
@@ -14,6 +16,16 @@ type Validator interface {
 	WithValidators(...Validator) Validators
 }
 
+// ContextValidator is a Validator which accepts a context.Context, passing its
+// deadline and cancellation down to every check it runs.
+type ContextValidator interface {
+	Validator
+	// ValidateContext is like Validate, but stops validating once ctx is done.
+	// Results are only returned for the objects validated until then, so
+	// callers should check ctx.Err() to know whether validation completed.
+	ValidateContext(context.Context, ...interface{}) []errors.ManifestResult
+}
+
 // ValidatorFunc implements Validator. ValidatorFunc can be used as a wrapper
 // for functions that run object validators.
 type ValidatorFunc func(...interface{}) []errors.ManifestResult
@@ -28,6 +40,46 @@ func (f ValidatorFunc) WithValidators(vals ...Validator) Validators {
 	return append(vals, f)
 }
 
+// ContextValidatorFunc implements ContextValidator. ContextValidatorFunc can be
+// used as a wrapper for functions that run object validators with a context.
+type ContextValidatorFunc func(context.Context, ...interface{}) []errors.ManifestResult
+
+// ValidateContext runs the ContextValidatorFunc on objs with ctx.
+func (f ContextValidatorFunc) ValidateContext(ctx context.Context, objs ...interface{}) []errors.ManifestResult {
+	return f(ctx, objs...)
+}
+
+// Validate runs the ContextValidatorFunc on objs with a background context.
+func (f ContextValidatorFunc) Validate(objs ...interface{}) []errors.ManifestResult {
+	return f(context.Background(), objs...)
+}
+
+// WithValidators appends the ContextValidatorFunc to vals.
+func (f ContextValidatorFunc) WithValidators(vals ...Validator) Validators {
+	return append(vals, f)
+}
+
+// WithContext returns v as a ContextValidator. Validators which are not
+// context-aware are adapted to only run if the context is not done yet.
+func WithContext(v Validator) ContextValidator {
+	if cv, ok := v.(ContextValidator); ok {
+		return cv
+	}
+	return contextValidator{v}
+}
+
+// contextValidator adapts a Validator which does not accept a context.
+type contextValidator struct {
+	Validator
+}
+
+func (v contextValidator) ValidateContext(ctx context.Context, objs ...interface{}) []errors.ManifestResult {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return v.Validate(objs...)
+}
+
 // Validators is a set of Validator's that implements Validate.
 type Validators []Validator
 
@@ -40,6 +92,18 @@ func (validators Validators) Validate(objs ...interface{}) (results []errors.Man
 	return results
 }
 
+// ValidateContext invokes each Validator in Validators with ctx, collecting
+// and returning the results. Validators left once ctx is done are not run.
+func (validators Validators) ValidateContext(ctx context.Context, objs ...interface{}) (results []errors.ManifestResult) {
+	for _, validator := range validators {
+		if ctx.Err() != nil {
+			break
+		}
+		results = append(results, WithContext(validator).ValidateContext(ctx, objs...)...)
+	}
+	return results
+}
+
 // WithValidators appends vals to Validators.
 func (validators Validators) WithValidators(vals ...Validator) Validators {
 	return append(vals, validators...)
@@ -0,0 +1,62 @@
+package validator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/validation/errors"
+
+	"github.com/stretchr/testify/require"
+)
+
+func resultFor(name string) []errors.ManifestResult {
+	return []errors.ManifestResult{{Name: name}}
+}
+
+func TestWithContext(t *testing.T) {
+	plain := ValidatorFunc(func(objs ...interface{}) []errors.ManifestResult {
+		return resultFor("plain")
+	})
+	var gotCtx context.Context
+	aware := ContextValidatorFunc(func(ctx context.Context, objs ...interface{}) []errors.ManifestResult {
+		gotCtx = ctx
+		return resultFor("aware")
+	})
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+	require.Equal(t, resultFor("plain"), WithContext(plain).ValidateContext(ctx))
+	require.Equal(t, resultFor("aware"), WithContext(aware).ValidateContext(ctx))
+	require.Equal(t, "value", gotCtx.Value(key{}))
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Empty(t, WithContext(plain).ValidateContext(cancelled))
+
+	// A ContextValidatorFunc used as a plain Validator runs with a background context.
+	require.Equal(t, resultFor("aware"), aware.Validate())
+	require.NoError(t, gotCtx.Err())
+}
+
+func TestValidatorsValidateContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	validators := Validators{
+		ValidatorFunc(func(objs ...interface{}) []errors.ManifestResult {
+			return resultFor("first")
+		}),
+		ContextValidatorFunc(func(ctx context.Context, objs ...interface{}) []errors.ManifestResult {
+			// cancels the validation, as a deadline would
+			cancel()
+			return resultFor("second")
+		}),
+		ValidatorFunc(func(objs ...interface{}) []errors.ManifestResult {
+			return resultFor("third")
+		}),
+	}
+
+	results := validators.ValidateContext(ctx)
+	require.Equal(t, append(resultFor("first"), resultFor("second")...), results)
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -38,7 +39,9 @@ used anywhere annotations need to be checked for case sensitivity.
 
 # Returns
 
-• errs: Any errors that may have been detected with the annotation keys provided
+• errs: Any errors that may have been detected with the annotation keys provided. Errors
+about the case of a key come with a Fix renaming the annotation, whose Kind and Name are left
+for the caller to set.
 */
 func ValidateAnnotationNames(annotations map[string]string, value interface{}) (errs []errors.Error) {
 	// for every annotation provided
@@ -48,7 +51,16 @@ func ValidateAnnotationNames(annotations map[string]string, value interface{}) (
 			// we have a case-insensitive match... now check to see if the case is really correct
 			if annotationKey != knownCaseSensitiveKey {
 				// annotation key supplied is invalid due to bad case.
-				errs = append(errs, errors.ErrFailedValidation(fmt.Sprintf("provided annotation %s uses wrong case and should be %s instead", annotationKey, knownCaseSensitiveKey), value))
+				errs = append(errs, errors.ErrFailedValidation(fmt.Sprintf("provided annotation %s uses wrong case and should be %s instead", annotationKey, knownCaseSensitiveKey), value).
+					WithCode(errors.RuleAnnotationCase).
+					WithFix(errors.Fix{
+						Description: fmt.Sprintf("rename annotation %s to %s", annotationKey, knownCaseSensitiveKey),
+						Patch: []errors.PatchOperation{{
+							Op:   errors.PatchMove,
+							From: errors.JSONPointer("metadata", "annotations", annotationKey),
+							Path: errors.JSONPointer("metadata", "annotations", knownCaseSensitiveKey),
+						}},
+					}))
 			}
 		}
 
@@ -59,8 +71,44 @@ func ValidateAnnotationNames(annotations map[string]string, value interface{}) (
 					fmt.Sprintf(
 						"found %s annotation, please define these properties in metadata/properties.yaml instead",
 						annotationKey,
-					)))
+					)).WithCode(errors.RuleAnnotationProperties))
 		}
 	}
 	return errs
 }
+
+// withPropertiesAnnotationFix returns errs with a Fix moving the properties of
+// the olm.properties annotation of a CSV to metadata/properties.yaml attached
+// to the warning about the annotation. Annotations which are not a JSON list
+// of properties are left to be moved by hand.
+func withPropertiesAnnotationFix(errs []errors.Error, annotations map[string]string) []errors.Error {
+	var properties []map[string]interface{}
+	if err := json.Unmarshal([]byte(annotations[olmpropertiesAnnotation]), &properties); err != nil || len(properties) == 0 {
+		return errs
+	}
+	add := make([]errors.PatchOperation, 0, len(properties))
+	for _, p := range properties {
+		if typ, ok := p["type"].(string); !ok || typ == "" {
+			return errs
+		}
+		add = append(add, errors.PatchOperation{Op: errors.PatchAdd, Path: errors.JSONPointer("properties", "-"), Value: p})
+	}
+	for i, e := range errs {
+		if e.Code != errors.RuleAnnotationProperties {
+			continue
+		}
+		errs[i] = e.WithFix(errors.Fix{
+			Description: fmt.Sprintf("move the properties of the %s annotation to metadata/properties.yaml", olmpropertiesAnnotation),
+			Patch: []errors.PatchOperation{{
+				Op:   errors.PatchRemove,
+				Path: errors.JSONPointer("metadata", "annotations", olmpropertiesAnnotation),
+			}},
+			Also: []errors.Fix{{
+				Description: fmt.Sprintf("add the properties of the %s annotation", olmpropertiesAnnotation),
+				File:        "metadata/properties.yaml",
+				Patch:       add,
+			}},
+		})
+	}
+	return errs
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -14,7 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-var BundleValidator interfaces.Validator = interfaces.ValidatorFunc(validateBundles)
+var BundleValidator interfaces.Validator = interfaces.ContextValidatorFunc(validateBundles)
 
 // max_bundle_size is the maximum size of a bundle in bytes.
 // This ensures the bundle can be staged in a single ConfigMap by OLM during installation.
@@ -22,8 +23,11 @@ var BundleValidator interfaces.Validator = interfaces.ValidatorFunc(validateBund
 // We will use this value to check the bundle compressed is < ~1MB
 const max_bundle_size = int64(1 << (10 * 2))
 
-func validateBundles(objs ...interface{}) (results []errors.ManifestResult) {
+func validateBundles(ctx context.Context, objs ...interface{}) (results []errors.ManifestResult) {
 	for _, obj := range objs {
+		if ctx.Err() != nil {
+			break
+		}
 		switch v := obj.(type) {
 		case *manifests.Bundle:
 			results = append(results, validateBundle(v))
@@ -51,9 +55,22 @@ func validateBundle(bundle *manifests.Bundle) (result errors.ManifestResult) {
 	if relatedImagesErrors != nil {
 		result.Add(relatedImagesErrors...)
 	}
+	result.Add(validateLoadWarnings(bundle)...)
+	result.Add(validateProperties(bundle)...)
 	return result
 }
 
+// validateLoadWarnings reports the problems found while loading the bundle.
+func validateLoadWarnings(bundle *manifests.Bundle) []errors.Error {
+	var errs []errors.Error
+	for _, w := range bundle.Warnings {
+		errs = append(errs, errors.WarnInvalidBundle(w.Detail, w.Position.File).
+			WithCode(errors.RuleBundleMultipleDocuments).
+			WithPosition(errors.Position{File: w.Position.File, Line: w.Position.Line, Column: w.Position.Column}))
+	}
+	return errs
+}
+
 func validateBundleName(bundle *manifests.Bundle) []errors.Error {
 	var errs []errors.Error
 	// bundle naming with a specified release version must follow the pattern
@@ -61,7 +78,7 @@ func validateBundleName(bundle *manifests.Bundle) []errors.Error {
 	if len(bundle.CSV.Spec.Release.Release) > 0 {
 		expectedName := fmt.Sprintf("%s-v%s-%s", bundle.Package, bundle.CSV.Spec.Version.String(), bundle.CSV.Spec.Release.String())
 		if bundle.Name != expectedName {
-			errs = append(errs, errors.ErrInvalidBundle(fmt.Sprintf("bundle name with release versioning %q does not match expected name %q", bundle.Name, expectedName), bundle.Name))
+			errs = append(errs, errors.ErrInvalidBundle(fmt.Sprintf("bundle name with release versioning %q does not match expected name %q", bundle.Name, expectedName), bundle.Name).WithCode(errors.RuleBundleNameMismatch))
 		}
 	}
 	return errs
@@ -76,7 +93,7 @@ func validateRelatedImages(bundle *manifests.Bundle) []errors.Error {
 		if relatedImage.Image == "" {
 			errs = append(errs, errors.ErrInvalidBundle(
 				fmt.Sprintf("relatedImages[%d] has an empty image field", i),
-				fmt.Sprintf("spec.relatedImages[%d].image", i)))
+				fmt.Sprintf("spec.relatedImages[%d].image", i)).WithCode(errors.RuleBundleRelatedImageInvalid))
 			continue
 		}
 
@@ -84,7 +101,7 @@ func validateRelatedImages(bundle *manifests.Bundle) []errors.Error {
 		if _, err := reference.ParseNormalizedNamed(relatedImage.Image); err != nil {
 			errs = append(errs, errors.ErrInvalidBundle(
 				fmt.Sprintf("relatedImages[%d] has an invalid image pullspec %q: %v", i, relatedImage.Image, err),
-				fmt.Sprintf("spec.relatedImages[%d].image", i)))
+				fmt.Sprintf("spec.relatedImages[%d].image", i)).WithCode(errors.RuleBundleRelatedImageInvalid))
 		}
 	}
 
@@ -112,7 +129,7 @@ func validateServiceAccounts(bundle *manifests.Bundle) []errors.Error {
 					"This service account %s in your bundle is not valid, because a service account with the same name "+
 					"was already specified in your CSV. If this was unintentional, please remove the service account "+
 					"manifest from your bundle. If it was intentional to specify a separate service account, "+
-					"please rename the SA in either the bundle manifest or the CSV.", sa.Name), sa.Name))
+					"please rename the SA in either the bundle manifest or the CSV.", sa.Name), sa.Name).WithCode(errors.RuleBundleDuplicateServiceAccount))
 			}
 		}
 	}
@@ -127,7 +144,7 @@ func validateOwnedCRDs(bundle *manifests.Bundle, csv *operatorsv1alpha1.ClusterS
 	keySet := make(map[schema.GroupVersionKind]struct{})
 	for _, key := range getBundleCRDKeys(bundle) {
 		if _, hasKey := keySet[key]; hasKey {
-			result.Add(errors.ErrInvalidBundle(fmt.Sprintf("duplicate CRD %q in bundle %q", key, bundle.Name), key))
+			result.Add(errors.ErrInvalidBundle(fmt.Sprintf("duplicate CRD %q in bundle %q", key, bundle.Name), key).WithCode(errors.RuleBundleDuplicateCRD))
 		}
 		// Always add key to keySet so the below validations run correctly.
 		keySet[key] = struct{}{}
@@ -137,7 +154,7 @@ func validateOwnedCRDs(bundle *manifests.Bundle, csv *operatorsv1alpha1.ClusterS
 	ownedGVSet := make(map[schema.GroupKind]struct{})
 	for _, ownedKey := range ownedKeys {
 		if _, ok := keySet[ownedKey]; !ok {
-			result.Add(errors.ErrInvalidBundle(fmt.Sprintf("owned CRD %q not found in bundle %q", ownedKey, bundle.Name), ownedKey))
+			result.Add(errors.ErrInvalidBundle(fmt.Sprintf("owned CRD %q not found in bundle %q", ownedKey, bundle.Name), ownedKey).WithCode(errors.RuleBundleOwnedCRDMissing))
 		} else {
 			delete(keySet, ownedKey)
 			gvKey := schema.GroupKind{Group: ownedKey.Group, Kind: ownedKey.Kind}
@@ -155,7 +172,7 @@ func validateOwnedCRDs(bundle *manifests.Bundle, csv *operatorsv1alpha1.ClusterS
 
 	// All CRDs present in a CSV must be present in the bundle.
 	for key := range keySet {
-		result.Add(errors.ErrInvalidBundle(fmt.Sprintf("CRD %q is present in bundle %q but not defined in CSV", key, bundle.Name), key))
+		result.Add(errors.ErrInvalidBundle(fmt.Sprintf("CRD %q is present in bundle %q but not defined in CSV", key, bundle.Name), key).WithCode(errors.RuleBundleCRDNotInCSV))
 	}
 
 	return result
@@ -184,12 +201,12 @@ func validateBundleSize(bundle *manifests.Bundle) []errors.Error {
 	var errs []errors.Error
 
 	if bundle.CompressedSize == 0 {
-		errs = append(errs, errors.WarnFailedValidation("unable to check the bundle compressed size", bundle.Name))
+		errs = append(errs, errors.WarnFailedValidation("unable to check the bundle compressed size", bundle.Name).WithCode(errors.RuleBundleSizeUnknown))
 		return errs
 	}
 
 	if bundle.Size == 0 {
-		errs = append(errs, errors.WarnFailedValidation("unable to check the bundle size", bundle.Name))
+		errs = append(errs, errors.WarnFailedValidation("unable to check the bundle size", bundle.Name).WithCode(errors.RuleBundleSizeUnknown))
 		return errs
 	}
 
@@ -202,14 +219,14 @@ func validateBundleSize(bundle *manifests.Bundle) []errors.Error {
 				formatBytesInUnit(bundle.CompressedSize),
 				formatBytesInUnit(max_bundle_size),
 				formatBytesInUnit(bundle.Size)),
-			bundle.Name))
+			bundle.Name).WithCode(errors.RuleBundleSizeExceeded))
 	} else if float64(bundle.CompressedSize) > warnSize {
 		errs = append(errs, errors.WarnInvalidBundle(
 			fmt.Sprintf("nearing maximum bundle compressed size with gzip: size=~%s , max=%s. Bundle uncompressed size is %s",
 				formatBytesInUnit(bundle.CompressedSize),
 				formatBytesInUnit(max_bundle_size),
 				formatBytesInUnit(bundle.Size)),
-			bundle.Name))
+			bundle.Name).WithCode(errors.RuleBundleSizeNearLimit))
 	}
 
 	return errs
@@ -452,3 +452,18 @@ func TestValidateRelatedImages(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateLoadWarnings(t *testing.T) {
+	bundle := &manifests.Bundle{
+		Warnings: []manifests.LoadWarning{{
+			Position: manifests.Position{File: "manifests/rbac.yaml", Line: 8, Column: 1},
+			Detail:   "RoleBinding etcd-rolebinding is not the first document of manifests/rbac.yaml",
+		}},
+	}
+	require.Equal(t, []errors.Error{
+		errors.WarnInvalidBundle("RoleBinding etcd-rolebinding is not the first document of manifests/rbac.yaml", "manifests/rbac.yaml").
+			WithCode(errors.RuleBundleMultipleDocuments).
+			WithPosition(errors.Position{File: "manifests/rbac.yaml", Line: 8, Column: 1}),
+	}, validateLoadWarnings(bundle))
+	require.Empty(t, validateLoadWarnings(&manifests.Bundle{}))
+}
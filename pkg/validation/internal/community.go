@@ -38,22 +38,14 @@ var CommunityOperatorValidator interfaces.Validator = interfaces.ValidatorFunc(c
 
 func communityValidator(objs ...interface{}) (results []errors.ManifestResult) {
 
-	// Obtain the k8s version if informed via the objects an optional
-	var indexImagePath = ""
-	for _, obj := range objs {
-		switch obj.(type) {
-		case map[string]string:
-			indexImagePath = obj.(map[string]string)[IndexImagePathKey]
-			if len(indexImagePath) > 0 {
-				break
-			}
-		}
-	}
+	// Obtain the index image path if informed via the optional values
+	opts, errs := optionsFrom(objs, IndexImagePathKey)
+	indexImagePath := opts.IndexImagePath
 
 	for _, obj := range objs {
 		switch v := obj.(type) {
 		case *manifests.Bundle:
-			results = append(results, validateCommunityBundle(v, indexImagePath))
+			results = append(results, withOptionsErrors(validateCommunityBundle(v, indexImagePath), errs))
 		}
 	}
 
@@ -71,13 +63,13 @@ type CommunityOperatorChecks struct {
 func validateCommunityBundle(bundle *manifests.Bundle, indexImagePath string) errors.ManifestResult {
 	result := errors.ManifestResult{}
 	if bundle == nil {
-		result.Add(errors.ErrInvalidBundle("Bundle is nil", nil))
+		result.Add(errors.ErrInvalidBundle("Bundle is nil", nil).WithCode(errors.RuleBundleMissing))
 		return result
 	}
 	result.Name = bundle.Name
 
 	if bundle.CSV == nil {
-		result.Add(errors.ErrInvalidBundle("Bundle csv is nil", bundle.Name))
+		result.Add(errors.ErrInvalidBundle("Bundle csv is nil", bundle.Name).WithCode(errors.RuleBundleMissing))
 		return result
 	}
 
@@ -90,10 +82,10 @@ func validateCommunityBundle(bundle *manifests.Bundle, indexImagePath string) er
 		checks = checkMaxOpenShiftVersion(checks, deprecatedAPIsMessage)
 		checks = checkOCPLabelsWithHasDeprecatedAPIs(checks, deprecatedAPIsMessage)
 		for _, err := range checks.errs {
-			result.Add(errors.ErrInvalidCSV(err.Error(), bundle.CSV.GetName()))
+			result.Add(errors.ErrInvalidCSV(err.Error(), bundle.CSV.GetName()).WithCode(errors.RuleCommunityCriteria))
 		}
 		for _, warn := range checks.warns {
-			result.Add(errors.WarnInvalidCSV(warn.Error(), bundle.CSV.GetName()))
+			result.Add(errors.WarnInvalidCSV(warn.Error(), bundle.CSV.GetName()).WithCode(errors.RuleCommunityCriteria))
 		}
 	}
 
@@ -21,51 +21,58 @@ func init() {
 	install.Install(scheme)
 }
 
-var CRDValidator interfaces.Validator = interfaces.ValidatorFunc(validateCRDs)
+var CRDValidator interfaces.Validator = interfaces.ContextValidatorFunc(validateCRDs)
 
-func validateCRDs(objs ...interface{}) (results []errors.ManifestResult) {
+func validateCRDs(ctx context.Context, objs ...interface{}) (results []errors.ManifestResult) {
 	for _, obj := range objs {
+		if ctx.Err() != nil {
+			break
+		}
 		switch v := obj.(type) {
 		case *v1beta1.CustomResourceDefinition:
-			results = append(results, validateV1Beta1CRD(v))
+			results = append(results, validateV1Beta1CRD(ctx, v))
 		case *v1.CustomResourceDefinition:
-			results = append(results, validateV1CRD(v))
+			results = append(results, validateV1CRD(ctx, v))
 		}
 	}
 	return results
 }
 
-func validateV1Beta1CRD(crd *v1beta1.CustomResourceDefinition) (result errors.ManifestResult) {
+func validateV1Beta1CRD(ctx context.Context, crd *v1beta1.CustomResourceDefinition) (result errors.ManifestResult) {
 	internalCRD := &apiextensions.CustomResourceDefinition{}
+	// Default a copy, crd may be read by other validators concurrently.
+	crd = crd.DeepCopy()
 	v1beta1.SetObjectDefaults_CustomResourceDefinition(crd)
 	err := scheme.Converter().Convert(crd, internalCRD, nil)
 	if err != nil {
-		result.Add(errors.ErrInvalidParse("error converting crd", err))
+		result.Add(errors.ErrInvalidParse("error converting crd", err).WithCode(errors.RuleCRDInvalid))
 		return result
 	}
 
-	result = validateInternalCRD(internalCRD)
+	result = validateInternalCRD(ctx, internalCRD)
 	return result
 }
 
-func validateV1CRD(crd *v1.CustomResourceDefinition) (result errors.ManifestResult) {
+func validateV1CRD(ctx context.Context, crd *v1.CustomResourceDefinition) (result errors.ManifestResult) {
 	internalCRD := &apiextensions.CustomResourceDefinition{}
+	// Default a copy, crd may be read by other validators concurrently.
+	crd = crd.DeepCopy()
 	v1.SetObjectDefaults_CustomResourceDefinition(crd)
 	err := scheme.Converter().Convert(crd, internalCRD, nil)
 	if err != nil {
-		result.Add(errors.ErrInvalidParse("error converting crd", err))
+		result.Add(errors.ErrInvalidParse("error converting crd", err).WithCode(errors.RuleCRDInvalid))
 		return result
 	}
 
-	result = validateInternalCRD(internalCRD)
+	result = validateInternalCRD(ctx, internalCRD)
 	return result
 }
 
-func validateInternalCRD(crd *apiextensions.CustomResourceDefinition) (result errors.ManifestResult) {
-	errList := validation.ValidateCustomResourceDefinition(context.TODO(), crd)
+func validateInternalCRD(ctx context.Context, crd *apiextensions.CustomResourceDefinition) (result errors.ManifestResult) {
+	errList := validation.ValidateCustomResourceDefinition(ctx, crd)
 	for _, err := range errList {
 		if !strings.Contains(err.Field, "openAPIV3Schema") && !strings.Contains(err.Field, "status") {
-			result.Add(errors.NewError(errors.ErrorType(err.Type), err.Error(), err.Field, err.BadValue))
+			result.Add(errors.NewError(errors.ErrorType(err.Type), err.Error(), err.Field, err.BadValue).WithCode(errors.RuleCRDInvalid))
 		}
 	}
 
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/blang/semver/v4"
@@ -18,10 +19,13 @@ import (
 	"k8s.io/apimachinery/pkg/util/yaml"
 )
 
-var CSVValidator interfaces.Validator = interfaces.ValidatorFunc(validateCSVs)
+var CSVValidator interfaces.Validator = interfaces.ContextValidatorFunc(validateCSVs)
 
-func validateCSVs(objs ...interface{}) (results []errors.ManifestResult) {
+func validateCSVs(ctx context.Context, objs ...interface{}) (results []errors.ManifestResult) {
 	for _, obj := range objs {
+		if ctx.Err() != nil {
+			break
+		}
 		switch v := obj.(type) {
 		case *v1alpha1.ClusterServiceVersion:
 			results = append(results, validateCSV(v))
@@ -35,11 +39,11 @@ func validateCSV(csv *v1alpha1.ClusterServiceVersion) errors.ManifestResult {
 	result := errors.ManifestResult{Name: csv.GetName()}
 	// Ensure CSV names are of the correct format.
 	if err := parseCSVNameFormat(csv.GetName()); err != nil {
-		result.Add(errors.ErrInvalidCSV(fmt.Sprintf("metadata.name %s", err), csv.GetName()))
+		result.Add(errors.ErrInvalidCSV(fmt.Sprintf("metadata.name %s", err), csv.GetName()).WithCode(errors.RuleCSVNameInvalid))
 	}
 	if replaces := csv.Spec.Replaces; replaces != "" {
 		if err := parseCSVNameFormat(replaces); err != nil {
-			result.Add(errors.ErrInvalidCSV(fmt.Sprintf("spec.replaces %s", err), csv.GetName()))
+			result.Add(errors.ErrInvalidCSV(fmt.Sprintf("spec.replaces %s", err), csv.GetName()).WithCode(errors.RuleCSVReplacesInvalid))
 		}
 	}
 	// validate example annotations ("alm-examples", "olm.examples").
@@ -51,7 +55,8 @@ func validateCSV(csv *v1alpha1.ClusterServiceVersion) errors.ManifestResult {
 	// check missing optional/mandatory fields.
 	result.Add(checkFields(*csv)...)
 	// validate case sensitive annotation names
-	result.Add(ValidateAnnotationNames(csv.GetAnnotations(), csv.GetName())...)
+	annotationErrs := withPropertiesAnnotationFix(ValidateAnnotationNames(csv.GetAnnotations(), csv.GetName()), csv.GetAnnotations())
+	result.Add(withFixTarget(annotationErrs, v1alpha1.ClusterServiceVersionKind, csv.GetName())...)
 	// validate Version and Kind
 	result.Add(validateVersionKind(csv)...)
 	return result
@@ -82,7 +87,7 @@ func validateExamplesAnnotations(csv *v1alpha1.ClusterServiceVersion) (errs []er
 	annotations := csv.ObjectMeta.GetAnnotations()
 	// Return right away if no examples annotations are found.
 	if len(annotations) == 0 {
-		errs = append(errs, errors.WarnInvalidCSV("annotations not found", csv.GetName()))
+		errs = append(errs, errors.WarnInvalidCSV("annotations not found", csv.GetName()).WithCode(errors.RuleCSVExamplesMissing))
 		return errs
 	}
 	// Expect either `alm-examples` or `olm.examples` but not both
@@ -91,11 +96,11 @@ func validateExamplesAnnotations(csv *v1alpha1.ClusterServiceVersion) (errs []er
 	almExamples, almOK := annotations["alm-examples"]
 	olmExamples, olmOK := annotations["olm.examples"]
 	if !almOK && !olmOK {
-		errs = append(errs, errors.WarnInvalidCSV("example annotations not found", csv.GetName()))
+		errs = append(errs, errors.WarnInvalidCSV("example annotations not found", csv.GetName()).WithCode(errors.RuleCSVExamplesMissing))
 		return errs
 	} else if almOK {
 		if olmOK {
-			errs = append(errs, errors.WarnInvalidCSV("both `alm-examples` and `olm.examples` are present. Checking only `alm-examples`", csv.GetName()))
+			errs = append(errs, errors.WarnInvalidCSV("both `alm-examples` and `olm.examples` are present. Checking only `alm-examples`", csv.GetName()).WithCode(errors.RuleCSVExamplesDuplicated))
 		}
 		examplesString = almExamples
 	} else {
@@ -103,14 +108,14 @@ func validateExamplesAnnotations(csv *v1alpha1.ClusterServiceVersion) (errs []er
 	}
 
 	if err := validateJSON(examplesString); err != nil {
-		errs = append(errs, errors.ErrInvalidParse("invalid example", err))
+		errs = append(errs, errors.ErrInvalidParse("invalid example", err).WithCode(errors.RuleCSVExamplesInvalid))
 		return errs
 	}
 
 	us := []unstructured.Unstructured{}
 	dec := yaml.NewYAMLOrJSONDecoder(strings.NewReader(examplesString), 8)
 	if err := dec.Decode(&us); err != nil && err != io.EOF {
-		errs = append(errs, errors.ErrInvalidParse("error decoding example CustomResource", err))
+		errs = append(errs, errors.ErrInvalidParse("error decoding example CustomResource", err).WithCode(errors.RuleCSVExamplesInvalid))
 		return errs
 	}
 	parsed := map[schema.GroupVersionKind]struct{}{}
@@ -155,7 +160,7 @@ func getProvidedAPIs(csv *v1alpha1.ClusterServiceVersion) (provided map[schema.G
 	for _, owned := range csv.Spec.CustomResourceDefinitions.Owned {
 		parts := strings.SplitN(owned.Name, ".", 2)
 		if len(parts) < 2 {
-			errs = append(errs, errors.ErrInvalidParse(fmt.Sprintf("couldn't parse plural.group from crd name: %s", owned.Name), nil))
+			errs = append(errs, errors.ErrInvalidParse(fmt.Sprintf("couldn't parse plural.group from crd name: %s", owned.Name), nil).WithCode(errors.RuleCSVOwnedCRDNameInvalid))
 			continue
 		}
 		provided[newGVK(parts[1], owned.Version, owned.Kind)] = struct{}{}
@@ -175,12 +180,12 @@ func newGVK(g, v, k string) schema.GroupVersionKind {
 func matchGVKProvidedAPIs(exampleSet map[schema.GroupVersionKind]struct{}, providedAPISet map[schema.GroupVersionKind]struct{}) (errs []errors.Error) {
 	for example := range exampleSet {
 		if _, ok := providedAPISet[example]; !ok {
-			errs = append(errs, errors.ErrInvalidOperation("example must have a provided API", example))
+			errs = append(errs, errors.ErrInvalidOperation("example must have a provided API", example).WithCode(errors.RuleCSVExampleAPINotProvided))
 		}
 	}
 	for api := range providedAPISet {
 		if _, ok := exampleSet[api]; !ok {
-			errs = append(errs, errors.WarnInvalidOperation("provided API should have an example annotation", api))
+			errs = append(errs, errors.WarnInvalidOperation("provided API should have an example annotation", api).WithCode(errors.RuleCSVProvidedAPIExampleMissing))
 		}
 	}
 	return errs
@@ -188,7 +193,7 @@ func matchGVKProvidedAPIs(exampleSet map[schema.GroupVersionKind]struct{}, provi
 
 func validateInstallModes(csv *v1alpha1.ClusterServiceVersion) (errs []errors.Error) {
 	if len(csv.Spec.InstallModes) == 0 {
-		errs = append(errs, errors.ErrInvalidCSV("install modes not found", csv.GetName()))
+		errs = append(errs, errors.ErrInvalidCSV("install modes not found", csv.GetName()).WithCode(errors.RuleCSVInstallModesMissing))
 		return errs
 	}
 
@@ -196,7 +201,7 @@ func validateInstallModes(csv *v1alpha1.ClusterServiceVersion) (errs []errors.Er
 	anySupported := false
 	for _, installMode := range csv.Spec.InstallModes {
 		if _, ok := installModeSet[installMode.Type]; ok {
-			errs = append(errs, errors.ErrInvalidCSV("duplicate install modes present", csv.GetName()))
+			errs = append(errs, errors.ErrInvalidCSV("duplicate install modes present", csv.GetName()).WithCode(errors.RuleCSVInstallModesDuplicated))
 		} else if installMode.Supported {
 			anySupported = true
 		}
@@ -219,7 +224,7 @@ func validateInstallModes(csv *v1alpha1.ClusterServiceVersion) (errs []errors.Er
 					}
 				}
 				if supportsOnlyAllNamespaces == false {
-					errs = append(errs, errors.ErrInvalidCSV("only AllNamespaces InstallModeType is supported when conversionCRDs is present", csv.GetName()))
+					errs = append(errs, errors.ErrInvalidCSV("only AllNamespaces InstallModeType is supported when conversionCRDs is present", csv.GetName()).WithCode(errors.RuleCSVInstallModesConversion))
 				}
 			}
 		}
@@ -227,7 +232,7 @@ func validateInstallModes(csv *v1alpha1.ClusterServiceVersion) (errs []errors.Er
 
 	// all installModes should not be `false`
 	if !anySupported {
-		errs = append(errs, errors.ErrInvalidCSV("none of InstallModeTypes are supported", csv.GetName()))
+		errs = append(errs, errors.ErrInvalidCSV("none of InstallModeTypes are supported", csv.GetName()).WithCode(errors.RuleCSVInstallModesUnsupported))
 	}
 	return errs
 }
@@ -236,10 +241,10 @@ func validateInstallModes(csv *v1alpha1.ClusterServiceVersion) (errs []errors.Er
 func validateVersionKind(csv *v1alpha1.ClusterServiceVersion) (errs []errors.Error) {
 	gvk := csv.GroupVersionKind()
 	if gvk.Version == "" {
-		errs = append(errs, errors.ErrInvalidCSV("'apiVersion' is missing", csv.GetName()))
+		errs = append(errs, errors.ErrInvalidCSV("'apiVersion' is missing", csv.GetName()).WithCode(errors.RuleCSVTypeMetaMissing))
 	}
 	if gvk.Kind == "" {
-		errs = append(errs, errors.ErrInvalidCSV("'kind' is missing", csv.GetName()))
+		errs = append(errs, errors.ErrInvalidCSV("'kind' is missing", csv.GetName()).WithCode(errors.RuleCSVTypeMetaMissing))
 	}
 	return
 }
@@ -247,10 +252,10 @@ func validateVersionKind(csv *v1alpha1.ClusterServiceVersion) (errs []errors.Err
 // validateMinKubeVersion checks format of spec.minKubeVersion field
 func validateMinKubeVersion(csv v1alpha1.ClusterServiceVersion) (errs []errors.Error) {
 	if len(strings.TrimSpace(csv.Spec.MinKubeVersion)) == 0 {
-		errs = append(errs, errors.WarnInvalidCSV(minKubeVersionWarnMessage, csv.GetName()))
+		errs = append(errs, errors.WarnInvalidCSV(minKubeVersionWarnMessage, csv.GetName()).WithCode(errors.RuleCSVMinKubeVersionMissing))
 	} else {
 		if _, err := semver.Parse(csv.Spec.MinKubeVersion); err != nil {
-			errs = append(errs, errors.ErrInvalidCSV(fmt.Sprintf("csv.Spec.MinKubeVersion has an invalid value: %s", csv.Spec.MinKubeVersion), csv.GetName()))
+			errs = append(errs, errors.ErrInvalidCSV(fmt.Sprintf("csv.Spec.MinKubeVersion has an invalid value: %s", csv.Spec.MinKubeVersion), csv.GetName()).WithCode(errors.RuleCSVMinKubeVersionInvalid))
 		}
 	}
 	return errs
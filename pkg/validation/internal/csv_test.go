@@ -29,7 +29,7 @@ func TestValidateCSV(t *testing.T) {
 				description: "invalid install modes",
 				wantErr:     true,
 				errors: []errors.Error{
-					errors.ErrInvalidCSV("install modes not found", "etcdoperator.v0.9.0"),
+					errors.ErrInvalidCSV("install modes not found", "etcdoperator.v0.9.0").WithCode(errors.RuleCSVInstallModesMissing),
 				},
 			},
 			filepath.Join("testdata", "noInstallMode.csv.yaml"),
@@ -46,7 +46,7 @@ func TestValidateCSV(t *testing.T) {
 				description: "invalid install modes when dealing with conversionCRDs",
 				wantErr:     true,
 				errors: []errors.Error{
-					errors.ErrInvalidCSV("only AllNamespaces InstallModeType is supported when conversionCRDs is present", "etcdoperator.v0.9.0"),
+					errors.ErrInvalidCSV("only AllNamespaces InstallModeType is supported when conversionCRDs is present", "etcdoperator.v0.9.0").WithCode(errors.RuleCSVInstallModesConversion),
 				},
 			},
 			filepath.Join("testdata", "incorrect.csv.with.conversion.webhook.yaml"),
@@ -56,9 +56,12 @@ func TestValidateCSV(t *testing.T) {
 				description: "invalid annotation name for csv",
 				wantErr:     true,
 				errors: []errors.Error{
-					errors.ErrFailedValidation("provided annotation olm.skiprange uses wrong case and should be olm.skipRange instead", "etcdoperator.v0.9.0"),
-					errors.ErrFailedValidation("provided annotation olm.operatorgroup uses wrong case and should be olm.operatorGroup instead", "etcdoperator.v0.9.0"),
-					errors.ErrFailedValidation("provided annotation olm.operatornamespace uses wrong case and should be olm.operatorNamespace instead", "etcdoperator.v0.9.0"),
+					errors.ErrFailedValidation("provided annotation olm.skiprange uses wrong case and should be olm.skipRange instead", "etcdoperator.v0.9.0").WithCode(errors.RuleAnnotationCase).
+						WithFix(annotationCaseFix(operatorsv1alpha1.ClusterServiceVersionKind, "etcdoperator.v0.9.0", "olm.skiprange", "olm.skipRange")),
+					errors.ErrFailedValidation("provided annotation olm.operatorgroup uses wrong case and should be olm.operatorGroup instead", "etcdoperator.v0.9.0").WithCode(errors.RuleAnnotationCase).
+						WithFix(annotationCaseFix(operatorsv1alpha1.ClusterServiceVersionKind, "etcdoperator.v0.9.0", "olm.operatorgroup", "olm.operatorGroup")),
+					errors.ErrFailedValidation("provided annotation olm.operatornamespace uses wrong case and should be olm.operatorNamespace instead", "etcdoperator.v0.9.0").WithCode(errors.RuleAnnotationCase).
+						WithFix(annotationCaseFix(operatorsv1alpha1.ClusterServiceVersionKind, "etcdoperator.v0.9.0", "olm.operatornamespace", "olm.operatorNamespace")),
 				},
 			},
 			filepath.Join("testdata", "badAnnotationNames.csv.yaml"),
@@ -68,7 +71,7 @@ func TestValidateCSV(t *testing.T) {
 				description: "csv with name over 63 characters limit",
 				wantErr:     true,
 				errors: []errors.Error{
-					errors.ErrInvalidCSV(`metadata.name "someoperatorwithanextremelylongnamethatmakenosensewhatsoever.v999.999.999" is invalid: must be no more than 63 bytes`, "someoperatorwithanextremelylongnamethatmakenosensewhatsoever.v999.999.999"),
+					errors.ErrInvalidCSV(`metadata.name "someoperatorwithanextremelylongnamethatmakenosensewhatsoever.v999.999.999" is invalid: must be no more than 63 bytes`, "someoperatorwithanextremelylongnamethatmakenosensewhatsoever.v999.999.999").WithCode(errors.RuleCSVNameInvalid),
 				},
 			},
 			filepath.Join("testdata", "badName.csv.yaml"),
@@ -78,7 +81,7 @@ func TestValidateCSV(t *testing.T) {
 				description: "should fail when alm-examples is pretty format and is invalid",
 				wantErr:     true,
 				errors: []errors.Error{
-					errors.ErrInvalidParse("invalid example", "invalid character at 176\n [{\"apiVersion\":\"local.storage.openshift.io/v1\",\"kind\":\"LocalVolume\",\"metadata\":{\"name\":\"example\"},\"spec\":{\"storageClassDevices\":[{\"devicePaths\":[\"/dev/disk/by-id/ata-crucial\",]<--(see the invalid character)"),
+					errors.ErrInvalidParse("invalid example", "invalid character at 176\n [{\"apiVersion\":\"local.storage.openshift.io/v1\",\"kind\":\"LocalVolume\",\"metadata\":{\"name\":\"example\"},\"spec\":{\"storageClassDevices\":[{\"devicePaths\":[\"/dev/disk/by-id/ata-crucial\",]<--(see the invalid character)").WithCode(errors.RuleCSVExamplesInvalid),
 				},
 			},
 			filepath.Join("testdata", "invalid.alm-examples.csv.yaml"),
@@ -88,7 +91,7 @@ func TestValidateCSV(t *testing.T) {
 				description: "should not fail when alm-examples is not informed",
 				wantWarn:    true,
 				errors: []errors.Error{
-					errors.WarnInvalidOperation("provided API should have an example annotation", schema.GroupVersionKind{Group: "etcd.database.coreos.com", Version: "v1beta2", Kind: "EtcdCluster"}),
+					errors.WarnInvalidOperation("provided API should have an example annotation", schema.GroupVersionKind{Group: "etcd.database.coreos.com", Version: "v1beta2", Kind: "EtcdCluster"}).WithCode(errors.RuleCSVProvidedAPIExampleMissing),
 				},
 			},
 			filepath.Join("testdata", "correct.csv.empty.example.yaml"),
@@ -103,7 +106,21 @@ func TestValidateCSV(t *testing.T) {
 							"found %s annotation, please define these properties in metadata/properties.yaml instead",
 							olmpropertiesAnnotation,
 						),
-					),
+					).WithCode(errors.RuleAnnotationProperties).WithFix(errors.Fix{
+						Description: "move the properties of the olm.properties annotation to metadata/properties.yaml",
+						Kind:        "ClusterServiceVersion",
+						Name:        "etcdoperator.v0.9.0",
+						Patch:       []errors.PatchOperation{{Op: errors.PatchRemove, Path: "/metadata/annotations/olm.properties"}},
+						Also: []errors.Fix{{
+							Description: "add the properties of the olm.properties annotation",
+							File:        "metadata/properties.yaml",
+							Patch: []errors.PatchOperation{{
+								Op:    errors.PatchAdd,
+								Path:  "/properties/-",
+								Value: map[string]interface{}{"type": "foo", "value": "bar"},
+							}},
+						}},
+					}),
 				},
 			},
 			filepath.Join("testdata", "correct.csv.olm.properties.annotation.yaml"),
@@ -113,7 +130,7 @@ func TestValidateCSV(t *testing.T) {
 				description: "should fail when spec.minKubeVersion is not in semantic version format",
 				wantErr:     true,
 				errors: []errors.Error{
-					errors.ErrInvalidCSV(`csv.Spec.MinKubeVersion has an invalid value: 1.21`, "test-operator.v0.0.1"),
+					errors.ErrInvalidCSV(`csv.Spec.MinKubeVersion has an invalid value: 1.21`, "test-operator.v0.0.1").WithCode(errors.RuleCSVMinKubeVersionInvalid),
 				},
 			},
 			filepath.Join("testdata", "invalid_min_kube_version.csv.yaml"),
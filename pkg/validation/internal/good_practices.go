@@ -1,8 +1,10 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	goerrors "errors"
@@ -13,6 +15,7 @@ import (
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"github.com/operator-framework/api/pkg/validation/errors"
 	interfaces "github.com/operator-framework/api/pkg/validation/interfaces"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // GoodPracticesValidator validates the bundle against criteria and suggestions defined as
@@ -27,6 +30,9 @@ import (
 //
 // - CRDs defined in the bundle have empty descriptions
 //
+// - Images of the deployments, including RELATED_IMAGE_* environment variables, are not listed in spec.relatedImages,
+// which is required to mirror them for disconnected installs
+//
 // - Check if the CSV has permissions to create CRDs. Note that:
 // a) "Operators should own a CRD and only one Operator should control a CRD on a cluster. Two Operators managing the same CRD is not a recommended best practice. In the case where an API exists but with multiple implementations, this is typically an example of a no-op Operator because it doesn't have any deployment or reconciliation loop to define the shared API and other Operators depend on this Operator to provide one implementation of the API, e.g. similar to PVCs or Ingress."
 //
@@ -36,10 +42,13 @@ import (
 // - The bundle name (CSV.metadata.name) does not follow the naming convention: <operator-name>.v<semver> e.g. memcached-operator.v0.0.1
 //
 // NOTE: The bundle name must be 63 characters or less because it will be used as k8s ownerref label which only allows max of 63 characters.
-var GoodPracticesValidator interfaces.Validator = interfaces.ValidatorFunc(goodPracticesValidator)
+var GoodPracticesValidator interfaces.Validator = interfaces.ContextValidatorFunc(goodPracticesValidator)
 
-func goodPracticesValidator(objs ...interface{}) (results []errors.ManifestResult) {
+func goodPracticesValidator(ctx context.Context, objs ...interface{}) (results []errors.ManifestResult) {
 	for _, obj := range objs {
+		if ctx.Err() != nil {
+			break
+		}
 		switch v := obj.(type) {
 		case *manifests.Bundle:
 			results = append(results, validateGoodPracticesFrom(v))
@@ -51,31 +60,32 @@ func goodPracticesValidator(objs ...interface{}) (results []errors.ManifestResul
 func validateGoodPracticesFrom(bundle *manifests.Bundle) errors.ManifestResult {
 	result := errors.ManifestResult{}
 	if bundle == nil {
-		result.Add(errors.ErrInvalidBundle("Bundle is nil", nil))
+		result.Add(errors.ErrInvalidBundle("Bundle is nil", nil).WithCode(errors.RuleBundleMissing))
 		return result
 	}
 
 	result.Name = bundle.Name
 
 	if bundle.CSV == nil {
-		result.Add(errors.ErrInvalidBundle("Bundle csv is nil", bundle.Name))
+		result.Add(errors.ErrInvalidBundle("Bundle csv is nil", bundle.Name).WithCode(errors.RuleBundleMissing))
 		return result
 	}
 
 	errs, warns := validateResourceRequests(bundle.CSV)
-	warns = append(warns, validateCrdDescriptions(bundle.CSV.Spec.CustomResourceDefinitions)...)
+	warns = append(warns, validateCrdDescriptions(bundle)...)
 	warns = append(warns, validateHubChannels(bundle))
 	warns = append(warns, validateRBACForCRDsWith(bundle.CSV))
 	warns = append(warns, checkBundleName(bundle.CSV)...)
+	warns = append(warns, validateMissingRelatedImages(bundle.CSV))
 
 	for _, err := range errs {
 		if err != nil {
-			result.Add(errors.ErrFailedValidation(err.Error(), bundle.CSV.GetName()))
+			result.Add(withRuleOf(errors.ErrFailedValidation(err.Error(), bundle.CSV.GetName()), err))
 		}
 	}
 	for _, warn := range warns {
 		if warn != nil {
-			result.Add(errors.WarnFailedValidation(warn.Error(), bundle.CSV.GetName()))
+			result.Add(withRuleOf(errors.WarnFailedValidation(warn.Error(), bundle.CSV.GetName()), warn))
 		}
 	}
 
@@ -85,25 +95,58 @@ func validateGoodPracticesFrom(bundle *manifests.Bundle) errors.ManifestResult {
 // validateResourceRequests will return a WARN when the resource request is not set
 func validateResourceRequests(csv *operatorsv1alpha1.ClusterServiceVersion) (errs, warns []error) {
 	if csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs == nil {
-		errs = append(errs, goerrors.New("unable to find a deployment to install in the CSV"))
+		errs = append(errs, withRule(errors.RuleGoodPracticesDeploymentMissing, goerrors.New("unable to find a deployment to install in the CSV")))
 		return errs, warns
 	}
 	deploymentSpec := csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs
 
-	for _, dSpec := range deploymentSpec {
-		for _, c := range dSpec.Spec.Template.Spec.Containers {
+	for i, dSpec := range deploymentSpec {
+		for j, c := range dSpec.Spec.Template.Spec.Containers {
 			if c.Resources.Requests == nil || !(len(c.Resources.Requests.Cpu().String()) != 0 && len(c.Resources.Requests.Memory().String()) != 0) {
 				msg := fmt.Errorf("unable to find the resource requests for the container: (%s). It is recommended "+
 					"to ensure the resource request for CPU and Memory. Be aware that for some clusters configurations "+
 					"it is required to specify requests or limits for those values. Otherwise, the system or quota may "+
 					"reject Pod creation. More info: https://master.sdk.operatorframework.io/docs/best-practices/managing-resources/", c.Name)
-				warns = append(warns, msg)
+				warns = append(warns, withFix(withRule(errors.RuleGoodPracticesResourceRequests, msg), resourceRequestsFix(csv, i, j, c)))
 			}
 		}
 	}
 	return errs, warns
 }
 
+// Resource requests set by resourceRequestsFix when the container has no limits,
+// which are the requests scaffolded by the Operator SDK.
+const (
+	defaultCPURequest    = "10m"
+	defaultMemoryRequest = "64Mi"
+)
+
+// resourceRequestsFix returns the fix setting the resource requests of the
+// container c, the container j of the deployment i of csv. The requests are
+// set to the limits of the container, which are the requests Kubernetes
+// defaults to, or else to defaultCPURequest and defaultMemoryRequest.
+func resourceRequestsFix(csv *operatorsv1alpha1.ClusterServiceVersion, i, j int, c corev1.Container) errors.Fix {
+	requests := map[string]string{"cpu": defaultCPURequest, "memory": defaultMemoryRequest}
+	if cpu, ok := c.Resources.Limits[corev1.ResourceCPU]; ok {
+		requests["cpu"] = cpu.String()
+	}
+	if memory, ok := c.Resources.Limits[corev1.ResourceMemory]; ok {
+		requests["memory"] = memory.String()
+	}
+
+	resources := []string{"spec", "install", "spec", "deployments", strconv.Itoa(i), "spec", "template", "spec", "containers", strconv.Itoa(j), "resources"}
+	op := errors.PatchOperation{Op: errors.PatchAdd, Path: errors.JSONPointer(resources...), Value: map[string]interface{}{"requests": requests}}
+	if c.Resources.Limits != nil {
+		op = errors.PatchOperation{Op: errors.PatchAdd, Path: errors.JSONPointer(append(resources, "requests")...), Value: requests}
+	}
+	return errors.Fix{
+		Description: fmt.Sprintf("set the resource requests of the container %s to cpu=%s, memory=%s", c.Name, requests["cpu"], requests["memory"]),
+		Kind:        operatorsv1alpha1.ClusterServiceVersionKind,
+		Name:        csv.GetName(),
+		Patch:       []errors.PatchOperation{op},
+	}
+}
+
 // checkBundleName will validate the operator bundle name informed via CSV.metadata.name.
 // The motivation for the following check is to ensure that operators authors knows that operator bundles names should
 // follow a name and versioning convention
@@ -115,18 +158,18 @@ func checkBundleName(csv *operatorsv1alpha1.ClusterServiceVersion) []error {
 
 	if len(match) > 0 {
 		if _, err := semver.Parse(match[0]); err != nil {
-			warns = append(warns, fmt.Errorf("csv.metadata.Name %v is not following the versioning "+
-				"convention (MAJOR.MINOR.PATCH e.g 0.0.1): https://semver.org/", csv.Name))
+			warns = append(warns, withRule(errors.RuleGoodPracticesCSVNameSemver, fmt.Errorf("csv.metadata.Name %v is not following the versioning "+
+				"convention (MAJOR.MINOR.PATCH e.g 0.0.1): https://semver.org/", csv.Name)))
 		}
 	} else {
-		warns = append(warns, fmt.Errorf("csv.metadata.Name %v is not following the versioning "+
-			"convention (MAJOR.MINOR.PATCH e.g 0.0.1): https://semver.org/", csv.Name))
+		warns = append(warns, withRule(errors.RuleGoodPracticesCSVNameSemver, fmt.Errorf("csv.metadata.Name %v is not following the versioning "+
+			"convention (MAJOR.MINOR.PATCH e.g 0.0.1): https://semver.org/", csv.Name)))
 	}
 
 	// Check if its following the name convention
 	if len(strings.Split(csv.Name, ".v")) != 2 {
-		warns = append(warns, fmt.Errorf("csv.metadata.Name %v is not following the recommended "+
-			"naming convention: <operator-name>.v<semver> e.g. memcached-operator.v0.0.1", csv.Name))
+		warns = append(warns, withRule(errors.RuleGoodPracticesCSVNameConvention, fmt.Errorf("csv.metadata.Name %v is not following the recommended "+
+			"naming convention: <operator-name>.v<semver> e.g. memcached-operator.v0.0.1", csv.Name)))
 	}
 
 	return warns
@@ -135,6 +178,7 @@ func checkBundleName(csv *operatorsv1alpha1.ClusterServiceVersion) []error {
 // validateHubChannels will check the channels. The motivation for the following check is to ensure that operators
 // authors knows if their operator bundles are or not respecting the Naming Convention Rules.
 // However, the operator authors still able to choose the names as please them.
+// The warning has no Fix: renaming the channels of a published package breaks the subscriptions to them.
 func validateHubChannels(bundle *manifests.Bundle) error {
 	channels := append(bundle.Channels, bundle.DefaultChannel)
 	const candidate = "candidate"
@@ -154,9 +198,9 @@ func validateHubChannels(bundle *manifests.Bundle) error {
 	}
 
 	if len(channelsNotFollowingConventional) > 0 {
-		return fmt.Errorf("channel(s) %+q are not following the recommended naming convention: "+
+		return withRule(errors.RuleGoodPracticesChannelNaming, fmt.Errorf("channel(s) %+q are not following the recommended naming convention: "+
 			"https://olm.operatorframework.io/docs/best-practices/channel-naming",
-			channelsNotFollowingConventional)
+			channelsNotFollowingConventional))
 	}
 
 	return nil
@@ -168,11 +212,11 @@ func validateRBACForCRDsWith(csv *operatorsv1alpha1.ClusterServiceVersion) error
 		"apiextensions.k8s.io": {"customresourcedefinitions", "*", "[*]"},
 	}
 	verbs := []string{"create", "*", "[*]", "patch"}
-	warning := goerrors.New("CSV contains permissions to create CRD. An Operator shouldn't deploy or manage " +
-		"other operators (such patterns are known as meta or super operators or include CRDs in its Operands)." +
-		" It's the Operator Lifecycle Manager's job to manage the deployment and lifecycle of operators. " +
-		" Please, review the design of your solution and if you should not be using Dependency Resolution from OLM instead." +
-		" More info: https://sdk.operatorframework.io/docs/best-practices/common-recommendation/")
+	warning := withRule(errors.RuleGoodPracticesRBACForCRDs, goerrors.New("CSV contains permissions to create CRD. An Operator shouldn't deploy or manage "+
+		"other operators (such patterns are known as meta or super operators or include CRDs in its Operands)."+
+		" It's the Operator Lifecycle Manager's job to manage the deployment and lifecycle of operators. "+
+		" Please, review the design of your solution and if you should not be using Dependency Resolution from OLM instead."+
+		" More info: https://sdk.operatorframework.io/docs/best-practices/common-recommendation/"))
 
 	for _, perm := range csv.Spec.InstallStrategy.StrategySpec.Permissions {
 		if hasRBACFor(perm, apiGroupResourceMap, verbs) {
@@ -236,16 +280,127 @@ func getUniqueValues(array []string) []string {
 }
 
 // validateCrdDescriptions ensures that all CRDs defined in the bundle have non-empty descriptions.
-func validateCrdDescriptions(crds operatorsv1alpha1.CustomResourceDefinitions) []error {
+// The empty descriptions of owned CRDs can be fixed with the description of the CRD schema.
+func validateCrdDescriptions(bundle *manifests.Bundle) []error {
+	crds := bundle.CSV.Spec.CustomResourceDefinitions
 	f := func(crds []operatorsv1alpha1.CRDDescription, relation string) []error {
-		errors := make([]error, 0, len(crds))
-		for _, crd := range crds {
+		errs := make([]error, 0, len(crds))
+		for i, crd := range crds {
 			if crd.Description == "" {
-				errors = append(errors, fmt.Errorf("%s CRD %q has an empty description", relation, crd.Name))
+				err := withRule(errors.RuleGoodPracticesCRDDescription, fmt.Errorf("%s CRD %q has an empty description", relation, crd.Name))
+				if description := crdSchemaDescription(bundle, crd); relation == "owned" && description != "" {
+					err = withFix(err, errors.Fix{
+						Description: fmt.Sprintf("set the description of the owned CRD %s from its schema", crd.Name),
+						Kind:        operatorsv1alpha1.ClusterServiceVersionKind,
+						Name:        bundle.CSV.GetName(),
+						Patch: []errors.PatchOperation{{
+							Op:    errors.PatchAdd,
+							Path:  errors.JSONPointer("spec", "customresourcedefinitions", "owned", strconv.Itoa(i), "description"),
+							Value: description,
+						}},
+					})
+				}
+				errs = append(errs, err)
 			}
 		}
-		return errors
+		return errs
 	}
 
 	return append(f(crds.Owned, "owned"), f(crds.Required, "required")...)
 }
+
+// crdSchemaDescription returns the description of the OpenAPI schema of the
+// version of the CRD described by desc, if the CRD is in the bundle.
+func crdSchemaDescription(bundle *manifests.Bundle, desc operatorsv1alpha1.CRDDescription) string {
+	for _, crd := range bundle.V1CRDs {
+		if crd.GetName() != desc.Name {
+			continue
+		}
+		for _, version := range crd.Spec.Versions {
+			if version.Name == desc.Version && version.Schema != nil && version.Schema.OpenAPIV3Schema != nil {
+				return version.Schema.OpenAPIV3Schema.Description
+			}
+		}
+	}
+	for _, crd := range bundle.V1beta1CRDs {
+		if crd.GetName() != desc.Name {
+			continue
+		}
+		for _, version := range crd.Spec.Versions {
+			if version.Name == desc.Version && version.Schema != nil && version.Schema.OpenAPIV3Schema != nil {
+				return version.Schema.OpenAPIV3Schema.Description
+			}
+		}
+		if crd.Spec.Validation != nil && crd.Spec.Validation.OpenAPIV3Schema != nil {
+			return crd.Spec.Validation.OpenAPIV3Schema.Description
+		}
+	}
+	return ""
+}
+
+// relatedImageEnvPrefix is the prefix of the environment variables of the
+// operator containers holding the images of their operands.
+const relatedImageEnvPrefix = "RELATED_IMAGE_"
+
+// validateMissingRelatedImages checks that the images of the containers of the
+// CSV deployments, and the operand images of their RELATED_IMAGE_ environment
+// variables, are listed in spec.relatedImages, from which the images to mirror
+// for disconnected installs are found. The warning comes with a Fix adding the
+// missing images, named after their container or environment variable.
+func validateMissingRelatedImages(csv *operatorsv1alpha1.ClusterServiceVersion) error {
+	listed := map[string]struct{}{}
+	names := map[string]struct{}{}
+	for _, relatedImage := range csv.Spec.RelatedImages {
+		listed[relatedImage.Image] = struct{}{}
+		names[relatedImage.Name] = struct{}{}
+	}
+
+	var missing []operatorsv1alpha1.RelatedImage
+	add := func(name, image string) {
+		if _, ok := listed[image]; ok || image == "" {
+			return
+		}
+		listed[image] = struct{}{}
+		unique := name
+		for i := 2; ; i++ {
+			if _, ok := names[unique]; !ok {
+				break
+			}
+			unique = fmt.Sprintf("%s-%d", name, i)
+		}
+		names[unique] = struct{}{}
+		missing = append(missing, operatorsv1alpha1.RelatedImage{Name: unique, Image: image})
+	}
+	for _, deployment := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		podSpec := deployment.Spec.Template.Spec
+		for _, container := range append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...) {
+			add(container.Name, container.Image)
+			for _, env := range container.Env {
+				if strings.HasPrefix(env.Name, relatedImageEnvPrefix) {
+					add(strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(env.Name, relatedImageEnvPrefix), "_", "-")), env.Value)
+				}
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	images := make([]string, 0, len(missing))
+	var patch []errors.PatchOperation
+	for _, relatedImage := range missing {
+		images = append(images, relatedImage.Image)
+		patch = append(patch, errors.PatchOperation{Op: errors.PatchAdd, Path: errors.JSONPointer("spec", "relatedImages", "-"), Value: relatedImage})
+	}
+	if len(csv.Spec.RelatedImages) == 0 {
+		// The list may not be in the manifest, or be empty.
+		patch = []errors.PatchOperation{{Op: errors.PatchAdd, Path: errors.JSONPointer("spec", "relatedImages"), Value: missing}}
+	}
+	err := fmt.Errorf("images %+q are not listed in spec.relatedImages, which is required to mirror them for disconnected installs", images)
+	return withFix(withRule(errors.RuleGoodPracticesRelatedImagesMissing, err), errors.Fix{
+		Description: "add the missing images to spec.relatedImages",
+		Kind:        operatorsv1alpha1.ClusterServiceVersionKind,
+		Name:        csv.GetName(),
+		Patch:       patch,
+	})
+}
@@ -4,16 +4,35 @@ import (
 	"testing"
 
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/operator-framework/api/pkg/validation/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/operator-framework/api/pkg/manifests"
 	"github.com/stretchr/testify/require"
 )
 
+// listValidBundleRelatedImages adds the images of the deployments of testdata/valid_bundle,
+// which its CSV does not list, to the spec.relatedImages of bundle.
+func listValidBundleRelatedImages(bundle *manifests.Bundle) {
+	bundle.CSV.Spec.RelatedImages = []operatorsv1alpha1.RelatedImage{
+		{Name: "manager", Image: "quay.io/coreos/etcd-operator2@sha256:66a37fd61a06a43969854ee6d3e21087a98b93838e284a6086b13917f96b0d9b"},
+		{Name: "etcd-backup-operator", Image: "quay.io/coreos/etcd-operator@sha256:66a37fd61a06a43969854ee6d3e21087a98b93838e284a6086b13917f96b0d9b"},
+	}
+}
+
 func Test_ValidateGoodPractices(t *testing.T) {
 	bundleWithDeploymentSpecEmpty, _ := manifests.GetBundleFromDir("./testdata/valid_bundle")
 	bundleWithDeploymentSpecEmpty.CSV.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = nil
 
+	validBundle, _ := manifests.GetBundleFromDir("./testdata/valid_bundle")
+	listValidBundleRelatedImages(validBundle)
+
 	bundleWithMissingCrdDescription, _ := manifests.GetBundleFromDir("./testdata/valid_bundle")
+	listValidBundleRelatedImages(bundleWithMissingCrdDescription)
 	bundleWithMissingCrdDescription.CSV.Spec.CustomResourceDefinitions.Owned[0].Description = ""
 
 	type args struct {
@@ -32,7 +51,7 @@ func Test_ValidateGoodPractices(t *testing.T) {
 			name: "should pass successfully when the resource request is set for " +
 				"all containers defined in the bundle",
 			args: args{
-				bundleDir: "./testdata/valid_bundle",
+				bundle: validBundle,
 			},
 		},
 		{
@@ -106,6 +125,71 @@ func Test_ValidateGoodPractices(t *testing.T) {
 	}
 }
 
+func Test_ValidateGoodPracticesFixes(t *testing.T) {
+	bundleWithoutRequests, err := manifests.GetBundleFromDir("./testdata/valid_bundle_v1")
+	require.NoError(t, err)
+	containers := bundleWithoutRequests.CSV.Spec.InstallStrategy.StrategySpec.DeploymentSpecs[0].Spec.Template.Spec.Containers
+	containers[1].Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")}
+
+	results := validateGoodPracticesFrom(bundleWithoutRequests)
+	require.Len(t, results.Warnings, 2)
+	var fixes []errors.Fix
+	for _, w := range results.Warnings {
+		require.NotNil(t, w.Fix)
+		fixes = append(fixes, *w.Fix)
+	}
+	require.ElementsMatch(t, []errors.Fix{
+		{
+			Description: "set the resource requests of the container kube-rbac-proxy to cpu=10m, memory=64Mi",
+			Kind:        operatorsv1alpha1.ClusterServiceVersionKind,
+			Name:        "memcached-operator.v0.0.1",
+			Patch: []errors.PatchOperation{{
+				Op:    errors.PatchAdd,
+				Path:  "/spec/install/spec/deployments/0/spec/template/spec/containers/0/resources",
+				Value: map[string]interface{}{"requests": map[string]string{"cpu": "10m", "memory": "64Mi"}},
+			}},
+		},
+		{
+			Description: "set the resource requests of the container manager to cpu=10m, memory=128Mi",
+			Kind:        operatorsv1alpha1.ClusterServiceVersionKind,
+			Name:        "memcached-operator.v0.0.1",
+			Patch: []errors.PatchOperation{{
+				Op:    errors.PatchAdd,
+				Path:  "/spec/install/spec/deployments/0/spec/template/spec/containers/1/resources/requests",
+				Value: map[string]string{"cpu": "10m", "memory": "128Mi"},
+			}},
+		},
+	}, fixes)
+
+	bundleWithMissingCrdDescription, err := manifests.GetBundleFromDir("./testdata/valid_bundle")
+	require.NoError(t, err)
+	listValidBundleRelatedImages(bundleWithMissingCrdDescription)
+	bundleWithMissingCrdDescription.CSV.Spec.CustomResourceDefinitions.Owned[0].Description = ""
+	results = validateGoodPracticesFrom(bundleWithMissingCrdDescription)
+	require.Len(t, results.Warnings, 1)
+	require.Nil(t, results.Warnings[0].Fix, "the CRD schema has no description")
+
+	for _, crd := range bundleWithMissingCrdDescription.V1beta1CRDs {
+		if crd.GetName() == "etcdclusters.etcd.database.coreos.com" {
+			crd.Spec.Validation = &apiextensionsv1beta1.CustomResourceValidation{
+				OpenAPIV3Schema: &apiextensionsv1beta1.JSONSchemaProps{Description: "EtcdCluster is a cluster of etcd nodes."},
+			}
+		}
+	}
+	results = validateGoodPracticesFrom(bundleWithMissingCrdDescription)
+	require.Len(t, results.Warnings, 1)
+	require.Equal(t, &errors.Fix{
+		Description: "set the description of the owned CRD etcdclusters.etcd.database.coreos.com from its schema",
+		Kind:        operatorsv1alpha1.ClusterServiceVersionKind,
+		Name:        "etcdoperator.v0.9.4",
+		Patch: []errors.PatchOperation{{
+			Op:    errors.PatchAdd,
+			Path:  "/spec/customresourcedefinitions/owned/0/description",
+			Value: "EtcdCluster is a cluster of etcd nodes.",
+		}},
+	}, results.Warnings[0].Fix)
+}
+
 func TestValidateHubChannels(t *testing.T) {
 	type args struct {
 		channels []string
@@ -316,3 +400,67 @@ func TestCheckBundleName(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateMissingRelatedImages(t *testing.T) {
+	csv := func(relatedImages ...operatorsv1alpha1.RelatedImage) *operatorsv1alpha1.ClusterServiceVersion {
+		return &operatorsv1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "memcached-operator.v0.0.1"},
+			Spec: operatorsv1alpha1.ClusterServiceVersionSpec{
+				RelatedImages: relatedImages,
+				InstallStrategy: operatorsv1alpha1.NamedInstallStrategy{
+					StrategySpec: operatorsv1alpha1.StrategyDetailsDeployment{
+						DeploymentSpecs: []operatorsv1alpha1.StrategyDeploymentSpec{{
+							Name: "memcached-operator-controller-manager",
+							Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+								InitContainers: []corev1.Container{{Name: "init", Image: "quay.io/example/init:v0.0.1"}},
+								Containers: []corev1.Container{
+									{Name: "kube-rbac-proxy", Image: "gcr.io/kubebuilder/kube-rbac-proxy:v0.8.0"},
+									{
+										Name:  "manager",
+										Image: "quay.io/example/memcached-operator:v0.0.1",
+										Env: []corev1.EnvVar{
+											{Name: "RELATED_IMAGE_MEMCACHED_SERVER", Value: "docker.io/library/memcached:1.6"},
+											{Name: "WATCH_NAMESPACE", Value: "default"},
+										},
+									},
+								},
+							}}},
+						}},
+					},
+				},
+			},
+		}
+	}
+	all := []operatorsv1alpha1.RelatedImage{
+		{Name: "init", Image: "quay.io/example/init:v0.0.1"},
+		{Name: "kube-rbac-proxy", Image: "gcr.io/kubebuilder/kube-rbac-proxy:v0.8.0"},
+		{Name: "manager", Image: "quay.io/example/memcached-operator:v0.0.1"},
+		{Name: "memcached-server", Image: "docker.io/library/memcached:1.6"},
+	}
+
+	warn := func(csv *operatorsv1alpha1.ClusterServiceVersion) errors.Error {
+		err := validateMissingRelatedImages(csv)
+		require.Error(t, err)
+		return withRuleOf(errors.WarnFailedValidation(err.Error(), csv.GetName()), err)
+	}
+
+	require.NoError(t, validateMissingRelatedImages(csv(all...)))
+
+	w := warn(csv())
+	require.Equal(t, errors.RuleGoodPracticesRelatedImagesMissing, w.Code)
+	require.Equal(t, &errors.Fix{
+		Description: "add the missing images to spec.relatedImages",
+		Kind:        operatorsv1alpha1.ClusterServiceVersionKind,
+		Name:        "memcached-operator.v0.0.1",
+		Patch:       []errors.PatchOperation{{Op: errors.PatchAdd, Path: "/spec/relatedImages", Value: all}},
+	}, w.Fix)
+
+	// Names already used by other images get a suffix.
+	w = warn(csv(append([]operatorsv1alpha1.RelatedImage{{Name: "manager", Image: "quay.io/example/other:v1"}}, all[:2]...)...))
+	require.Equal(t, `images ["quay.io/example/memcached-operator:v0.0.1" "docker.io/library/memcached:1.6"] are not listed in spec.relatedImages, `+
+		`which is required to mirror them for disconnected installs`, w.Detail)
+	require.Equal(t, []errors.PatchOperation{
+		{Op: errors.PatchAdd, Path: "/spec/relatedImages/-", Value: operatorsv1alpha1.RelatedImage{Name: "manager-2", Image: "quay.io/example/memcached-operator:v0.0.1"}},
+		{Op: errors.PatchAdd, Path: "/spec/relatedImages/-", Value: all[3]},
+	}, w.Fix.Patch)
+}
@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+
+	"github.com/operator-framework/api/pkg/graph"
+	"github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/validation/errors"
+	interfaces "github.com/operator-framework/api/pkg/validation/interfaces"
+)
+
+// UpgradeGraphValidator validates the upgrade graphs of the channels of the
+// packages of the objects it is given: the bundles of a package manifest when
+// a *manifests.PackageManifest is given along with them, else the bundles
+// given, grouped by their package and channels, the channels of the
+// *manifests.Catalog's given, and the *graph.Graph's given.
+var UpgradeGraphValidator interfaces.Validator = interfaces.ValidatorFunc(validateUpgradeGraphs)
+
+func validateUpgradeGraphs(objs ...interface{}) (results []errors.ManifestResult) {
+	var pkgs []*manifests.PackageManifest
+	var bundles []*manifests.Bundle
+	var catalogs []*manifests.Catalog
+	var graphs []*graph.Graph
+	for _, obj := range objs {
+		switch v := obj.(type) {
+		case *manifests.PackageManifest:
+			pkgs = append(pkgs, v)
+		case *manifests.Bundle:
+			if v.CSV != nil {
+				bundles = append(bundles, v)
+			}
+		case *manifests.Catalog:
+			catalogs = append(catalogs, v)
+		case *graph.Graph:
+			graphs = append(graphs, v)
+		}
+	}
+
+	// buildErrs are the errors building the graphs of each package.
+	buildErrs := map[string][]error{}
+	switch {
+	case len(pkgs) > 0 && len(bundles) > 0:
+		for _, pkg := range pkgs {
+			pkgGraphs, err := graph.FromPackageManifest(pkg, bundles)
+			graphs = append(graphs, pkgGraphs...)
+			if err != nil {
+				buildErrs[pkg.PackageName] = append(buildErrs[pkg.PackageName], err)
+			}
+		}
+	case len(pkgs) == 0 && len(bundles) > 0:
+		// Bundles are built with the bundles of their package only, so that
+		// the errors of each package are reported with it.
+		byPackage := map[string][]*manifests.Bundle{}
+		for _, bundle := range bundles {
+			// Bundles without a package or channel are reported by the bundle validator.
+			if bundle.Package == "" || len(bundle.Channels) == 0 {
+				continue
+			}
+			byPackage[bundle.Package] = append(byPackage[bundle.Package], bundle)
+		}
+		for pkg, pkgBundles := range byPackage {
+			pkgGraphs, err := graph.FromBundles(pkgBundles)
+			graphs = append(graphs, pkgGraphs...)
+			if err != nil {
+				buildErrs[pkg] = append(buildErrs[pkg], err)
+			}
+		}
+	}
+	// Catalogs are built per package too, and the errors of a package in
+	// several catalogs are all reported.
+	for _, catalog := range catalogs {
+		for pkg, pkgCatalog := range catalogPackages(catalog) {
+			pkgGraphs, err := graph.FromCatalog(pkgCatalog)
+			graphs = append(graphs, pkgGraphs...)
+			if err != nil {
+				buildErrs[pkg] = append(buildErrs[pkg], err)
+			}
+		}
+	}
+
+	// Report the graphs of each package in one result, in the order of their names.
+	byPackage := map[string][]*graph.Graph{}
+	for _, g := range graphs {
+		byPackage[g.Package] = append(byPackage[g.Package], g)
+	}
+	names := make([]string, 0, len(byPackage))
+	for name := range byPackage {
+		names = append(names, name)
+	}
+	for name := range buildErrs {
+		if _, ok := byPackage[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result := errors.ManifestResult{Name: name}
+		for _, err := range buildErrs[name] {
+			result.Add(errors.ErrInvalidUpgradeGraph(err.Error(), name).WithCode(errors.RuleGraphInvalid))
+		}
+		for _, g := range byPackage[name] {
+			result.Add(validateUpgradeGraph(g)...)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// catalogPackages splits the channels and bundles of catalog by package.
+func catalogPackages(catalog *manifests.Catalog) map[string]*manifests.Catalog {
+	byPackage := map[string]*manifests.Catalog{}
+	get := func(pkg string) *manifests.Catalog {
+		if byPackage[pkg] == nil {
+			byPackage[pkg] = &manifests.Catalog{}
+		}
+		return byPackage[pkg]
+	}
+	for _, channel := range catalog.Channels {
+		c := get(channel.Package)
+		c.Channels = append(c.Channels, channel)
+	}
+	for _, bundle := range catalog.Bundles {
+		c := get(bundle.Package)
+		c.Bundles = append(c.Bundles, bundle)
+	}
+	return byPackage
+}
+
+// validateUpgradeGraph returns the problems of the upgrade graph of a channel.
+func validateUpgradeGraph(g *graph.Graph) (errs []errors.Error) {
+	for _, cycle := range g.Cycles() {
+		errs = append(errs, errors.ErrInvalidUpgradeGraph(
+			fmt.Sprintf("channel %s has an upgrade cycle between bundles %s", g.Channel, strings.Join(cycle, ", ")), cycle[0]).
+			WithCode(errors.RuleGraphCycle))
+	}
+
+	head := g.Head
+	if heads := g.Heads(); len(heads) > 1 {
+		errs = append(errs, errors.ErrInvalidUpgradeGraph(
+			fmt.Sprintf("channel %s has %d heads, bundles %s, which no bundle upgrades from: the channel must have a single head", g.Channel, len(heads), strings.Join(heads, ", ")), heads[0]).
+			WithCode(errors.RuleGraphMultipleHeads))
+	} else if head == "" && len(heads) == 1 {
+		head = heads[0]
+	}
+	if head != "" {
+		for _, name := range g.Unreachable(head) {
+			errs = append(errs, errors.ErrInvalidUpgradeGraph(
+				fmt.Sprintf("bundle %s of channel %s cannot be upgraded to the channel head %s", name, g.Channel, head), name).
+				WithCode(errors.RuleGraphUnreachable))
+		}
+	}
+
+	for _, n := range g.Nodes() {
+		if n.Replaces != "" && g.Node(n.Replaces) == nil {
+			errs = append(errs, errors.WarnInvalidUpgradeGraph(
+				fmt.Sprintf("bundle %s of channel %s replaces %s, which is not in the channel", n.Name, g.Channel, n.Replaces), n.Name).
+				WithCode(errors.RuleGraphDanglingReplaces))
+		}
+		if n.SkipRange == "" {
+			continue
+		}
+		if _, err := semver.ParseRange(n.SkipRange); err != nil {
+			errs = append(errs, errors.ErrInvalidUpgradeGraph(
+				fmt.Sprintf("bundle %s of channel %s has an invalid skipRange %q: %v", n.Name, g.Channel, n.SkipRange, err), n.Name).
+				WithCode(errors.RuleGraphSkipRangeInvalid))
+			continue
+		}
+		if !skipsByRange(g, n) {
+			errs = append(errs, errors.WarnInvalidUpgradeGraph(
+				fmt.Sprintf("skipRange %q of bundle %s of channel %s matches no bundle of the channel", n.SkipRange, n.Name, g.Channel), n.Name).
+				WithCode(errors.RuleGraphSkipRangeNoMatch))
+		}
+	}
+	return errs
+}
+
+// skipsByRange returns whether the skipRange of n matches a bundle of g.
+func skipsByRange(g *graph.Graph, n *graph.Node) bool {
+	for _, e := range g.UpgradesTo(n.Name) {
+		if e.Kind == graph.EdgeSkipRange {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package internal
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/api/pkg/graph"
+	"github.com/operator-framework/api/pkg/lib/version"
+	"github.com/operator-framework/api/pkg/manifests"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/operator-framework/api/pkg/validation/errors"
+)
+
+func TestValidateUpgradeGraphs(t *testing.T) {
+	bundle := func(name, ver, replaces, skipRange string, channels ...string) *manifests.Bundle {
+		csv := &operatorsv1alpha1.ClusterServiceVersion{}
+		csv.SetName(name)
+		csv.Spec.Replaces = replaces
+		csv.Spec.Version = version.OperatorVersion{Version: semver.MustParse(ver)}
+		if skipRange != "" {
+			csv.SetAnnotations(map[string]string{operatorsv1alpha1.SkipRangeAnnotationKey: skipRange})
+		}
+		return &manifests.Bundle{Name: name, CSV: csv, Package: "etcd", Channels: channels}
+	}
+
+	tests := []struct {
+		name string
+		objs []interface{}
+		want []errors.ManifestResult
+	}{
+		{
+			name: "valid bundles",
+			objs: []interface{}{
+				bundle("etcd.v0.9.0", "0.9.0", "", "", "stable"),
+				bundle("etcd.v0.9.2", "0.9.2", "etcd.v0.9.0", "", "stable"),
+				bundle("etcd.v0.9.4", "0.9.4", "etcd.v0.9.2", ">=0.9.0 <0.9.4", "stable"),
+				// Bundles without a package are not part of any graph.
+				&manifests.Bundle{Name: "other", CSV: &operatorsv1alpha1.ClusterServiceVersion{}},
+			},
+			want: []errors.ManifestResult{{Name: "etcd"}},
+		},
+		{
+			name: "invalid bundles",
+			objs: []interface{}{
+				bundle("etcd.v0.9.0", "0.9.0", "etcd.v0.9.2", "", "stable", "alpha"),
+				bundle("etcd.v0.9.2", "0.9.2", "etcd.v0.9.0", "", "stable"),
+				bundle("etcd.v0.9.4", "0.9.4", "etcd.v0.9.3", ">=0.9.3 <0.9.4", "stable"),
+				bundle("etcd.v0.9.5", "0.9.5", "etcd.v0.9.4", "~>0.9", "stable"),
+				bundle("etcd.v1.0.0", "1.0.0", "", "<0.1.0", "alpha"),
+			},
+			want: []errors.ManifestResult{{
+				Name: "etcd",
+				Errors: []errors.Error{
+					errors.ErrInvalidUpgradeGraph("channel alpha has 2 heads, bundles etcd.v0.9.0, etcd.v1.0.0, which no bundle upgrades from: the channel must have a single head", "etcd.v0.9.0").
+						WithCode(errors.RuleGraphMultipleHeads),
+					errors.ErrInvalidUpgradeGraph("channel stable has an upgrade cycle between bundles etcd.v0.9.0, etcd.v0.9.2", "etcd.v0.9.0").
+						WithCode(errors.RuleGraphCycle),
+					errors.ErrInvalidUpgradeGraph("bundle etcd.v0.9.0 of channel stable cannot be upgraded to the channel head etcd.v0.9.5", "etcd.v0.9.0").
+						WithCode(errors.RuleGraphUnreachable),
+					errors.ErrInvalidUpgradeGraph("bundle etcd.v0.9.2 of channel stable cannot be upgraded to the channel head etcd.v0.9.5", "etcd.v0.9.2").
+						WithCode(errors.RuleGraphUnreachable),
+					errors.ErrInvalidUpgradeGraph(`bundle etcd.v0.9.5 of channel stable has an invalid skipRange "~>0.9": Could not parse Range "~>0.9": Could not parse comparator "~>" in "~>0.9"`, "etcd.v0.9.5").
+						WithCode(errors.RuleGraphSkipRangeInvalid),
+				},
+				Warnings: []errors.Error{
+					errors.WarnInvalidUpgradeGraph("bundle etcd.v0.9.0 of channel alpha replaces etcd.v0.9.2, which is not in the channel", "etcd.v0.9.0").
+						WithCode(errors.RuleGraphDanglingReplaces),
+					errors.WarnInvalidUpgradeGraph(`skipRange "<0.1.0" of bundle etcd.v1.0.0 of channel alpha matches no bundle of the channel`, "etcd.v1.0.0").
+						WithCode(errors.RuleGraphSkipRangeNoMatch),
+					errors.WarnInvalidUpgradeGraph("bundle etcd.v0.9.4 of channel stable replaces etcd.v0.9.3, which is not in the channel", "etcd.v0.9.4").
+						WithCode(errors.RuleGraphDanglingReplaces),
+					errors.WarnInvalidUpgradeGraph(`skipRange ">=0.9.3 <0.9.4" of bundle etcd.v0.9.4 of channel stable matches no bundle of the channel`, "etcd.v0.9.4").
+						WithCode(errors.RuleGraphSkipRangeNoMatch),
+				},
+			}},
+		},
+		{
+			name: "package manifest",
+			objs: []interface{}{
+				bundle("etcd.v0.9.2", "0.9.2", "", ""),
+				bundle("etcd.v0.9.4", "0.9.4", "etcd.v0.9.2", ""),
+				bundle("etcd.v0.9.5", "0.9.5", "", ""),
+				&manifests.PackageManifest{PackageName: "etcd", Channels: []manifests.PackageChannel{{Name: "stable", CurrentCSVName: "etcd.v0.9.4"}}},
+			},
+			want: []errors.ManifestResult{{
+				Name: "etcd",
+				Errors: []errors.Error{
+					errors.ErrInvalidUpgradeGraph("unable to build the upgrade graph: bundle etcd.v0.9.5 is not in any channel", "etcd").
+						WithCode(errors.RuleGraphInvalid),
+				},
+			}},
+		},
+		{
+			name: "catalog and graph",
+			objs: []interface{}{
+				&manifests.Catalog{
+					Channels: []manifests.CatalogChannel{{Name: "stable", Package: "etcd", Entries: []manifests.CatalogChannelEntry{
+						{Name: "etcd.v0.9.2"},
+						{Name: "etcd.v0.9.4", SkipRange: "<0.9.4"},
+					}}},
+					Bundles: []manifests.CatalogBundle{{Name: "etcd.v0.9.2", Package: "etcd", Properties: []manifests.Property{
+						{Type: manifests.PropertyPackage, Value: json.RawMessage(`{"packageName":"etcd","version":"0.9.2"}`)},
+					}}},
+				},
+				graph.New("prometheus", "beta", []*graph.Node{{Name: "prometheus.v0.27.0"}, {Name: "prometheus.v0.28.0"}}),
+			},
+			want: []errors.ManifestResult{
+				{Name: "etcd"},
+				{
+					Name: "prometheus",
+					Errors: []errors.Error{
+						errors.ErrInvalidUpgradeGraph("channel beta has 2 heads, bundles prometheus.v0.27.0, prometheus.v0.28.0, which no bundle upgrades from: the channel must have a single head", "prometheus.v0.27.0").
+							WithCode(errors.RuleGraphMultipleHeads),
+					},
+				},
+			},
+		},
+		{
+			name: "invalid catalogs",
+			objs: []interface{}{
+				&manifests.Catalog{
+					Channels: []manifests.CatalogChannel{
+						{Name: "stable", Package: "etcd", Entries: []manifests.CatalogChannelEntry{{Name: "etcd.v0.9.2"}}},
+						{Name: "beta", Package: "prometheus", Entries: []manifests.CatalogChannelEntry{{Name: "prometheus.v0.27.0"}}},
+					},
+					Bundles: []manifests.CatalogBundle{
+						{Name: "etcd.v0.9.2", Package: "etcd", Properties: []manifests.Property{
+							{Type: manifests.PropertyPackage, Value: json.RawMessage(`{"packageName":"etcd","version":"0.9"}`)},
+						}},
+						{Name: "prometheus.v0.27.0", Package: "prometheus", Properties: []manifests.Property{
+							{Type: manifests.PropertyPackage, Value: json.RawMessage(`{"packageName":"prometheus","version":"0.27.0"}`)},
+						}},
+					},
+				},
+				&manifests.Catalog{
+					Channels: []manifests.CatalogChannel{{Name: "alpha", Package: "etcd", Entries: []manifests.CatalogChannelEntry{{Name: "etcd.v1.0.0"}}}},
+					Bundles: []manifests.CatalogBundle{{Name: "etcd.v1.0.0", Package: "etcd", Properties: []manifests.Property{
+						{Type: manifests.PropertyPackage, Value: json.RawMessage(`{"packageName":"etcd","version":"1"}`)},
+					}}},
+				},
+			},
+			want: []errors.ManifestResult{
+				{
+					Name: "etcd",
+					Errors: []errors.Error{
+						errors.ErrInvalidUpgradeGraph("unable to build the upgrade graph: bundle etcd.v0.9.2: invalid version: No Major.Minor.Patch elements found", "etcd").
+							WithCode(errors.RuleGraphInvalid),
+						errors.ErrInvalidUpgradeGraph("unable to build the upgrade graph: bundle etcd.v1.0.0: invalid version: No Major.Minor.Patch elements found", "etcd").
+							WithCode(errors.RuleGraphInvalid),
+					},
+				},
+				{Name: "prometheus"},
+			},
+		},
+		{
+			name: "package manifest without bundles",
+			objs: []interface{}{&manifests.PackageManifest{PackageName: "etcd"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, validateUpgradeGraphs(tt.objs...))
+		})
+	}
+}
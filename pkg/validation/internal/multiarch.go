@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os/exec"
@@ -43,7 +44,7 @@ import (
 //
 // The container named as manager under the CSV Deployment InstallStrategy (`Spec.InstallStrategy.StrategySpec.DeploymentSpecs`)
 // And if the above not found, all images under the InstallStrategy excluding the container named as `kube-rbac-proxy` since it is also scaffolded by default via SDK
-var MultipleArchitecturesValidator interfaces.Validator = interfaces.ValidatorFunc(multipleArchitecturesValidate)
+var MultipleArchitecturesValidator interfaces.Validator = interfaces.ContextValidatorFunc(multipleArchitecturesValidate)
 
 // ContainerToolsKey defines the key which can be used by its consumers
 // to inform where to find the container tool that should be used to inspect the image
@@ -112,47 +113,43 @@ func (p platform) String() string {
 	return fmt.Sprintf("%s/%s", p.OS, p.Architecture)
 }
 
-func multipleArchitecturesValidate(objs ...interface{}) (results []errors.ManifestResult) {
-	// Obtain the k8s version if informed via the objects an optional
-	var containerTool = ""
-	for _, obj := range objs {
-		switch obj.(type) {
-		case map[string]string:
-			// Check the key values informed
-			containerTool = obj.(map[string]string)[ContainerToolsKey]
-			if len(containerTool) > 0 {
-				// Make lower for we compare and use it
-				log.Infof("Container tool set to %q", containerTool)
-				containerTool = strings.ToLower(containerTool)
-				break
-			}
-		}
+func multipleArchitecturesValidate(ctx context.Context, objs ...interface{}) (results []errors.ManifestResult) {
+	// Obtain the container tool if informed via the optional values
+	opts, errs := optionsFrom(objs, ContainerToolsKey)
+	containerTool := opts.ContainerTools
+	if len(containerTool) > 0 {
+		// Make lower for we compare and use it
+		log.Infof("Container tool set to %q", containerTool)
+		containerTool = strings.ToLower(containerTool)
 	}
 
 	for _, obj := range objs {
+		if ctx.Err() != nil {
+			break
+		}
 		switch v := obj.(type) {
 		case *manifests.Bundle:
-			results = append(results, validateMultiArchWith(v, containerTool))
+			results = append(results, withOptionsErrors(validateMultiArchWith(ctx, v, containerTool), errs))
 		}
 	}
 
-	if len(results) == 0 {
+	if len(results) == 0 && ctx.Err() == nil {
 		log.Error("No bundles found.")
 	}
 	return results
 }
 
-func validateMultiArchWith(bundle *manifests.Bundle, containerTool string) errors.ManifestResult {
+func validateMultiArchWith(ctx context.Context, bundle *manifests.Bundle, containerTool string) errors.ManifestResult {
 	result := errors.ManifestResult{}
 	if bundle == nil {
-		result.Add(errors.ErrInvalidBundle("bundle is nil", nil))
+		result.Add(errors.ErrInvalidBundle("bundle is nil", nil).WithCode(errors.RuleBundleMissing))
 		return result
 	}
 
 	result.Name = bundle.Name
 
 	if bundle.CSV == nil {
-		result.Add(errors.ErrInvalidBundle("bundle csv is nil", bundle.Name))
+		result.Add(errors.ErrInvalidBundle("bundle csv is nil", bundle.Name).WithCode(errors.RuleBundleMissing))
 		return result
 	}
 
@@ -160,22 +157,22 @@ func validateMultiArchWith(bundle *manifests.Bundle, containerTool string) error
 	// with an invalid/unsupported value then make no sense do the check
 	containerTool, err := validateContainerTool(containerTool)
 	if err != nil {
-		result.Add(errors.ErrFailedValidation(err.Error(), bundle.CSV.GetName()))
+		result.Add(errors.ErrFailedValidation(err.Error(), bundle.CSV.GetName()).WithCode(errors.RuleMultiArchContainerTool))
 		return result
 	}
 
 	// Performs the checks
 	multiArchValidator := multiArchValidator{bundle: bundle, containerTool: containerTool}
-	multiArchValidator.validate()
+	multiArchValidator.validate(ctx)
 
 	for _, err := range multiArchValidator.warns {
 		// add the warn to the result
-		result.Add(errors.WarnFailedValidation(err.Error(), bundle.CSV.GetName()))
+		result.Add(errors.WarnFailedValidation(err.Error(), bundle.CSV.GetName()).WithCode(ruleOf(err)))
 	}
 
 	for _, err := range multiArchValidator.errors {
 		// add the warn to the result
-		result.Add(errors.ErrFailedValidation(err.Error(), bundle.CSV.GetName()))
+		result.Add(errors.ErrFailedValidation(err.Error(), bundle.CSV.GetName()).WithCode(ruleOf(err)))
 	}
 
 	return result
@@ -195,13 +192,18 @@ func validateContainerTool(containerTool string) (string, error) {
 // validate performs all required checks to validate the bundle against the Multiple Architecture
 // configuration to guess the missing labels and/or highlight what are the missing Architectures
 // for the images (for what is configured to be supported AND for what we guess that is supported
-// and just is missing a label).
-func (data *multiArchValidator) validate() {
+// and just is missing a label). The images are inspected with commands which are killed once ctx is done,
+// in which case the checks are not done: the platforms of the images not inspected are unknown.
+func (data *multiArchValidator) validate(ctx context.Context) {
 	data.loadInfraLabelsFromCSV()
 	data.loadImagesFromCSV()
-	data.managerImages = data.inspectImages(data.managerImages)
-	data.otherCSVDeploymentImages = data.inspectImages(data.otherCSVDeploymentImages)
-	data.relatedImages = data.inspectImages(data.relatedImages)
+	data.managerImages = data.inspectImages(ctx, data.managerImages)
+	data.otherCSVDeploymentImages = data.inspectImages(ctx, data.otherCSVDeploymentImages)
+	data.relatedImages = data.inspectImages(ctx, data.relatedImages)
+	if ctx.Err() != nil {
+		// The inspections which failed with ctx.Err() were reported.
+		return
+	}
 	data.loadAllPossibleArchSupported()
 	data.loadAllPossibleOsSupported()
 	data.doChecks()
@@ -256,7 +258,7 @@ func (data *multiArchValidator) loadImagesFromCSV() {
 			}
 
 			// Collect nodeAffinity boundaries for all images
-			data.imageNodeAffinity[c.Image] = append(extractNodeAffinityPlatforms(v.Spec.Template.Spec))
+			data.imageNodeAffinity[c.Image] = extractNodeAffinityPlatforms(v.Spec.Template.Spec)
 		}
 
 		// If we do not find a container called manager then we
@@ -341,14 +343,17 @@ func extractNodeAffinityPlatforms(spec corev1.PodSpec) []platform {
 
 // runManifestInspect executes the command for we are able to check what
 // are the Architecture(s) and OS(s) supported per each image found
-func runManifestInspect(image, tool string) (manifestInspect, error) {
-	cmd := exec.Command(tool, "pull", image)
+func runManifestInspect(ctx context.Context, image, tool string) (manifestInspect, error) {
+	if err := ctx.Err(); err != nil {
+		return manifestInspect{}, err
+	}
+	cmd := exec.CommandContext(ctx, tool, "pull", image)
 	_, err := runCommand(cmd)
 	if err != nil {
 		return manifestInspect{}, err
 	}
 
-	cmd = exec.Command(tool, "manifest", "inspect", image)
+	cmd = exec.CommandContext(ctx, tool, "manifest", "inspect", image)
 	output, err := runCommand(cmd)
 	if err != nil {
 		return manifestInspect{}, err
@@ -384,14 +389,16 @@ func runCommand(cmd *exec.Cmd) ([]byte, error) {
 }
 
 // inspectImages will lookup a list of images via a container tool to get a list of supported platforms
-func (data *multiArchValidator) inspectImages(images map[string][]platform) map[string][]platform {
+func (data *multiArchValidator) inspectImages(ctx context.Context, images map[string][]platform) map[string][]platform {
 	for k := range images {
-		manifest, err := runManifestInspect(k, data.containerTool)
+		manifest, err := runManifestInspect(ctx, k, data.containerTool)
 		if err != nil {
-			// try once more
-			manifest, err = runManifestInspect(k, data.containerTool)
+			// try once more, unless the validation was cancelled
+			if ctx.Err() == nil {
+				manifest, err = runManifestInspect(ctx, k, data.containerTool)
+			}
 			if err != nil {
-				data.warns = append(data.warns, fmt.Errorf("unable to inspect the image (%s) : %s", k, err))
+				data.warns = append(data.warns, withRule(errors.RuleMultiArchInspectFailed, fmt.Errorf("unable to inspect the image (%s) : %s", k, err)))
 
 				// We set the Arch and OS as error so we can identify that the container inspection failed later
 				// We raise a warning to notify the user that the image does not provide some kind of support
@@ -461,13 +468,13 @@ func (data *multiArchValidator) checkMissingSupportForOtherImages(images map[str
 		if len(listArchNotFound) > 0 {
 			sort.Strings(listArchNotFound)
 			data.warns = append(data.warns,
-				fmt.Errorf("check if the image %s should not support %q. "+
+				withRule(errors.RuleMultiArchImagePlatform, fmt.Errorf("check if the image %s should not support %q. "+
 					"Note that this CSV has labels for this Arch(s) "+
 					"Your manager image %q are providing this support OR the CSV is configured via labels "+
 					"to support it. Then, please verify if this image should not support it",
 					image,
 					listArchNotFound,
-					data.managerImagesString))
+					data.managerImagesString)))
 		}
 
 		listAllOsNotFound := []string{}
@@ -493,13 +500,13 @@ func (data *multiArchValidator) checkMissingSupportForOtherImages(images map[str
 		if len(listAllOsNotFound) > 0 {
 			sort.Strings(listAllOsNotFound)
 			data.warns = append(data.warns,
-				fmt.Errorf("check if the image %s should not support %q. "+
+				withRule(errors.RuleMultiArchImagePlatform, fmt.Errorf("check if the image %s should not support %q. "+
 					"Note that this CSV has labels for this OS(s) "+
 					"Your manager image %q are providing this support OR the CSV is configured via labels "+
 					"to support it. Then, please verify if this image should not support it",
 					image,
 					listAllOsNotFound,
-					data.managerImagesString))
+					data.managerImagesString)))
 		}
 	}
 }
@@ -525,16 +532,16 @@ func (data *multiArchValidator) checkNodeAffinity(images map[string][]platform)
 			if !imagePlatformDataValid {
 				// Node affinity info is missing from CSV (or invalid)
 				data.warns = append(data.warns,
-					fmt.Errorf("check if the CSV is missing a node affinity configuration for the image: %q. ",
+					withRule(errors.RuleMultiArchNodeAffinityMissing, fmt.Errorf("check if the CSV is missing a node affinity configuration for the image: %q. ",
 						image,
-					))
+					)))
 			}
 
 			// We have valid platform data for the image but a missing or invalid affinity configuration
-			data.warns = append(data.warns, fmt.Errorf("check if the CSV has a missing or invalid node affinity configuration for the image: %q. "+
+			data.warns = append(data.warns, withRule(errors.RuleMultiArchNodeAffinityMissing, fmt.Errorf("check if the CSV has a missing or invalid node affinity configuration for the image: %q. "+
 				"The image data suggests the following platforms are supported: %q",
 				image,
-				platformFromImage))
+				platformFromImage)))
 
 			continue
 		}
@@ -549,20 +556,20 @@ func (data *multiArchValidator) checkNodeAffinity(images map[string][]platform)
 
 		// Warn author about extra affinities
 		if len(extra) != 0 {
-			data.warns = append(data.warns, fmt.Errorf("the CSV includes %q in the node affinity configuration for the image: %q, but "+
+			data.warns = append(data.warns, withRule(errors.RuleMultiArchNodeAffinity, fmt.Errorf("the CSV includes %q in the node affinity configuration for the image: %q, but "+
 				"the image data suggests the following platforms are supported: %q",
 				extra,
 				image,
-				platformFromImage))
+				platformFromImage)))
 		}
 
 		// Warn author about missing affinities
 		if len(missing) != 0 {
-			data.warns = append(data.warns, fmt.Errorf("the image data indicates %q is supported for the image: %q, but "+
+			data.warns = append(data.warns, withRule(errors.RuleMultiArchNodeAffinity, fmt.Errorf("the image data indicates %q is supported for the image: %q, but "+
 				"the node affinity configuration for the image only specifies %q",
 				missing,
 				image,
-				data.imageNodeAffinity[image]))
+				data.imageNodeAffinity[image])))
 		}
 	}
 }
@@ -632,14 +639,14 @@ func (data *multiArchValidator) checkMissingLabelsForOS() {
 		// this message as result
 		sort.Strings(notFoundOsLabel)
 		data.warns = append(data.warns,
-			fmt.Errorf("check if the CSV is missing the label (%s<value>) for the OS(s): %q. "+
+			withRule(errors.RuleMultiArchLabelMissing, fmt.Errorf("check if the CSV is missing the label (%s<value>) for the OS(s): %q. "+
 				"Be aware that your Operator manager image %q provides this support. "+
 				"Thus, it is very likely that you want to provide it and if you support more than linux OS you MUST,"+
 				"use the required labels for all which are supported."+
 				"Otherwise, your solution cannot be listed on the cluster for these architectures",
 				operatorFrameworkOSLabel,
 				notFoundOsLabel,
-				data.managerImagesString))
+				data.managerImagesString)))
 	}
 }
 
@@ -670,14 +677,14 @@ func (data *multiArchValidator) checkMissingLabelsForArchs() {
 		sort.Strings(notFoundArchLabel)
 
 		data.warns = append(data.warns,
-			fmt.Errorf("check if the CSV is missing the label (%s<value>) for the Arch(s): %q. "+
+			withRule(errors.RuleMultiArchLabelMissing, fmt.Errorf("check if the CSV is missing the label (%s<value>) for the Arch(s): %q. "+
 				"Be aware that your Operator manager image %q provides this support. "+
 				"Thus, it is very likely that you want to provide it and if you support more than amd64 architectures, you MUST,"+
 				"use the required labels for all which are supported."+
 				"Otherwise, your solution cannot be listed on the cluster for these architectures",
 				operatorFrameworkArchLabel,
 				notFoundArchLabel,
-				data.managerImagesString))
+				data.managerImagesString)))
 	}
 }
 
@@ -768,9 +775,9 @@ func (data *multiArchValidator) checkSupportDefined() {
 			// Sort the images so we can check results in the tests
 			sort.Strings(images)
 			data.errors = append(data.errors,
-				fmt.Errorf("not all images specified are providing the support described via the CSV labels. "+
+				withRule(errors.RuleMultiArchUnsupported, fmt.Errorf("not all images specified are providing the support described via the CSV labels. "+
 					"Note that (OS.architecture): (%s) was not found for the image(s) %s",
-					platform, images))
+					platform, images)))
 		}
 	}
 }
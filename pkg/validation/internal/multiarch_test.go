@@ -1,11 +1,14 @@
 package internal
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
 	"github.com/operator-framework/api/pkg/manifests"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/operator-framework/api/pkg/validation/errors"
+	interfaces "github.com/operator-framework/api/pkg/validation/interfaces"
 	"github.com/stretchr/testify/require"
 )
 
@@ -82,7 +85,7 @@ func Test_ValidateMultiArchFrom(t *testing.T) {
 				tt.args.bundle.CSV.Labels = tt.args.labels
 			}
 
-			results := validateMultiArchWith(tt.args.bundle, "")
+			results := validateMultiArchWith(context.Background(), tt.args.bundle, "")
 			t.Log(results.Warnings)
 			t.Log(results.Errors)
 
@@ -258,7 +261,7 @@ func Test_RunManifestInspect(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := runManifestInspect(tt.args.image, tt.args.tool)
+			got, err := runManifestInspect(context.Background(), tt.args.image, tt.args.tool)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("runManifestInspect() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -677,3 +680,40 @@ func Test_multiArchValidator_checkNodeAffinity(t *testing.T) {
 
 	}
 }
+
+func Test_ValidateMultiArchCancelled(t *testing.T) {
+	bundle, err := manifests.GetBundleFromDir("./testdata/valid_bundle")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := validateMultiArchWith(ctx, bundle, "docker")
+	require.NotEmpty(t, results.Warnings)
+	require.Empty(t, results.Errors)
+	for _, w := range results.Warnings {
+		// The platforms of the images were not inspected, so they are not checked.
+		require.Equal(t, errors.RuleMultiArchInspectFailed, w.Code)
+		require.Contains(t, w.Detail, context.Canceled.Error())
+	}
+}
+
+func Test_ValidateCancelled(t *testing.T) {
+	bundle, err := manifests.GetBundleFromDir("./testdata/valid_bundle")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for name, validator := range map[string]interfaces.Validator{
+		"bundle":         BundleValidator,
+		"csv":            CSVValidator,
+		"good-practices": GoodPracticesValidator,
+		"multiarch":      MultipleArchitecturesValidator,
+	} {
+		t.Run(name, func(t *testing.T) {
+			results := interfaces.WithContext(validator).ValidateContext(ctx, bundle, bundle.CSV)
+			require.Empty(t, results, "no object is validated once the context is done")
+		})
+	}
+}
@@ -61,13 +61,13 @@ func validatePDB(u *unstructured.Unstructured) (result errors.ManifestResult) {
 
 	b, err := u.MarshalJSON()
 	if err != nil {
-		result.Add(errors.ErrInvalidParse("error converting unstructured", err))
+		result.Add(errors.ErrInvalidParse("error converting unstructured", err).WithCode(errors.RuleObjectInvalid))
 		return
 	}
 
 	err = json.Unmarshal(b, &pdb)
 	if err != nil {
-		result.Add(errors.ErrInvalidParse("error unmarshaling poddisruptionbudget", err))
+		result.Add(errors.ErrInvalidParse("error unmarshaling poddisruptionbudget", err).WithCode(errors.RuleObjectInvalid))
 		return
 	}
 
@@ -78,12 +78,12 @@ func validatePDB(u *unstructured.Unstructured) (result errors.ManifestResult) {
 
 	maxUnavailable := pdb.Spec.MaxUnavailable
 	if maxUnavailable != nil && (maxUnavailable.IntVal == 0 || maxUnavailable.StrVal == "0%") {
-		result.Add(errors.ErrInvalidObject(pdb, "maxUnavailable field cannot be set to 0 or 0%"))
+		result.Add(errors.ErrInvalidObject(pdb, "maxUnavailable field cannot be set to 0 or 0%").WithCode(errors.RuleObjectPDBMaxUnavailable))
 	}
 
 	minAvailable := pdb.Spec.MinAvailable
 	if minAvailable != nil && minAvailable.StrVal == "100%" {
-		result.Add(errors.ErrInvalidObject(pdb, "minAvailable field cannot be set to 100%"))
+		result.Add(errors.ErrInvalidObject(pdb, "minAvailable field cannot be set to 100%").WithCode(errors.RuleObjectPDBMinAvailable))
 	}
 
 	return
@@ -96,18 +96,18 @@ func validatePriorityClass(u *unstructured.Unstructured) (result errors.Manifest
 
 	b, err := u.MarshalJSON()
 	if err != nil {
-		result.Add(errors.ErrInvalidParse("error converting unstructured", err))
+		result.Add(errors.ErrInvalidParse("error converting unstructured", err).WithCode(errors.RuleObjectInvalid))
 		return
 	}
 
 	err = json.Unmarshal(b, &pc)
 	if err != nil {
-		result.Add(errors.ErrInvalidParse("error unmarshaling priorityclass", err))
+		result.Add(errors.ErrInvalidParse("error unmarshaling priorityclass", err).WithCode(errors.RuleObjectInvalid))
 		return
 	}
 
 	if pc.GlobalDefault {
-		result.Add(errors.ErrInvalidObject(pc, "globalDefault field cannot be set to true"))
+		result.Add(errors.ErrInvalidObject(pc, "globalDefault field cannot be set to true").WithCode(errors.RuleObjectPriorityClassGlobalDefault))
 	}
 
 	return
@@ -118,7 +118,7 @@ func validateRBAC(u *unstructured.Unstructured) (result errors.ManifestResult) {
 
 	b, err := u.MarshalJSON()
 	if err != nil {
-		result.Add(errors.ErrInvalidParse("error converting unstructured", err))
+		result.Add(errors.ErrInvalidParse("error converting unstructured", err).WithCode(errors.RuleObjectInvalid))
 		return
 	}
 
@@ -127,7 +127,7 @@ func validateRBAC(u *unstructured.Unstructured) (result errors.ManifestResult) {
 		role := rbacv1.Role{}
 		err = json.Unmarshal(b, &role)
 		if err != nil {
-			result.Add(errors.ErrInvalidParse("error unmarshaling role", err))
+			result.Add(errors.ErrInvalidParse("error unmarshaling role", err).WithCode(errors.RuleObjectInvalid))
 			return
 		}
 		policyRules = role.Rules
@@ -135,7 +135,7 @@ func validateRBAC(u *unstructured.Unstructured) (result errors.ManifestResult) {
 		clusterrole := rbacv1.ClusterRole{}
 		err = json.Unmarshal(b, &clusterrole)
 		if err != nil {
-			result.Add(errors.ErrInvalidParse("error unmarshaling clusterrole", err))
+			result.Add(errors.ErrInvalidParse("error unmarshaling clusterrole", err).WithCode(errors.RuleObjectInvalid))
 			return
 		}
 		policyRules = clusterrole.Rules
@@ -153,7 +153,7 @@ func audit(policies []rbacv1.PolicyRule) (result errors.ManifestResult) {
 		if contains(rule.APIGroups, PodDisruptionBudgetAPIGroup) &&
 			contains(rule.Resources, "poddisruptionbudgets") &&
 			contains(rule.Verbs, rbacv1.VerbAll, "create", "update", "patch") {
-			result.Add(errors.WarnInvalidObject("RBAC includes permission to create/update poddisruptionbudgets, which could impact cluster stability", rule))
+			result.Add(errors.WarnInvalidObject("RBAC includes permission to create/update poddisruptionbudgets, which could impact cluster stability", rule).WithCode(errors.RuleObjectRBACPodDisruptionBudgets))
 		}
 	}
 
@@ -163,7 +163,7 @@ func audit(policies []rbacv1.PolicyRule) (result errors.ManifestResult) {
 			contains(rule.Resources, "securitycontextconstraints") &&
 			contains(rule.Verbs, rbacv1.VerbAll, "delete", "update", "patch") &&
 			containsDefaults(rule.ResourceNames, defaultSCCs) {
-			result.Add(errors.ErrInvalidObject(rule, "RBAC includes permission to modify default securitycontextconstraints, which could impact cluster stability"))
+			result.Add(errors.ErrInvalidObject(rule, "RBAC includes permission to modify default securitycontextconstraints, which could impact cluster stability").WithCode(errors.RuleObjectRBACDefaultSCC))
 		}
 	}
 
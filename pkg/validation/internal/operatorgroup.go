@@ -24,12 +24,12 @@ func validateOperatorGroups(objs ...interface{}) (results []errors.ManifestResul
 
 func validateOperatorGroupV1Alpha2(operatorGroup *operatorsv1alpha2.OperatorGroup) (result errors.ManifestResult) {
 	// validate case sensitive annotation names
-	result.Add(ValidateAnnotationNames(operatorGroup.GetAnnotations(), operatorGroup.GetName())...)
+	result.Add(withFixTarget(ValidateAnnotationNames(operatorGroup.GetAnnotations(), operatorGroup.GetName()), operatorsv1.OperatorGroupKind, operatorGroup.GetName())...)
 	return result
 }
 
 func validateOperatorGroupV1(operatorGroup *operatorsv1.OperatorGroup) (result errors.ManifestResult) {
 	// validate case sensitive annotation names
-	result.Add(ValidateAnnotationNames(operatorGroup.GetAnnotations(), operatorGroup.GetName())...)
+	result.Add(withFixTarget(ValidateAnnotationNames(operatorGroup.GetAnnotations(), operatorGroup.GetName()), operatorsv1.OperatorGroupKind, operatorGroup.GetName())...)
 	return result
 }
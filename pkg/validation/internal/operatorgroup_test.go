@@ -26,7 +26,8 @@ func TestValidateOperatorGroup(t *testing.T) {
 				description: "invalid annotation name for operator group",
 				wantErr:     true,
 				errors: []errors.Error{
-					errors.ErrFailedValidation("provided annotation olm.providedapis uses wrong case and should be olm.providedAPIs instead", "nginx-hbvsw"),
+					errors.ErrFailedValidation("provided annotation olm.providedapis uses wrong case and should be olm.providedAPIs instead", "nginx-hbvsw").WithCode(errors.RuleAnnotationCase).
+						WithFix(annotationCaseFix(operatorsv1.OperatorGroupKind, "nginx-hbvsw", "olm.providedapis", "olm.providedAPIs")),
 				},
 			},
 			filepath.Join("testdata", "badAnnotationNames.og.yaml"),
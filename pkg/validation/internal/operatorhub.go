@@ -2,6 +2,7 @@ package internal
 
 import (
 	"encoding/json"
+	goerrors "errors"
 	"fmt"
 	"net/mail"
 	"net/url"
@@ -135,28 +136,20 @@ const minKubeVersionWarnMessage = "csv.Spec.minKubeVersion is not informed. It i
 // Warning: this validator is deprecated in favor of validateOperatorHub()
 func validateOperatorHubDeprecated(objs ...interface{}) (results []errors.ManifestResult) {
 
-	// Obtain the k8s version if informed via the objects an optional
-	k8sVersion := ""
-	for _, obj := range objs {
-		switch obj.(type) {
-		case map[string]string:
-			k8sVersion = obj.(map[string]string)[k8sVersionKey]
-			if len(k8sVersion) > 0 {
-				break
-			}
-		}
-	}
+	// Obtain the k8s version if informed via the optional values
+	opts, errs := optionsFrom(objs, K8sVersionKey)
+	k8sVersion := opts.K8sVersion
 
 	for _, obj := range objs {
 		switch v := obj.(type) {
 		case *manifests.Bundle:
-			results = append(results, validateBundleOperatorHub(v, k8sVersion))
+			results = append(results, withOptionsErrors(validateBundleOperatorHub(v, k8sVersion), errs))
 		}
 	}
 
 	// Add a deprecation warning to the list so that users are aware this validator is deprecated
 	deprecationResultWarning := errors.ManifestResult{}
-	deprecationResultWarning.Add(errors.WarnDeprecatedValidator(`The "operatorhub" validator is deprecated; for equivalent validation use "operatorhub/v2", "standardcapabilities" and "standardcategories" validators`))
+	deprecationResultWarning.Add(errors.WarnDeprecatedValidator(`The "operatorhub" validator is deprecated; for equivalent validation use "operatorhub-v2", "standard-capabilities" and "standard-categories" validators`).WithCode(errors.RuleOperatorHubDeprecated))
 	results = append(results, deprecationResultWarning)
 
 	return results
@@ -166,30 +159,30 @@ func validateBundleOperatorHub(bundle *manifests.Bundle, k8sVersion string) erro
 	result := errors.ManifestResult{}
 
 	if bundle == nil {
-		result.Add(errors.ErrInvalidBundle("Bundle is nil", nil))
+		result.Add(errors.ErrInvalidBundle("Bundle is nil", nil).WithCode(errors.RuleBundleMissing))
 		return result
 	}
 	result.Name = bundle.Name
 
 	if bundle.CSV == nil {
-		result.Add(errors.ErrInvalidBundle("Bundle csv is nil", bundle.Name))
+		result.Add(errors.ErrInvalidBundle("Bundle csv is nil", bundle.Name).WithCode(errors.RuleBundleMissing))
 		return result
 	}
 
 	csvChecksResult := validateHubCSVSpec(*bundle.CSV)
 	for _, err := range csvChecksResult.errs {
-		result.Add(errors.ErrInvalidCSV(err.Error(), bundle.CSV.GetName()))
+		result.Add(errors.ErrInvalidCSV(err.Error(), bundle.CSV.GetName()).WithCode(ruleOf(err)))
 	}
 	for _, warn := range csvChecksResult.warns {
-		result.Add(errors.WarnInvalidCSV(warn.Error(), bundle.CSV.GetName()))
+		result.Add(errors.WarnInvalidCSV(warn.Error(), bundle.CSV.GetName()).WithCode(ruleOf(warn)))
 	}
 
 	errs, warns := validateDeprecatedAPIS(bundle, k8sVersion)
 	for _, err := range errs {
-		result.Add(errors.ErrFailedValidation(err.Error(), bundle.CSV.GetName()))
+		result.Add(errors.ErrFailedValidation(err.Error(), bundle.CSV.GetName()).WithCode(ruleOf(err)))
 	}
 	for _, warn := range warns {
-		result.Add(errors.WarnFailedValidation(warn.Error(), bundle.CSV.GetName()))
+		result.Add(errors.WarnFailedValidation(warn.Error(), bundle.CSV.GetName()).WithCode(ruleOf(warn)))
 	}
 
 	return result
@@ -221,10 +214,10 @@ type CSVChecks struct {
 // checkSpecMinKubeVersion will validate the spec minKubeVersion informed via CSV.spec.minKubeVersion
 func checkSpecMinKubeVersion(checks CSVChecks) CSVChecks {
 	if len(strings.TrimSpace(checks.csv.Spec.MinKubeVersion)) == 0 {
-		checks.warns = append(checks.warns, fmt.Errorf(minKubeVersionWarnMessage))
+		checks.warns = append(checks.warns, withRule(errors.RuleOperatorHubMinKubeVersion, goerrors.New(minKubeVersionWarnMessage)))
 	} else {
 		if _, err := semver.Parse(checks.csv.Spec.MinKubeVersion); err != nil {
-			checks.errs = append(checks.errs, fmt.Errorf("csv.Spec.MinKubeVersion has an invalid value: %s", checks.csv.Spec.MinKubeVersion))
+			checks.errs = append(checks.errs, withRule(errors.RuleOperatorHubMinKubeVersionFormat, fmt.Errorf("csv.Spec.MinKubeVersion has an invalid value: %s", checks.csv.Spec.MinKubeVersion)))
 		}
 	}
 	return checks
@@ -234,7 +227,7 @@ func checkSpecMinKubeVersion(checks CSVChecks) CSVChecks {
 func checkSpecVersion(checks CSVChecks) CSVChecks {
 	// spec.Version needs to be set
 	if checks.csv.Spec.Version.Equals(semver.Version{}) {
-		checks.errs = append(checks.errs, fmt.Errorf("csv.Spec.Version must be set"))
+		checks.errs = append(checks.errs, withRule(errors.RuleOperatorHubVersionMissing, goerrors.New("csv.Spec.Version must be set")))
 	}
 	return checks
 }
@@ -244,21 +237,21 @@ func checkSpecIcon(checks CSVChecks) CSVChecks {
 	if checks.csv.Spec.Icon != nil {
 		// only one icon is allowed
 		if len(checks.csv.Spec.Icon) != 1 {
-			checks.errs = append(checks.errs, fmt.Errorf("csv.Spec.Icon should only have one element"))
+			checks.errs = append(checks.errs, withRule(errors.RuleOperatorHubIconCount, goerrors.New("csv.Spec.Icon should only have one element")))
 		}
 
 		icon := checks.csv.Spec.Icon[0]
 		if icon.MediaType == "" || icon.Data == "" {
-			checks.errs = append(checks.errs, fmt.Errorf("csv.Spec.Icon elements should contain both data and mediatype"))
+			checks.errs = append(checks.errs, withRule(errors.RuleOperatorHubIconIncomplete, goerrors.New("csv.Spec.Icon elements should contain both data and mediatype")))
 		}
 
 		if icon.MediaType != "" {
 			if _, ok := validMediatypes[icon.MediaType]; !ok {
-				checks.errs = append(checks.errs, fmt.Errorf("csv.Spec.Icon %s does not have a valid mediatype", icon.MediaType))
+				checks.errs = append(checks.errs, withRule(errors.RuleOperatorHubIconMediaType, fmt.Errorf("csv.Spec.Icon %s does not have a valid mediatype", icon.MediaType)))
 			}
 		}
 	} else {
-		checks.warns = append(checks.warns, fmt.Errorf("csv.Spec.Icon not specified"))
+		checks.warns = append(checks.warns, withRule(errors.RuleOperatorHubIconMissing, goerrors.New("csv.Spec.Icon not specified")))
 	}
 	return checks
 }
@@ -267,12 +260,12 @@ func checkSpecIcon(checks CSVChecks) CSVChecks {
 func checkSpecLinks(checks CSVChecks) CSVChecks {
 	for _, link := range checks.csv.Spec.Links {
 		if link.Name == "" || link.URL == "" {
-			checks.errs = append(checks.errs, fmt.Errorf("csv.Spec.Links elements should contain both name and url"))
+			checks.errs = append(checks.errs, withRule(errors.RuleOperatorHubLinkIncomplete, goerrors.New("csv.Spec.Links elements should contain both name and url")))
 		}
 		if link.URL != "" {
 			_, err := url.ParseRequestURI(link.URL)
 			if err != nil {
-				checks.errs = append(checks.errs, fmt.Errorf("csv.Spec.Links url %s is invalid: %v", link.URL, err))
+				checks.errs = append(checks.errs, withRule(errors.RuleOperatorHubLinkURL, fmt.Errorf("csv.Spec.Links url %s is invalid: %v", link.URL, err)))
 			}
 		}
 	}
@@ -283,12 +276,12 @@ func checkSpecLinks(checks CSVChecks) CSVChecks {
 func checkSpecMaintainers(checks CSVChecks) CSVChecks {
 	for _, maintainer := range checks.csv.Spec.Maintainers {
 		if maintainer.Name == "" || maintainer.Email == "" {
-			checks.errs = append(checks.errs, fmt.Errorf("csv.Spec.Maintainers elements should contain both name and email"))
+			checks.errs = append(checks.errs, withRule(errors.RuleOperatorHubMaintainerIncomplete, goerrors.New("csv.Spec.Maintainers elements should contain both name and email")))
 		}
 		if maintainer.Email != "" {
 			_, err := mail.ParseAddress(maintainer.Email)
 			if err != nil {
-				checks.errs = append(checks.errs, fmt.Errorf("csv.Spec.Maintainers email %s is invalid: %v", maintainer.Email, err))
+				checks.errs = append(checks.errs, withRule(errors.RuleOperatorHubMaintainerEmail, fmt.Errorf("csv.Spec.Maintainers email %s is invalid: %v", maintainer.Email, err)))
 			}
 		}
 	}
@@ -298,7 +291,7 @@ func checkSpecMaintainers(checks CSVChecks) CSVChecks {
 // checkSpecProviderName will validate the values informed via csv.Spec.Provider.Name
 func checkSpecProviderName(checks CSVChecks) CSVChecks {
 	if strings.TrimSpace(checks.csv.Spec.Provider.Name) == "" {
-		checks.errs = append(checks.errs, fmt.Errorf("csv.Spec.Provider.Name not specified"))
+		checks.errs = append(checks.errs, withRule(errors.RuleOperatorHubProviderMissing, goerrors.New("csv.Spec.Provider.Name not specified")))
 	}
 	return checks
 }
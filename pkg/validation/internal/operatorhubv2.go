@@ -10,22 +10,14 @@ import (
 var OperatorHubV2Validator interfaces.Validator = interfaces.ValidatorFunc(validateOperatorHubV2)
 
 func validateOperatorHubV2(objs ...interface{}) (results []errors.ManifestResult) {
-	// Obtain the k8s version if informed via the objects an optional
-	k8sVersion := ""
-	for _, obj := range objs {
-		switch obj.(type) {
-		case map[string]string:
-			k8sVersion = obj.(map[string]string)[k8sVersionKey]
-			if len(k8sVersion) > 0 {
-				break
-			}
-		}
-	}
+	// Obtain the k8s version if informed via the optional values
+	opts, errs := optionsFrom(objs, K8sVersionKey)
+	k8sVersion := opts.K8sVersion
 
 	for _, obj := range objs {
 		switch v := obj.(type) {
 		case *manifests.Bundle:
-			results = append(results, validateBundleOperatorHubV2(v, k8sVersion))
+			results = append(results, withOptionsErrors(validateBundleOperatorHubV2(v, k8sVersion), errs))
 		}
 	}
 
@@ -35,30 +27,30 @@ func validateOperatorHubV2(objs ...interface{}) (results []errors.ManifestResult
 func validateBundleOperatorHubV2(bundle *manifests.Bundle, k8sVersion string) errors.ManifestResult {
 	result := errors.ManifestResult{}
 	if bundle == nil {
-		result.Add(errors.ErrInvalidBundle("Bundle is nil", nil))
+		result.Add(errors.ErrInvalidBundle("Bundle is nil", nil).WithCode(errors.RuleBundleMissing))
 		return result
 	}
 	result.Name = bundle.Name
 
 	if bundle.CSV == nil {
-		result.Add(errors.ErrInvalidBundle("Bundle csv is nil", bundle.Name))
+		result.Add(errors.ErrInvalidBundle("Bundle csv is nil", bundle.Name).WithCode(errors.RuleBundleMissing))
 		return result
 	}
 
 	csvChecksResult := validateHubCSVSpecV2(*bundle.CSV)
 	for _, err := range csvChecksResult.errs {
-		result.Add(errors.ErrInvalidCSV(err.Error(), bundle.CSV.GetName()))
+		result.Add(errors.ErrInvalidCSV(err.Error(), bundle.CSV.GetName()).WithCode(ruleOf(err)))
 	}
 	for _, warn := range csvChecksResult.warns {
-		result.Add(errors.WarnInvalidCSV(warn.Error(), bundle.CSV.GetName()))
+		result.Add(errors.WarnInvalidCSV(warn.Error(), bundle.CSV.GetName()).WithCode(ruleOf(warn)))
 	}
 
 	errs, warns := validateDeprecatedAPIS(bundle, k8sVersion)
 	for _, err := range errs {
-		result.Add(errors.ErrFailedValidation(err.Error(), bundle.CSV.GetName()))
+		result.Add(errors.ErrFailedValidation(err.Error(), bundle.CSV.GetName()).WithCode(ruleOf(err)))
 	}
 	for _, warn := range warns {
-		result.Add(errors.WarnFailedValidation(warn.Error(), bundle.CSV.GetName()))
+		result.Add(errors.WarnFailedValidation(warn.Error(), bundle.CSV.GetName()).WithCode(ruleOf(warn)))
 	}
 
 	return result
@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/validation/errors"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Options are the optional values accepted by the validators. They are passed
+// to the validators among the objects to validate, as an Options or *Options.
+// For compatibility, a map[string]string keyed by K8sVersionKey,
+// ContainerToolsKey and IndexImagePathKey is also accepted.
+type Options struct {
+	// K8sVersion is the Kubernetes version the bundle is intended to be used with, e.g. 1.22.
+	K8sVersion string
+	// ContainerTools is the tool used to inspect images, one of: docker, podman, none.
+	ContainerTools string
+	// IndexImagePath is the path of the index image Dockerfile to be checked.
+	IndexImagePath string
+}
+
+// option declares a field of Options along with its key.
+type option struct {
+	key string
+	// field returns the field of the option in o.
+	field func(o *Options) *string
+	// validate returns an error if value is invalid, if set.
+	validate func(value string) error
+}
+
+var optionsDeclared = []option{
+	{
+		key:   K8sVersionKey,
+		field: func(o *Options) *string { return &o.K8sVersion },
+		validate: func(value string) error {
+			return withRule(errors.RuleRemovedAPIsK8sVersionInvalid, verifyK8sVersionInformed(value))
+		},
+	},
+	{
+		key:   ContainerToolsKey,
+		field: func(o *Options) *string { return &o.ContainerTools },
+		validate: func(value string) error {
+			_, err := validateContainerTool(strings.ToLower(value))
+			return withRule(errors.RuleMultiArchContainerTool, err)
+		},
+	},
+	{
+		key:   IndexImagePathKey,
+		field: func(o *Options) *string { return &o.IndexImagePath },
+	},
+}
+
+func lookupOption(key string) (option, bool) {
+	for _, opt := range optionsDeclared {
+		if opt.key == key {
+			return opt, true
+		}
+	}
+	return option{}, false
+}
+
+// OptionKeys returns the keys of the optional values accepted by the validators.
+func OptionKeys() []string {
+	keys := make([]string, 0, len(optionsDeclared))
+	for _, opt := range optionsDeclared {
+		keys = append(keys, opt.key)
+	}
+	return keys
+}
+
+// ParseOptions converts optional values keyed by K8sVersionKey, ContainerToolsKey
+// and IndexImagePathKey to Options. An error is returned for unknown keys and
+// invalid values.
+func ParseOptions(values map[string]string) (Options, error) {
+	opts, errs := decodeOptions(values)
+	if err := opts.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	return opts, utilerrors.NewAggregate(errs)
+}
+
+// Validate returns an error if a value of o is invalid.
+func (o Options) Validate() error {
+	return utilerrors.NewAggregate(o.validate(OptionKeys()...))
+}
+
+// validate returns an error for each invalid value among the options with keys.
+func (o Options) validate(keys ...string) (errs []error) {
+	for _, key := range keys {
+		opt, ok := lookupOption(key)
+		if !ok || opt.validate == nil {
+			continue
+		}
+		if value := *opt.field(&o); value != "" {
+			if err := opt.validate(value); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// decodeOptions converts values to Options, returning an error for each unknown key.
+func decodeOptions(values map[string]string) (opts Options, errs []error) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		opt, ok := lookupOption(key)
+		if !ok {
+			errs = append(errs, withRule(errors.RuleOptionsUnknownKey, fmt.Errorf("unknown optional key %q, must be one of: %s",
+				key, strings.Join(OptionKeys(), ", "))))
+			continue
+		}
+		*opt.field(&opts) = values[key]
+	}
+	return opts, errs
+}
+
+// merge returns o with the values set in other.
+func (o Options) merge(other Options) Options {
+	for _, opt := range optionsDeclared {
+		if value := *opt.field(&other); value != "" {
+			*opt.field(&o) = value
+		}
+	}
+	return o
+}
+
+// optionsFrom returns the options found among objs. Errors are returned for
+// the unknown keys of optional values passed as a map, and for the invalid
+// values of the options with keys, which are the options used by the caller.
+// Invalid values are left unset, for the caller to use its defaults.
+func optionsFrom(objs []interface{}, keys ...string) (opts Options, errs []error) {
+	for _, obj := range objs {
+		switch v := obj.(type) {
+		case Options:
+			opts = opts.merge(v)
+		case *Options:
+			if v != nil {
+				opts = opts.merge(*v)
+			}
+		case map[string]string:
+			decoded, decodeErrs := decodeOptions(v)
+			opts = opts.merge(decoded)
+			errs = append(errs, decodeErrs...)
+		}
+	}
+	for _, key := range keys {
+		if invalid := opts.validate(key); len(invalid) > 0 {
+			errs = append(errs, invalid...)
+			opt, _ := lookupOption(key)
+			*opt.field(&opts) = ""
+		}
+	}
+	return opts, errs
+}
+
+// withOptionsErrors returns result, the result of a bundle, with the errors
+// found in the optional values.
+func withOptionsErrors(result errors.ManifestResult, errs []error) errors.ManifestResult {
+	for _, err := range errs {
+		result.Add(errors.ErrFailedValidation(err.Error(), result.Name).WithCode(ruleOf(err)))
+	}
+	return result
+}
@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/validation/errors"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]string
+		want    Options
+		wantErr string
+	}{
+		{
+			name:   "empty",
+			values: nil,
+		},
+		{
+			name: "all keys",
+			values: map[string]string{
+				K8sVersionKey:     "1.22",
+				ContainerToolsKey: "Podman",
+				IndexImagePathKey: "./bundle.Dockerfile",
+			},
+			want: Options{K8sVersion: "1.22", ContainerTools: "Podman", IndexImagePath: "./bundle.Dockerfile"},
+		},
+		{
+			name:    "unknown key",
+			values:  map[string]string{"k8s-verison": "1.22"},
+			wantErr: `unknown optional key "k8s-verison", must be one of: k8s-version, container-tools, index-path`,
+		},
+		{
+			name:    "invalid k8s version",
+			values:  map[string]string{K8sVersionKey: "invalid"},
+			want:    Options{K8sVersion: "invalid"},
+			wantErr: "invalid value informed via the k8s key option : invalid",
+		},
+		{
+			name:    "invalid container tool",
+			values:  map[string]string{ContainerToolsKey: "buildah"},
+			want:    Options{ContainerTools: "buildah"},
+			wantErr: "invalid value (container-tools) for (buildah). One of: [docker, podman, none] (If not set, the default value is docker)",
+		},
+		{
+			name:    "several errors",
+			values:  map[string]string{K8sVersionKey: "invalid", "unknown": "value"},
+			want:    Options{K8sVersion: "invalid"},
+			wantErr: `[unknown optional key "unknown", must be one of: k8s-version, container-tools, index-path, invalid value informed via the k8s key option : invalid]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOptions(tt.values)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOptionsFrom(t *testing.T) {
+	objs := []interface{}{
+		&manifests.Bundle{},
+		map[string]string{K8sVersionKey: "1.22", ContainerToolsKey: "invalid"},
+		&Options{IndexImagePath: "./bundle.Dockerfile"},
+		Options{K8sVersion: "1.25"},
+	}
+
+	opts, errs := optionsFrom(objs, K8sVersionKey, IndexImagePathKey)
+	require.Empty(t, errs)
+	require.Equal(t, Options{K8sVersion: "1.25", ContainerTools: "invalid", IndexImagePath: "./bundle.Dockerfile"}, opts)
+
+	// Only the values of the options used by the caller are validated, and
+	// the invalid ones are left unset.
+	opts, errs = optionsFrom(objs, ContainerToolsKey)
+	require.Len(t, errs, 1)
+	require.Equal(t, errors.RuleMultiArchContainerTool, ruleOf(errs[0]))
+	require.Empty(t, opts.ContainerTools)
+
+	_, errs = optionsFrom([]interface{}{map[string]string{"container-tool": "docker"}})
+	require.Len(t, errs, 1)
+	require.Equal(t, errors.RuleOptionsUnknownKey, ruleOf(errs[0]))
+}
+
+func TestValidatorsWithOptions(t *testing.T) {
+	bundle, err := manifests.GetBundleFromDir("./testdata/valid_bundle_v1beta1")
+	require.NoError(t, err)
+
+	// Typed options and their map form are equivalent.
+	require.Equal(t,
+		AlphaDeprecatedAPIsValidator.Validate(bundle, map[string]string{K8sVersionKey: "1.22"}),
+		AlphaDeprecatedAPIsValidator.Validate(bundle, Options{K8sVersion: "1.22"}),
+	)
+
+	results := AlphaDeprecatedAPIsValidator.Validate(bundle, map[string]string{"k8s-verison": "1.22"})
+	require.Len(t, results, 1)
+	require.Equal(t, bundle.Name, results[0].Name)
+	require.Equal(t, []errors.Error{
+		errors.ErrFailedValidation(`unknown optional key "k8s-verison", must be one of: k8s-version, container-tools, index-path`, bundle.Name).
+			WithCode(errors.RuleOptionsUnknownKey),
+	}, results[0].Errors)
+
+	results = OperatorHubV2Validator.Validate(bundle, Options{K8sVersion: "invalid"})
+	require.Len(t, results, 1)
+	require.Equal(t, []errors.Error{
+		errors.ErrFailedValidation("invalid value informed via the k8s key option : invalid", bundle.Name).
+			WithCode(errors.RuleRemovedAPIsK8sVersionInvalid),
+	}, results[0].Errors)
+}
+
+func TestValidatorsWithOptionsErrors(t *testing.T) {
+	bundle, err := manifests.GetBundleFromDir("./testdata/valid_bundle_v1beta1")
+	require.NoError(t, err)
+	bundle.CSV.Spec.Provider.Name = ""
+
+	// The bundle is still validated when an optional value is invalid.
+	results := OperatorHubV2Validator.Validate(bundle, map[string]string{K8sVersionKey: "invalid"})
+	require.Len(t, results, 1)
+	var codes []errors.RuleCode
+	for _, e := range results[0].Errors {
+		codes = append(codes, e.Code)
+	}
+	require.ElementsMatch(t, []errors.RuleCode{errors.RuleOperatorHubProviderMissing, errors.RuleRemovedAPIsK8sVersionInvalid}, codes)
+}
@@ -28,32 +28,32 @@ func validatePackageManifest(pkg *manifests.PackageManifest) errors.ManifestResu
 
 func validateChannels(pkg *manifests.PackageManifest) (errs []errors.Error) {
 	if pkg.PackageName == "" {
-		errs = append(errs, errors.ErrInvalidPackageManifest("packageName empty", pkg.PackageName))
+		errs = append(errs, errors.ErrInvalidPackageManifest("packageName empty", pkg.PackageName).WithCode(errors.RulePackageManifestNameMissing))
 	}
 	numChannels := len(pkg.Channels)
 	if numChannels == 0 {
-		errs = append(errs, errors.ErrInvalidPackageManifest("channels empty", pkg.PackageName))
+		errs = append(errs, errors.ErrInvalidPackageManifest("channels empty", pkg.PackageName).WithCode(errors.RulePackageManifestChannelsMissing))
 		return errs
 	}
 	if pkg.DefaultChannelName == "" && numChannels > 1 {
-		errs = append(errs, errors.ErrInvalidPackageManifest("default channel is empty but more than one channel exists", pkg.PackageName))
+		errs = append(errs, errors.ErrInvalidPackageManifest("default channel is empty but more than one channel exists", pkg.PackageName).WithCode(errors.RulePackageManifestDefaultChannel))
 	}
 
 	seen := map[string]struct{}{}
 	for i, c := range pkg.Channels {
 		if c.Name == "" {
-			errs = append(errs, errors.ErrInvalidPackageManifest(fmt.Sprintf("channel %d name is empty", i), pkg.PackageName))
+			errs = append(errs, errors.ErrInvalidPackageManifest(fmt.Sprintf("channel %d name is empty", i), pkg.PackageName).WithCode(errors.RulePackageManifestChannelInvalid))
 		}
 		if c.CurrentCSVName == "" {
-			errs = append(errs, errors.ErrInvalidPackageManifest(fmt.Sprintf("channel %q currentCSV is empty", c.Name), pkg.PackageName))
+			errs = append(errs, errors.ErrInvalidPackageManifest(fmt.Sprintf("channel %q currentCSV is empty", c.Name), pkg.PackageName).WithCode(errors.RulePackageManifestChannelInvalid))
 		}
 		if _, ok := seen[c.Name]; ok {
-			errs = append(errs, errors.ErrInvalidPackageManifest(fmt.Sprintf("duplicate package manifest channel name %q", c.Name), pkg.PackageName))
+			errs = append(errs, errors.ErrInvalidPackageManifest(fmt.Sprintf("duplicate package manifest channel name %q", c.Name), pkg.PackageName).WithCode(errors.RulePackageManifestChannelInvalid))
 		}
 		seen[c.Name] = struct{}{}
 	}
 	if _, found := seen[pkg.DefaultChannelName]; pkg.DefaultChannelName != "" && !found {
-		errs = append(errs, errors.ErrInvalidPackageManifest(fmt.Sprintf("default channel %q not found in the list of declared channels", pkg.DefaultChannelName), pkg.PackageName))
+		errs = append(errs, errors.ErrInvalidPackageManifest(fmt.Sprintf("default channel %q not found in the list of declared channels", pkg.DefaultChannelName), pkg.PackageName).WithCode(errors.RulePackageManifestDefaultChannel))
 	}
 
 	return errs
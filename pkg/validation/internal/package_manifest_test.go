@@ -42,7 +42,7 @@ func TestValidatePackageManifest(t *testing.T) {
 				description: "no default channel and more than one channel",
 				wantErr:     true,
 				errors: []errors.Error{
-					errors.ErrInvalidPackageManifest("default channel is empty but more than one channel exists", pkgName),
+					errors.ErrInvalidPackageManifest("default channel is empty but more than one channel exists", pkgName).WithCode(errors.RulePackageManifestDefaultChannel),
 				},
 			},
 			&manifests.PackageManifest{
@@ -58,7 +58,7 @@ func TestValidatePackageManifest(t *testing.T) {
 				description: "default channel does not exist in channels",
 				wantErr:     true,
 				errors: []errors.Error{
-					errors.ErrInvalidPackageManifest(`default channel "baz" not found in the list of declared channels`, pkgName),
+					errors.ErrInvalidPackageManifest(`default channel "baz" not found in the list of declared channels`, pkgName).WithCode(errors.RulePackageManifestDefaultChannel),
 				},
 			},
 			&manifests.PackageManifest{
@@ -74,7 +74,7 @@ func TestValidatePackageManifest(t *testing.T) {
 				description: "channels are empty",
 				wantErr:     true,
 				errors: []errors.Error{
-					errors.ErrInvalidPackageManifest("channels empty", pkgName),
+					errors.ErrInvalidPackageManifest("channels empty", pkgName).WithCode(errors.RulePackageManifestChannelsMissing),
 				},
 			},
 			&manifests.PackageManifest{
@@ -88,7 +88,7 @@ func TestValidatePackageManifest(t *testing.T) {
 				description: "one channel's CSVName is empty",
 				wantErr:     true,
 				errors: []errors.Error{
-					errors.ErrInvalidPackageManifest(`channel "foo" currentCSV is empty`, pkgName),
+					errors.ErrInvalidPackageManifest(`channel "foo" currentCSV is empty`, pkgName).WithCode(errors.RulePackageManifestChannelInvalid),
 				},
 			},
 			&manifests.PackageManifest{
@@ -102,7 +102,7 @@ func TestValidatePackageManifest(t *testing.T) {
 				description: "duplicate channel name",
 				wantErr:     true,
 				errors: []errors.Error{
-					errors.ErrInvalidPackageManifest(`duplicate package manifest channel name "foo"`, pkgName),
+					errors.ErrInvalidPackageManifest(`duplicate package manifest channel name "foo"`, pkgName).WithCode(errors.RulePackageManifestChannelInvalid),
 				},
 			},
 			&manifests.PackageManifest{
@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"context"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/operator-framework/api/pkg/validation/errors"
+	interfaces "github.com/operator-framework/api/pkg/validation/interfaces"
+)
+
+// WithPositions returns v, setting the Position of the findings of its
+// results with AttachPositions, for the bundles among the validated objects.
+// The results of a bundle are the ones named after it, its CSV or the version
+// of its CSV, or all of them if there is only one bundle.
+func WithPositions(v interfaces.Validator) interfaces.Validator {
+	return interfaces.ContextValidatorFunc(func(ctx context.Context, objs ...interface{}) []errors.ManifestResult {
+		results := interfaces.WithContext(v).ValidateContext(ctx, objs...)
+		var bundles []*manifests.Bundle
+		for _, obj := range objs {
+			if bundle, ok := obj.(*manifests.Bundle); ok && bundle != nil && bundle.Sources != nil {
+				bundles = append(bundles, bundle)
+			}
+		}
+		switch len(bundles) {
+		case 0:
+			return results
+		case 1:
+			return AttachPositions(bundles[0], results)
+		}
+		positioned := make([]errors.ManifestResult, 0, len(results))
+		for _, result := range results {
+			for _, bundle := range bundles {
+				if namedAfter(bundle, result.Name) {
+					result = AttachPositions(bundle, []errors.ManifestResult{result})[0]
+					break
+				}
+			}
+			positioned = append(positioned, result)
+		}
+		return positioned
+	})
+}
+
+// AttachPositions returns results with the Position of their findings set to
+// the file, line and column they were found at in the manifests of bundle,
+// as recorded in bundle.Sources. Findings are positioned at the value patched
+// by their Fix, if any, else at their Field in the object named by their
+// BadValue or, failing that, in the object the result is named after.
+// Findings already positioned, or whose object is not found, are left as is.
+func AttachPositions(bundle *manifests.Bundle, results []errors.ManifestResult) []errors.ManifestResult {
+	if bundle == nil || bundle.Sources == nil {
+		return results
+	}
+	positioned := make([]errors.ManifestResult, 0, len(results))
+	for _, result := range results {
+		r := errors.ManifestResult{Name: result.Name}
+		for _, err := range append(append([]errors.Error{}, result.Errors...), result.Warnings...) {
+			if err.Position == nil {
+				if pos, ok := findingPosition(bundle, result.Name, err); ok {
+					err = err.WithPosition(errors.Position{File: pos.File, Line: pos.Line, Column: pos.Column})
+				}
+			}
+			r.Add(err)
+		}
+		positioned = append(positioned, r)
+	}
+	return positioned
+}
+
+func findingPosition(bundle *manifests.Bundle, name string, err errors.Error) (manifests.Position, bool) {
+	if err.Fix != nil && len(err.Fix.Patch) > 0 {
+		op := err.Fix.Patch[0]
+		path := op.Path
+		if op.From != "" {
+			path = op.From
+		}
+		if pos, ok := bundle.Sources.Lookup(err.Fix.Kind, err.Fix.Name, path); ok {
+			return pos, true
+		}
+	}
+
+	field := fieldPath(err.Field)
+	// Findings about another object of the bundle, ex. a ServiceAccount, name it
+	// as their BadValue.
+	if value, ok := err.BadValue.(string); ok && value != "" && value != name {
+		if pos, ok := bundle.Sources.Lookup("", value, field); ok {
+			return pos, true
+		}
+	}
+	// Results of the checks of the whole bundle are named after the bundle, its
+	// CSV or the version of its CSV, and their findings are found in the CSV.
+	kind := ""
+	if bundle.CSV != nil && namedAfter(bundle, name) {
+		kind, name = v1alpha1.ClusterServiceVersionKind, bundle.CSV.GetName()
+	}
+	return bundle.Sources.Lookup(kind, name, field)
+}
+
+// namedAfter returns true if name is the name of bundle, or the name or the
+// version of its CSV.
+func namedAfter(bundle *manifests.Bundle, name string) bool {
+	if name == bundle.Name {
+		return true
+	}
+	csv := bundle.CSV
+	return csv != nil && (name == csv.GetName() || name == csv.Spec.Version.String())
+}
+
+// fieldPath returns the path of the manifest field of err.Field, which is
+// either a YAML path or, for the checks of required CSV fields, the path of
+// the Go struct field, ex. "ObjectMeta.Name".
+func fieldPath(field string) string {
+	field = strings.TrimPrefix(field, "TypeMeta.")
+	if field == "ObjectMeta" || strings.HasPrefix(field, "ObjectMeta.") {
+		field = "metadata" + strings.TrimPrefix(field, "ObjectMeta")
+	}
+	return field
+}
@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/validation/errors"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachPositions(t *testing.T) {
+	bundle, err := manifests.GetBundleFromDir("./testdata/valid_bundle_v1")
+	require.NoError(t, err)
+	csvFile := filepath.Join("testdata", "valid_bundle_v1", "memcached-operator.clusterserviceversion.yaml")
+	saFile := filepath.Join("testdata", "valid_bundle_v1", "memcached-operator-controller-manager_v1_serviceaccount.yaml")
+
+	positioned := errors.Position{File: "elsewhere.yaml", Line: 3}
+	results := []errors.ManifestResult{
+		validateGoodPracticesFrom(bundle),
+		{
+			Name: "0.0.1",
+			Errors: []errors.Error{
+				errors.ErrFieldMissing("required field missing", "ObjectMeta.Annotations", "struct"),
+				errors.ErrInvalidBundle("invalid service account found in bundle", "memcached-operator-controller-manager"),
+				errors.ErrInvalidBundle("already positioned", nil).WithPosition(positioned),
+			},
+		},
+		{
+			Name:     "unknown",
+			Warnings: []errors.Error{errors.WarnInvalidBundle("not found", nil)},
+		},
+	}
+
+	got := AttachPositions(bundle, results)
+	require.Len(t, got, 3)
+	require.Len(t, got[0].Warnings, 2)
+	require.Equal(t, &errors.Position{File: csvFile, Line: 122, Column: 17}, got[0].Warnings[0].Position, "fix adding to kube-rbac-proxy resources")
+	require.Equal(t, &errors.Position{File: csvFile, Line: 123, Column: 17}, got[0].Warnings[1].Position, "fix adding resources to the manager container")
+	require.Equal(t, &errors.Position{File: csvFile, Line: 4, Column: 3}, got[1].Errors[0].Position)
+	require.Equal(t, &errors.Position{File: saFile, Line: 1, Column: 1}, got[1].Errors[1].Position)
+	require.Equal(t, &positioned, got[1].Errors[2].Position)
+	require.Nil(t, got[2].Warnings[0].Position)
+	require.Nil(t, results[1].Errors[0].Position, "results must not be modified")
+
+	require.Equal(t, results, AttachPositions(&manifests.Bundle{}, results))
+}
+
+func TestWithPositions(t *testing.T) {
+	memcached, err := manifests.GetBundleFromDir("./testdata/valid_bundle_v1")
+	require.NoError(t, err)
+	etcd, err := manifests.GetBundleFromDir("./testdata/valid_bundle")
+	require.NoError(t, err)
+	validator := WithPositions(GoodPracticesValidator)
+
+	results := validator.Validate(memcached)
+	require.Len(t, results, 1)
+	require.Equal(t, AttachPositions(memcached, GoodPracticesValidator.Validate(memcached)), results)
+	require.NotEmpty(t, results[0].Warnings)
+	require.NotNil(t, results[0].Warnings[0].Position)
+
+	// The findings of each bundle are found in its own manifests.
+	results = validator.Validate(memcached, etcd)
+	require.Len(t, results, 2)
+	for i, dir := range []string{"valid_bundle_v1", "valid_bundle"} {
+		require.NotEmpty(t, results[i].Warnings)
+		for _, w := range results[i].Warnings {
+			require.NotNil(t, w.Position, w.Detail)
+			require.Equal(t, filepath.Join("testdata", dir), filepath.Dir(w.Position.File), w.Detail)
+		}
+	}
+
+	// Objects which are not bundles loaded from manifests are not positioned.
+	results = validator.Validate(&manifests.Bundle{Name: "memcached-operator.v0.0.1", CSV: memcached.CSV})
+	require.Len(t, results, 1)
+	for _, w := range results[0].Warnings {
+		require.Nil(t, w.Position)
+	}
+}
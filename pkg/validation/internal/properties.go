@@ -0,0 +1,146 @@
+package internal
+
+import (
+	goerrors "errors"
+	"fmt"
+
+	"github.com/blang/semver/v4"
+
+	"github.com/operator-framework/api/pkg/constraints"
+	"github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/validation/errors"
+)
+
+// validateProperties checks that the properties and dependencies declared by
+// the metadata files of the bundle are well formed.
+func validateProperties(bundle *manifests.Bundle) []errors.Error {
+	var errs []errors.Error
+	for i, p := range bundle.Properties {
+		field := fmt.Sprintf("properties[%d]", i)
+		if p.Type == "" {
+			errs = append(errs, propertyError(errors.RuleBundlePropertyInvalid, "property has no type", field, p.Type))
+			continue
+		}
+		value, err := p.Parse()
+		if err != nil {
+			errs = append(errs, propertyError(constraintRule(errors.RuleBundlePropertyInvalid, err), err.Error(), field, p.Type))
+			continue
+		}
+		if c, ok := value.(constraints.Constraint); ok {
+			errs = append(errs, constraintErrors(c, errors.RuleBundlePropertyInvalid, "property", field, p.Type)...)
+			continue
+		}
+		for _, problem := range propertyValueProblems(value) {
+			errs = append(errs, propertyError(errors.RuleBundlePropertyInvalid, fmt.Sprintf("invalid %s property: %s", p.Type, problem), field, p.Type))
+		}
+	}
+	for i, d := range bundle.Dependencies {
+		field := fmt.Sprintf("dependencies[%d]", i)
+		if d.Type == "" {
+			errs = append(errs, propertyError(errors.RuleBundleDependencyInvalid, "dependency has no type", field, d.Type))
+			continue
+		}
+		value, err := d.Parse()
+		if err != nil {
+			errs = append(errs, propertyError(constraintRule(errors.RuleBundleDependencyInvalid, err), err.Error(), field, d.Type))
+			continue
+		}
+		if c, ok := value.(constraints.Constraint); ok {
+			errs = append(errs, constraintErrors(c, errors.RuleBundleDependencyInvalid, "dependency", field, d.Type)...)
+			continue
+		}
+		for _, problem := range propertyValueProblems(value) {
+			errs = append(errs, propertyError(errors.RuleBundleDependencyInvalid, fmt.Sprintf("invalid %s dependency: %s", d.Type, problem), field, d.Type))
+		}
+	}
+	return errs
+}
+
+func propertyError(code errors.RuleCode, detail, field, typ string) errors.Error {
+	return errors.NewError(errors.ErrorInvalidBundle, detail, field, typ).WithCode(code)
+}
+
+func propertyWarning(code errors.RuleCode, detail, field, typ string) errors.Error {
+	return errors.NewWarn(errors.ErrorInvalidBundle, detail, field, typ).WithCode(code)
+}
+
+// constraintRule returns the rule of the error err of parsing a property or
+// dependency, which is code unless the constraint is too large.
+func constraintRule(code errors.RuleCode, err error) errors.RuleCode {
+	if goerrors.Is(err, constraints.ErrMaxConstraintSizeExceeded) {
+		return errors.RuleBundleConstraintTooLarge
+	}
+	return code
+}
+
+// propertyValueProblems returns the problems of the typed value of a property or dependency.
+func propertyValueProblems(value interface{}) []string {
+	var problems []string
+	switch v := value.(type) {
+	case manifests.PackageProperty:
+		if v.PackageName == "" {
+			problems = append(problems, "packageName is empty")
+		}
+		if _, err := semver.Parse(v.Version); err != nil {
+			problems = append(problems, fmt.Sprintf("version %q is not a valid semantic version: %v", v.Version, err))
+		}
+	case manifests.PackageRequiredProperty:
+		problems = append(problems, packageRangeProblems(v.PackageName, "versionRange", v.VersionRange)...)
+	case manifests.PackageDependency:
+		problems = append(problems, packageRangeProblems(v.PackageName, "version", v.Version)...)
+	case manifests.GVKProperty:
+		problems = append(problems, gvkProblems(v.Kind, v.Version)...)
+	case manifests.LabelProperty:
+		if v.Label == "" {
+			problems = append(problems, "label is empty")
+		}
+	case string:
+		// olm.maxOpenShiftVersion
+		if _, err := semver.ParseTolerant(v); err != nil {
+			problems = append(problems, fmt.Sprintf("%q is not a valid version: %v", v, err))
+		}
+	}
+	return problems
+}
+
+func packageRangeProblems(packageName, field, versionRange string) []string {
+	var problems []string
+	if packageName == "" {
+		problems = append(problems, "packageName is empty")
+	}
+	if _, err := semver.ParseRange(versionRange); err != nil {
+		problems = append(problems, fmt.Sprintf("%s %q is not a valid semantic version range: %v", field, versionRange, err))
+	}
+	return problems
+}
+
+func gvkProblems(kind, version string) []string {
+	var problems []string
+	if kind == "" {
+		problems = append(problems, "kind is empty")
+	}
+	if version == "" {
+		problems = append(problems, "version is empty")
+	}
+	return problems
+}
+
+// constraintErrors returns the problems found by constraints.Lint in the
+// constraint c of the property or dependency, what, at field. The invalid
+// constraints are reported with the rule code.
+func constraintErrors(c constraints.Constraint, code errors.RuleCode, what, field, typ string) []errors.Error {
+	var errs []errors.Error
+	for _, problem := range constraints.Lint(c) {
+		switch problem.Kind {
+		case constraints.ProblemInvalid:
+			errs = append(errs, propertyError(code, fmt.Sprintf("invalid %s %s: %s", typ, what, problem), field, typ))
+		case constraints.ProblemUnsatisfiable:
+			errs = append(errs, propertyError(errors.RuleBundleConstraintUnsatisfiable, fmt.Sprintf("%s %s: %s", typ, what, problem), field, typ))
+		case constraints.ProblemAlwaysSatisfied:
+			errs = append(errs, propertyWarning(errors.RuleBundleConstraintAlwaysSatisfied, fmt.Sprintf("%s %s: %s", typ, what, problem), field, typ))
+		case constraints.ProblemRedundant:
+			errs = append(errs, propertyWarning(errors.RuleBundleConstraintRedundant, fmt.Sprintf("%s %s: %s", typ, what, problem), field, typ))
+		}
+	}
+	return errs
+}
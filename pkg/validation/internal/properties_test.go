@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/validation/errors"
+)
+
+func TestValidateProperties(t *testing.T) {
+	property := func(typ, value string) manifests.Property {
+		return manifests.Property{Type: typ, Value: json.RawMessage(value)}
+	}
+	tests := []struct {
+		name         string
+		properties   []manifests.Property
+		dependencies []*manifests.Dependency
+		want         []errors.Error
+	}{
+		{
+			name: "valid properties and dependencies",
+			properties: []manifests.Property{
+				property("olm.package", `{"packageName":"etcd","version":"0.9.4"}`),
+				property("olm.gvk", `{"group":"etcd.database.coreos.com","kind":"EtcdCluster","version":"v1beta2"}`),
+				property("olm.package.required", `{"packageName":"prometheus","versionRange":">=0.27.0 <0.30.0"}`),
+				property("olm.maxOpenShiftVersion", `"4.8"`),
+				property("olm.constraint", `{"all":{"constraints":[{"gvk":{"group":"cert-manager.io","kind":"Certificate","version":"v1"}},{"cel":{"rule":"properties.exists(p, p.type == 'certified')"}}]}}`),
+				property("example.com/custom", `{"any":"value"}`),
+			},
+			dependencies: []*manifests.Dependency{
+				{Type: "olm.package", Value: `{"packageName":"prometheus","version":">0.27.0"}`},
+				{Type: "olm.label", Value: `{"label":"beta"}`},
+			},
+		},
+		{
+			name: "invalid package versions",
+			properties: []manifests.Property{
+				property("olm.package", `{"packageName":"etcd","version":"v0.9"}`),
+				property("olm.package.required", `{"packageName":"","versionRange":"latest"}`),
+			},
+			want: []errors.Error{
+				errors.NewError(errors.ErrorInvalidBundle, `invalid olm.package property: version "v0.9" is not a valid semantic version: No Major.Minor.Patch elements found`, "properties[0]", "olm.package").
+					WithCode(errors.RuleBundlePropertyInvalid),
+				errors.NewError(errors.ErrorInvalidBundle, "invalid olm.package.required property: packageName is empty", "properties[1]", "olm.package.required").
+					WithCode(errors.RuleBundlePropertyInvalid),
+				errors.NewError(errors.ErrorInvalidBundle, `invalid olm.package.required property: versionRange "latest" is not a valid semantic version range: Could not get version from string: "latest"`, "properties[1]", "olm.package.required").
+					WithCode(errors.RuleBundlePropertyInvalid),
+			},
+		},
+		{
+			name: "malformed values",
+			properties: []manifests.Property{
+				property("", `{}`),
+				property("olm.gvk", `{"kind":"EtcdCluster","versions":"v1beta2"}`),
+			},
+			want: []errors.Error{
+				errors.NewError(errors.ErrorInvalidBundle, "property has no type", "properties[0]", "").
+					WithCode(errors.RuleBundlePropertyInvalid),
+				errors.NewError(errors.ErrorInvalidBundle, `invalid olm.gvk value: json: unknown field "versions"`, "properties[1]", "olm.gvk").
+					WithCode(errors.RuleBundlePropertyInvalid),
+			},
+		},
+		{
+			name: "invalid constraints",
+			properties: []manifests.Property{
+				property("olm.constraint", `{"failureMessage":"nothing"}`),
+				property("olm.constraint", `{"any":{"constraints":[{"package":{"packageName":"etcd","versionRange":"~>1"}},{"not":{"constraints":[]}}]}}`),
+				property("olm.constraint", fmt.Sprintf(`{"cel":{"rule":%q}}`, strings.Repeat("x", 2<<16))),
+			},
+			want: []errors.Error{
+				errors.NewError(errors.ErrorInvalidBundle, "invalid olm.constraint property: constraint must set exactly one of cel, package, gvk, all, any or not, found 0", "properties[0]", "olm.constraint").
+					WithCode(errors.RuleBundlePropertyInvalid),
+				errors.NewError(errors.ErrorInvalidBundle, `invalid olm.constraint property: any.constraints[0]: versionRange "~>1" is not a valid semantic version range: Could not parse Range "~>1": Could not parse comparator "~>" in "~>1"`, "properties[1]", "olm.constraint").
+					WithCode(errors.RuleBundlePropertyInvalid),
+				errors.NewError(errors.ErrorInvalidBundle, "invalid olm.constraint property: any.constraints[1]: not has no constraints", "properties[1]", "olm.constraint").
+					WithCode(errors.RuleBundlePropertyInvalid),
+				errors.NewError(errors.ErrorInvalidBundle, "olm.constraint value is greater than max constraint size 131072 bytes", "properties[2]", "olm.constraint").
+					WithCode(errors.RuleBundleConstraintTooLarge),
+			},
+		},
+		{
+			name: "unsatisfiable and redundant constraints",
+			properties: []manifests.Property{
+				property("olm.constraint", `{"all":{"constraints":[{"package":{"packageName":"foo","versionRange":">=2.0.0"}},{"not":{"constraints":[{"package":{"packageName":"foo","versionRange":">=1.0.0"}}]}}]}}`),
+				property("olm.constraint", `{"any":{"constraints":[{"gvk":{"group":"cert-manager.io","kind":"Certificate","version":"v1"}},{"gvk":{"group":"cert-manager.io","kind":"Certificate","version":"v1"}},{"package":{"packageName":"cert-manager","versionRange":">=1.0.0"}}]}}`),
+			},
+			dependencies: []*manifests.Dependency{
+				{Type: "olm.constraint", Value: `{"any":{"constraints":[{"package":{"packageName":"foo","versionRange":">=1.0.0"}},{"not":{"constraints":[{"package":{"packageName":"foo","versionRange":">=1.0.0"}}]}}]}}`},
+			},
+			want: []errors.Error{
+				errors.NewError(errors.ErrorInvalidBundle, "olm.constraint property: constraint can never be satisfied", "properties[0]", "olm.constraint").
+					WithCode(errors.RuleBundleConstraintUnsatisfiable),
+				errors.NewWarn(errors.ErrorInvalidBundle, "olm.constraint property: any.constraints[1]: duplicate of any.constraints[0]", "properties[1]", "olm.constraint").
+					WithCode(errors.RuleBundleConstraintRedundant),
+				errors.NewWarn(errors.ErrorInvalidBundle, "olm.constraint dependency: constraint is always satisfied", "dependencies[0]", "olm.constraint").
+					WithCode(errors.RuleBundleConstraintAlwaysSatisfied),
+			},
+		},
+		{
+			name: "invalid dependencies",
+			dependencies: []*manifests.Dependency{
+				{Type: "olm.package", Value: `{"packageName":"prometheus","version":"latest"}`},
+				{Type: "olm.gvk", Value: `{"group":"monitoring.coreos.com","kind":"Prometheus"}`},
+			},
+			want: []errors.Error{
+				errors.NewError(errors.ErrorInvalidBundle, `invalid olm.package dependency: version "latest" is not a valid semantic version range: Could not get version from string: "latest"`, "dependencies[0]", "olm.package").
+					WithCode(errors.RuleBundleDependencyInvalid),
+				errors.NewError(errors.ErrorInvalidBundle, "invalid olm.gvk dependency: version is empty", "dependencies[1]", "olm.gvk").
+					WithCode(errors.RuleBundleDependencyInvalid),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle := &manifests.Bundle{Properties: tt.properties, Dependencies: tt.dependencies}
+			require.Equal(t, tt.want, validateProperties(bundle))
+		})
+	}
+}
@@ -15,9 +15,9 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-// k8sVersionKey defines the key which can be used by its consumers
+// K8sVersionKey defines the key which can be used by its consumers
 // to inform what is the K8S version that should be used to do the tests against.
-const k8sVersionKey = "k8s-version"
+const K8sVersionKey = "k8s-version"
 
 // DeprecateMessage defines the content of the message that will be raised as an error or warning
 // when the removed apis are found
@@ -54,22 +54,14 @@ var AlphaDeprecatedAPIsValidator interfaces.Validator = interfaces.ValidatorFunc
 
 func validateDeprecatedAPIsValidator(objs ...interface{}) (results []errors.ManifestResult) {
 
-	// Obtain the k8s version if informed via the objects an optional
-	k8sVersion := ""
-	for _, obj := range objs {
-		switch obj.(type) {
-		case map[string]string:
-			k8sVersion = obj.(map[string]string)[k8sVersionKey]
-			if len(k8sVersion) > 0 {
-				break
-			}
-		}
-	}
+	// Obtain the k8s version if informed via the optional values
+	opts, errs := optionsFrom(objs, K8sVersionKey)
+	k8sVersion := opts.K8sVersion
 
 	for _, obj := range objs {
 		switch v := obj.(type) {
 		case *manifests.Bundle:
-			results = append(results, validateDeprecatedAPIs(v, k8sVersion))
+			results = append(results, withOptionsErrors(validateDeprecatedAPIs(v, k8sVersion), errs))
 		}
 	}
 
@@ -79,23 +71,23 @@ func validateDeprecatedAPIsValidator(objs ...interface{}) (results []errors.Mani
 func validateDeprecatedAPIs(bundle *manifests.Bundle, k8sVersion string) errors.ManifestResult {
 	result := errors.ManifestResult{}
 	if bundle == nil {
-		result.Add(errors.ErrInvalidBundle("Bundle is nil", nil))
+		result.Add(errors.ErrInvalidBundle("Bundle is nil", nil).WithCode(errors.RuleBundleMissing))
 		return result
 	}
 
 	result.Name = bundle.Name
 
 	if bundle.CSV == nil {
-		result.Add(errors.ErrInvalidBundle("Bundle csv is nil", bundle.Name))
+		result.Add(errors.ErrInvalidBundle("Bundle csv is nil", bundle.Name).WithCode(errors.RuleBundleMissing))
 		return result
 	}
 
 	errs, warns := validateDeprecatedAPIS(bundle, k8sVersion)
 	for _, err := range errs {
-		result.Add(errors.ErrFailedValidation(err.Error(), bundle.CSV.GetName()))
+		result.Add(errors.ErrFailedValidation(err.Error(), bundle.CSV.GetName()).WithCode(ruleOf(err)))
 	}
 	for _, warn := range warns {
-		result.Add(errors.WarnFailedValidation(warn.Error(), bundle.CSV.GetName()))
+		result.Add(errors.WarnFailedValidation(warn.Error(), bundle.CSV.GetName()).WithCode(ruleOf(warn)))
 	}
 
 	return result
@@ -117,7 +109,7 @@ func validateDeprecatedAPIS(bundle *manifests.Bundle, versionProvided string) (e
 	semVerVersionProvided, _ := semver.ParseTolerant(versionProvided)
 
 	if err := verifyK8sVersionInformed(versionProvided); err != nil && isVersionProvided {
-		errs = append(errs, err)
+		errs = append(errs, withRule(errors.RuleRemovedAPIsK8sVersionInvalid, err))
 	}
 
 	// Transform the spec minKubeVersion in semver Version to compare
@@ -125,8 +117,8 @@ func validateDeprecatedAPIS(bundle *manifests.Bundle, versionProvided string) (e
 	if len(bundle.CSV.Spec.MinKubeVersion) > 0 {
 		var err error
 		if semverMinKube, err = semver.ParseTolerant(bundle.CSV.Spec.MinKubeVersion); err != nil {
-			errs = append(errs, fmt.Errorf("unable to use csv.Spec.MinKubeVersion to verify the CRD/Webhook apis "+
-				"because it has an invalid value: %s", bundle.CSV.Spec.MinKubeVersion))
+			errs = append(errs, withRule(errors.RuleRemovedAPIsMinKubeVersionInvalid, fmt.Errorf("unable to use csv.Spec.MinKubeVersion to verify the CRD/Webhook apis "+
+				"because it has an invalid value: %s", bundle.CSV.Spec.MinKubeVersion)))
 		}
 	}
 
@@ -153,23 +145,27 @@ func checkRemovedAPIsForVersion(
 
 	found := map[string][]string{}
 	warnsFound := map[string][]string{}
+	var code errors.RuleCode
 	switch k8sVersionToCheck.String() {
 	case "1.22.0":
 		found = getRemovedAPIsOn1_22From(bundle)
+		code = errors.RuleRemovedAPIs1_22
 	case "1.25.0":
 		found, warnsFound = getRemovedAPIsOn1_25From(bundle)
+		code = errors.RuleRemovedAPIs1_25
 	case "1.26.0":
 		found = getRemovedAPIsOn1_26From(bundle)
+		code = errors.RuleRemovedAPIs1_26
 	default:
 		panic(fmt.Errorf("invalid internal call to check the removed apis with the version (%s) which is not supported", k8sVersionToCheck.String()))
 	}
 
 	if len(found) > 0 {
 		deprecatedAPIsMessage := generateMessageWithDeprecatedAPIs(found)
-		msg := fmt.Errorf(DeprecateMessage,
+		msg := withRule(code, fmt.Errorf(DeprecateMessage,
 			k8sVersionToCheck.Major, k8sVersionToCheck.Minor,
 			k8sVersionToCheck.Major, k8sVersionToCheck.Minor,
-			deprecatedAPIsMessage)
+			deprecatedAPIsMessage))
 		if isK8sVersionInformedEQ(semVerVersionProvided, k8sVersionToCheck, semverMinKube) {
 			// We only raise an error when the version >= 1.26 was informed via
 			// the k8s key/value option or is specifically defined in the CSV
@@ -181,10 +177,10 @@ func checkRemovedAPIsForVersion(
 
 	if len(warnsFound) > 0 {
 		deprecatedAPIsMessage := generateMessageWithDeprecatedAPIs(warnsFound)
-		msg := fmt.Errorf(DeprecateMessage,
+		msg := withRule(code, fmt.Errorf(DeprecateMessage,
 			k8sVersionToCheck.Major, k8sVersionToCheck.Minor,
 			k8sVersionToCheck.Major, k8sVersionToCheck.Minor,
-			deprecatedAPIsMessage)
+			deprecatedAPIsMessage))
 		warns = append(warns, msg)
 	}
 
@@ -215,12 +211,9 @@ func generateMessageWithDeprecatedAPIs(deprecatedAPIs map[string][]string) strin
 	}
 	sort.Strings(keys)
 
-	deprecatedAPIsSorted := make(map[string][]string)
-	for _, key := range keys {
-		deprecatedAPIsSorted[key] = deprecatedAPIs[key]
-	}
-
-	for k, v := range deprecatedAPIsSorted {
+	// Iterate over the sorted keys for the message to be deterministic.
+	for _, k := range keys {
+		v := deprecatedAPIs[k]
 		if count == len(deprecatedAPIs)-1 {
 			msg = msg + fmt.Sprintf("%s: (%+q)", k, v)
 		} else {
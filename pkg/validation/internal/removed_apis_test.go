@@ -21,6 +21,7 @@ func Test_GetRemovedAPIsOn1_22From(t *testing.T) {
 	otherKindsMock[PriorityClassKind] = []string{"super-priority"}
 	otherKindsMock[RoleKind] = []string{"memcached-role"}
 	otherKindsMock["MutatingWebhookConfiguration"] = []string{"mutating-webhook-configuration"}
+	otherKindsMock["ValidatingWebhookConfiguration"] = []string{"validating-webhook-configuration"}
 
 	type args struct {
 		bundleDir string
@@ -0,0 +1,73 @@
+package internal
+
+import (
+	goerrors "errors"
+
+	"github.com/operator-framework/api/pkg/validation/errors"
+)
+
+// ruleError associates an error found by a check, which accumulates plain
+// errors before they are converted into errors.Error, with its rule code and,
+// if the error can be fixed automatically, its fix.
+type ruleError struct {
+	code errors.RuleCode
+	fix  *errors.Fix
+	err  error
+}
+
+func (e ruleError) Error() string { return e.err.Error() }
+
+func (e ruleError) Unwrap() error { return e.err }
+
+// withRule returns err tagged with code, or nil if err is nil.
+func withRule(code errors.RuleCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	return ruleError{code: code, err: err}
+}
+
+// withFix returns err, which may be tagged with withRule, along with fix.
+func withFix(err error, fix errors.Fix) error {
+	var re ruleError
+	if !goerrors.As(err, &re) {
+		re = ruleError{err: err}
+	}
+	re.fix = &fix
+	return re
+}
+
+// ruleOf returns the rule code err was tagged with by withRule, if any.
+func ruleOf(err error) errors.RuleCode {
+	var re ruleError
+	if goerrors.As(err, &re) {
+		return re.code
+	}
+	return ""
+}
+
+// withRuleOf returns e with the rule code and fix err was tagged with, if any.
+func withRuleOf(e errors.Error, err error) errors.Error {
+	var re ruleError
+	if !goerrors.As(err, &re) {
+		return e
+	}
+	e = e.WithCode(re.code)
+	if re.fix != nil {
+		e = e.WithFix(*re.fix)
+	}
+	return e
+}
+
+// withFixTarget sets the object to patch of the fixes of errs, which were
+// found in the object of the given kind and name.
+func withFixTarget(errs []errors.Error, kind, name string) []errors.Error {
+	for i := range errs {
+		if errs[i].Fix != nil {
+			fix := *errs[i].Fix
+			fix.Kind, fix.Name = kind, name
+			errs[i].Fix = &fix
+		}
+	}
+	return errs
+}
@@ -0,0 +1,96 @@
+package internal
+
+import (
+	goerrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/validation/errors"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleOf(t *testing.T) {
+	err := withRule(errors.RuleGoodPracticesChannelNaming, goerrors.New("bad channel"))
+	require.EqualError(t, err, "bad channel")
+	require.Equal(t, errors.RuleGoodPracticesChannelNaming, ruleOf(err))
+	require.Equal(t, errors.RuleGoodPracticesChannelNaming, ruleOf(fmt.Errorf("wrapped: %w", err)))
+	require.Equal(t, errors.RuleCode(""), ruleOf(goerrors.New("no rule")))
+	require.Nil(t, withRule(errors.RuleGoodPracticesChannelNaming, nil))
+}
+
+func TestWithRuleOf(t *testing.T) {
+	fix := errors.Fix{Description: "remove the channel", Patch: []errors.PatchOperation{{Op: errors.PatchRemove, Path: "/spec/channel"}}}
+	err := withFix(withRule(errors.RuleGoodPracticesChannelNaming, goerrors.New("bad channel")), fix)
+	require.EqualError(t, err, "bad channel")
+	require.Equal(t, errors.RuleGoodPracticesChannelNaming, ruleOf(err))
+
+	e := withRuleOf(errors.WarnFailedValidation(err.Error(), "etcdoperator.v0.9.4"), err)
+	require.Equal(t, errors.WarnFailedValidation("bad channel", "etcdoperator.v0.9.4").WithCode(errors.RuleGoodPracticesChannelNaming).WithFix(fix), e)
+
+	targeted := withFixTarget([]errors.Error{e}, "ClusterServiceVersion", "etcdoperator.v0.9.4")
+	require.Equal(t, "ClusterServiceVersion", targeted[0].Fix.Kind)
+	require.Equal(t, "etcdoperator.v0.9.4", targeted[0].Fix.Name)
+	require.Empty(t, e.Fix.Kind)
+
+	require.Equal(t, errors.ErrFailedValidation("no rule", nil), withRuleOf(errors.ErrFailedValidation("no rule", nil), goerrors.New("no rule")))
+}
+
+// annotationCaseFix returns the fix of the wrong case annotation bad, found in the object of the given kind and name.
+func annotationCaseFix(kind, name, bad, good string) errors.Fix {
+	return errors.Fix{
+		Description: fmt.Sprintf("rename annotation %s to %s", bad, good),
+		Kind:        kind,
+		Name:        name,
+		Patch:       []errors.PatchOperation{{Op: errors.PatchMove, From: "/metadata/annotations/" + bad, Path: "/metadata/annotations/" + good}},
+	}
+}
+
+func TestRuleCodes(t *testing.T) {
+	bundleWithDeploymentSpecEmpty, err := manifests.GetBundleFromDir("./testdata/valid_bundle")
+	require.NoError(t, err)
+	bundleWithDeploymentSpecEmpty.CSV.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = nil
+	bundleWithMetadata, err := manifests.GetBundleFromDir("./testdata/bundle_with_metadata")
+	require.NoError(t, err)
+	bundleV1, err := manifests.GetBundleFromDir("./testdata/valid_bundle_v1")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		result    errors.ManifestResult
+		wantCodes []errors.RuleCode
+	}{
+		{
+			name:      "DeploymentMissing",
+			result:    validateGoodPracticesFrom(bundleWithDeploymentSpecEmpty),
+			wantCodes: []errors.RuleCode{errors.RuleGoodPracticesDeploymentMissing},
+		},
+		{
+			name:      "ChannelNaming",
+			result:    validateGoodPracticesFrom(bundleWithMetadata),
+			wantCodes: []errors.RuleCode{errors.RuleGoodPracticesChannelNaming},
+		},
+		{
+			name:      "ResourceRequests",
+			result:    validateGoodPracticesFrom(bundleV1),
+			wantCodes: []errors.RuleCode{errors.RuleGoodPracticesResourceRequests, errors.RuleGoodPracticesResourceRequests},
+		},
+		{
+			name:      "BundleMissing",
+			result:    validateBundleOperatorHubV2(nil, ""),
+			wantCodes: []errors.RuleCode{errors.RuleBundleMissing},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var codes []errors.RuleCode
+			for _, e := range append(tt.result.Errors, tt.result.Warnings...) {
+				_, ok := errors.LookupRule(e.Code)
+				require.True(t, ok, "code %q of %q is not in the catalog", e.Code, e.Detail)
+				codes = append(codes, e.Code)
+			}
+			require.ElementsMatch(t, tt.wantCodes, codes)
+		})
+	}
+}
@@ -35,10 +35,10 @@ func validateCapabilitiesBundle(bundle *manifests.Bundle) errors.ManifestResult
 
 	csvChecksResult := checkCapabilities(csvCategoryCheck)
 	for _, err := range csvChecksResult.errs {
-		result.Add(errors.ErrInvalidCSV(err.Error(), bundle.CSV.GetName()))
+		result.Add(errors.ErrInvalidCSV(err.Error(), bundle.CSV.GetName()).WithCode(ruleOf(err)))
 	}
 	for _, warn := range csvChecksResult.warns {
-		result.Add(errors.WarnInvalidCSV(warn.Error(), bundle.CSV.GetName()))
+		result.Add(errors.WarnInvalidCSV(warn.Error(), bundle.CSV.GetName()).WithCode(ruleOf(warn)))
 	}
 
 	return result
@@ -52,7 +52,7 @@ func checkCapabilities(checks CSVChecks) CSVChecks {
 
 	if capability, ok := checks.csv.ObjectMeta.Annotations["capabilities"]; ok {
 		if _, ok := validCapabilities[capability]; !ok {
-			checks.errs = append(checks.errs, fmt.Errorf("csv.Metadata.Annotations.Capabilities %q is not a valid capabilities level", capability))
+			checks.errs = append(checks.errs, withRule(errors.RuleCapabilitiesInvalid, fmt.Errorf("csv.Metadata.Annotations.Capabilities %q is not a valid capabilities level", capability)))
 		}
 	}
 	return checks
@@ -48,10 +48,10 @@ func validateCategoriesBundle(bundle *manifests.Bundle) errors.ManifestResult {
 
 	csvChecksResult := checkCategories(csvCategoryCheck)
 	for _, err := range csvChecksResult.errs {
-		result.Add(errors.ErrInvalidCSV(err.Error(), bundle.CSV.GetName()))
+		result.Add(errors.ErrInvalidCSV(err.Error(), bundle.CSV.GetName()).WithCode(ruleOf(err)))
 	}
 	for _, warn := range csvChecksResult.warns {
-		result.Add(errors.WarnInvalidCSV(warn.Error(), bundle.CSV.GetName()))
+		result.Add(errors.WarnInvalidCSV(warn.Error(), bundle.CSV.GetName()).WithCode(ruleOf(warn)))
 	}
 
 	return result
@@ -70,11 +70,11 @@ func checkCategories(checks CSVChecks) CSVChecks {
 		if customCategoriesPath != "" {
 			customCategories, err := extractCategories(customCategoriesPath)
 			if err != nil {
-				checks.errs = append(checks.errs, fmt.Errorf("could not extract custom categories from categories %#v: %s", customCategories, err))
+				checks.errs = append(checks.errs, withRule(errors.RuleCategoriesCustomFile, fmt.Errorf("could not extract custom categories from categories %#v: %s", customCategories, err)))
 			} else {
 				for _, category := range categorySlice {
 					if _, ok := customCategories[strings.TrimSpace(category)]; !ok {
-						checks.errs = append(checks.errs, fmt.Errorf("csv.Metadata.Annotations[\"categories\"] value %q is not in the set of custom categories", category))
+						checks.errs = append(checks.errs, withRule(errors.RuleCategoriesInvalid, fmt.Errorf("csv.Metadata.Annotations[\"categories\"] value %q is not in the set of custom categories", category)))
 					}
 				}
 			}
@@ -82,7 +82,7 @@ func checkCategories(checks CSVChecks) CSVChecks {
 			// use default categories
 			for _, category := range categorySlice {
 				if _, ok := validCategories[strings.TrimSpace(category)]; !ok {
-					checks.errs = append(checks.errs, fmt.Errorf("csv.Metadata.Annotations[\"categories\"] value %q is not in the set of standard categories", category))
+					checks.errs = append(checks.errs, withRule(errors.RuleCategoriesInvalid, fmt.Errorf("csv.Metadata.Annotations[\"categories\"] value %q is not in the set of standard categories", category)))
 				}
 			}
 		}
@@ -54,6 +54,6 @@ func updateResult(result *errors.ManifestResult, typeName string, newParentStruc
 		return
 	}
 	if !isOptionalField && newParentStructName != "Status" {
-		result.Add(errors.ErrFieldMissing("required field missing", newParentStructName, typeName))
+		result.Add(errors.ErrFieldMissing("required field missing", newParentStructName, typeName).WithCode(errors.RuleCSVRequiredFieldMissing))
 	}
 }
@@ -0,0 +1,343 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+
+	interfaces "github.com/operator-framework/api/pkg/validation/interfaces"
+	"github.com/operator-framework/api/pkg/validation/internal"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Stage describes the maturity of a registered Validator.
+type Stage string
+
+const (
+	// StageDefault validators define the spec of the manifests and should always be run.
+	StageDefault Stage = "default"
+	// StageOptional validators check for additional criteria and must be chosen explicitly.
+	StageOptional Stage = "optional"
+	// StageAlpha validators are optional and their checks may change between releases.
+	StageAlpha Stage = "alpha"
+	// StageDeprecated validators will be removed in a future release.
+	StageDeprecated Stage = "deprecated"
+)
+
+// Label keys set on every registered Validator, which can be used in label selectors.
+const (
+	// NameLabel is set to the ID of the Validator.
+	NameLabel = "name"
+	// StageLabel is set to the Stage of the Validator.
+	StageLabel = "stage"
+	// SuiteLabel is set to the suite the Validator belongs to, if any.
+	SuiteLabel = "suite"
+)
+
+// OperatorFrameworkSuite groups the optional validators which check the
+// criteria to distribute operators with the Operator Framework.
+const OperatorFrameworkSuite = "operator-framework"
+
+// suiteAliases maps the former names of suites to their name. Label selectors
+// on SuiteLabel still accept the former names.
+var suiteAliases = map[string]string{
+	"operatorframework": OperatorFrameworkSuite,
+}
+
+// Optional keys which can be passed to validators as a map[string]string object.
+const (
+	// K8sVersionKey is the Kubernetes version the bundle is intended to be used with.
+	K8sVersionKey = internal.K8sVersionKey
+	// ContainerToolsKey is the container tool used to inspect images, one of: docker, podman, none.
+	ContainerToolsKey = internal.ContainerToolsKey
+	// IndexImagePathKey is the path of the index image Dockerfile to be checked.
+	IndexImagePathKey = internal.IndexImagePathKey
+)
+
+// Object types handled by registered validators.
+const (
+	ObjectTypeBundle                   = "Bundle"
+	ObjectTypePackageManifest          = "PackageManifest"
+	ObjectTypeClusterServiceVersion    = "ClusterServiceVersion"
+	ObjectTypeCustomResourceDefinition = "CustomResourceDefinition"
+	ObjectTypeOperatorGroup            = "OperatorGroup"
+	ObjectTypeUnstructured             = "Unstructured"
+	ObjectTypeCatalog                  = "Catalog"
+)
+
+// ValidatorInfo describes a Validator registered in a Registry.
+type ValidatorInfo struct {
+	// ID is the stable, unique name of the Validator. IDs are lowercase words
+	// separated by hyphens, ex. "good-practices".
+	ID string
+	// Aliases are former IDs of the Validator, which Get still accepts.
+	Aliases []string
+	// Description is a short, human readable description of what is validated.
+	Description string
+	// Stage is the maturity of the Validator.
+	Stage Stage
+	// ObjectTypes are the types of objects the Validator handles. Other objects are ignored.
+	ObjectTypes []string
+	// OptionalKeys are the keys of the optional values accepted by the Validator.
+	OptionalKeys []string
+	// Labels are additional labels to select the Validator with, such as SuiteLabel.
+	Labels map[string]string
+	// Validator is the registered Validator.
+	Validator interfaces.Validator
+}
+
+// LabelSet returns the labels of v, including NameLabel and StageLabel.
+func (v ValidatorInfo) LabelSet() labels.Set {
+	set := labels.Set{}
+	for k, val := range v.Labels {
+		set[k] = val
+	}
+	set[NameLabel] = v.ID
+	set[StageLabel] = string(v.Stage)
+	return set
+}
+
+// Registry is a set of uniquely named Validator's and their metadata.
+// The zero value is an empty Registry ready to use.
+type Registry struct {
+	infos []ValidatorInfo
+}
+
+// NewRegistry returns a Registry containing infos, or an error if any of them
+// is invalid or registered more than once.
+func NewRegistry(infos ...ValidatorInfo) (*Registry, error) {
+	r := &Registry{}
+	for _, info := range infos {
+		if err := r.Register(info); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds info to r. An error is returned if info has no ID or
+// Validator, or if its ID or one of its aliases is already the ID or an alias
+// of a registered Validator.
+func (r *Registry) Register(info ValidatorInfo) error {
+	if info.ID == "" {
+		return fmt.Errorf("validator ID must be set")
+	}
+	if info.Validator == nil {
+		return fmt.Errorf("validator %q must be set", info.ID)
+	}
+	for _, id := range append([]string{info.ID}, info.Aliases...) {
+		if id == "" {
+			return fmt.Errorf("validator %q has an empty alias", info.ID)
+		}
+		if _, ok := r.Get(id); ok {
+			return fmt.Errorf("validator %q is already registered", id)
+		}
+	}
+	r.infos = append(r.infos, info)
+	return nil
+}
+
+// MustRegister adds infos to r like Register, and panics if one of them is
+// invalid or already registered.
+func (r *Registry) MustRegister(infos ...ValidatorInfo) {
+	for _, info := range infos {
+		if err := r.Register(info); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Get returns the ValidatorInfo registered with id, which can be one of its
+// aliases.
+func (r *Registry) Get(id string) (ValidatorInfo, bool) {
+	for _, info := range r.infos {
+		if info.ID == id {
+			return info, true
+		}
+		for _, alias := range info.Aliases {
+			if alias == id {
+				return info, true
+			}
+		}
+	}
+	return ValidatorInfo{}, false
+}
+
+// List returns all registered ValidatorInfo's sorted by ID.
+func (r *Registry) List() []ValidatorInfo {
+	infos := append([]ValidatorInfo{}, r.infos...)
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+// Select returns the ValidatorInfo's whose labels match selector, in
+// registration order. Former suite names are accepted in the requirements on
+// SuiteLabel.
+func (r *Registry) Select(selector labels.Selector) []ValidatorInfo {
+	selector = withSuiteAliases(selector)
+	var infos []ValidatorInfo
+	for _, info := range r.infos {
+		if selector.Matches(info.LabelSet()) {
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
+
+// SelectString parses selector as a label selector, ex. "suite=operator-framework",
+// and returns the matching ValidatorInfo's.
+func (r *Registry) SelectString(selector string) ([]ValidatorInfo, error) {
+	sel, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid validator selector %q: %v", selector, err)
+	}
+	return r.Select(sel), nil
+}
+
+// withSuiteAliases returns selector with the former suite names in its
+// requirements on SuiteLabel replaced by their name.
+func withSuiteAliases(selector labels.Selector) labels.Selector {
+	reqs, selectable := selector.Requirements()
+	if !selectable {
+		return selector
+	}
+	aliased := labels.NewSelector()
+	for _, req := range reqs {
+		if req.Key() == SuiteLabel {
+			values := req.Values().List()
+			for i, value := range values {
+				if name, ok := suiteAliases[value]; ok {
+					values[i] = name
+				}
+			}
+			// The requirement was already validated, so this cannot fail.
+			if r, err := labels.NewRequirement(req.Key(), req.Operator(), values); err == nil {
+				req = *r
+			}
+		}
+		aliased = aliased.Add(req)
+	}
+	return aliased
+}
+
+// Validators returns the Validator of each info, in order.
+func Validators(infos ...ValidatorInfo) interfaces.Validators {
+	validators := make(interfaces.Validators, 0, len(infos))
+	for _, info := range infos {
+		validators = append(validators, info.Validator)
+	}
+	return validators
+}
+
+// DefaultRegistry contains every Validator implemented by this library.
+var DefaultRegistry = &Registry{}
+
+func init() {
+	// Registering the validators checks that their IDs and aliases are unique.
+	DefaultRegistry.MustRegister(ValidatorInfo{
+		ID:          "package-manifest",
+		Aliases:     []string{"packagemanifest"},
+		Description: "Validates the channels of package manifests",
+		Stage:       StageDefault,
+		ObjectTypes: []string{ObjectTypePackageManifest},
+		Validator:   PackageManifestValidator,
+	}, ValidatorInfo{
+		ID:          "csv",
+		Description: "Validates the fields, install modes, examples and annotations of ClusterServiceVersions",
+		Stage:       StageDefault,
+		ObjectTypes: []string{ObjectTypeClusterServiceVersion},
+		Validator:   ClusterServiceVersionValidator,
+	}, ValidatorInfo{
+		ID:          "crd",
+		Description: "Validates CustomResourceDefinitions against the Kubernetes API server validation",
+		Stage:       StageDefault,
+		ObjectTypes: []string{ObjectTypeCustomResourceDefinition},
+		Validator:   CustomResourceDefinitionValidator,
+	}, ValidatorInfo{
+		ID:          "bundle",
+		Description: "Validates the owned CRDs, service accounts, size, name and related images of bundles",
+		Stage:       StageDefault,
+		ObjectTypes: []string{ObjectTypeBundle},
+		Validator:   BundleValidator,
+	}, ValidatorInfo{
+		ID:           "operatorhub-v2",
+		Aliases:      []string{"operatorhub/v2", "operatorhubv2"},
+		Description:  "Validates bundles against the criteria to publish on OperatorHub.io",
+		Stage:        StageOptional,
+		ObjectTypes:  []string{ObjectTypeBundle},
+		OptionalKeys: []string{K8sVersionKey},
+		Labels:       map[string]string{SuiteLabel: OperatorFrameworkSuite},
+		Validator:    OperatorHubV2Validator,
+	}, ValidatorInfo{
+		ID:          "standard-capabilities",
+		Aliases:     []string{"standardcapabilities"},
+		Description: "Validates the capabilities annotation of the bundle CSV",
+		Stage:       StageOptional,
+		ObjectTypes: []string{ObjectTypeBundle},
+		Labels:      map[string]string{SuiteLabel: OperatorFrameworkSuite},
+		Validator:   StandardCapabilitiesValidator,
+	}, ValidatorInfo{
+		ID:          "standard-categories",
+		Aliases:     []string{"standardcategories"},
+		Description: "Validates the categories annotation of the bundle CSV",
+		Stage:       StageOptional,
+		ObjectTypes: []string{ObjectTypeBundle},
+		Labels:      map[string]string{SuiteLabel: OperatorFrameworkSuite},
+		Validator:   StandardCategoriesValidator,
+	}, ValidatorInfo{
+		ID:          "good-practices",
+		Description: "Validates bundles against the criteria defined as good practices",
+		Stage:       StageOptional,
+		ObjectTypes: []string{ObjectTypeBundle},
+		Labels:      map[string]string{SuiteLabel: OperatorFrameworkSuite},
+		Validator:   GoodPracticesValidator,
+	}, ValidatorInfo{
+		ID:           "alpha-deprecated-apis",
+		Description:  "Validates that bundles do not use Kubernetes APIs which are deprecated or removed",
+		Stage:        StageAlpha,
+		ObjectTypes:  []string{ObjectTypeBundle},
+		OptionalKeys: []string{K8sVersionKey},
+		Labels:       map[string]string{SuiteLabel: OperatorFrameworkSuite},
+		Validator:    AlphaDeprecatedAPIsValidator,
+	}, ValidatorInfo{
+		ID:           "multiarch",
+		Description:  "Validates that the images of bundles support the architectures and OSes defined by the CSV labels",
+		Stage:        StageOptional,
+		ObjectTypes:  []string{ObjectTypeBundle},
+		OptionalKeys: []string{ContainerToolsKey},
+		Validator:    MultipleArchitecturesValidator,
+	}, ValidatorInfo{
+		ID:          "upgrade-graph",
+		Description: "Validates the upgrade graphs of the channels of packages built from their bundles, package manifest or catalog",
+		Stage:       StageOptional,
+		ObjectTypes: []string{ObjectTypeBundle, ObjectTypePackageManifest, ObjectTypeCatalog},
+		Validator:   UpgradeGraphValidator,
+	}, ValidatorInfo{
+		ID:          "object",
+		Description: "Validates PodDisruptionBudgets, PriorityClasses and RBAC objects shipped in bundles",
+		Stage:       StageOptional,
+		ObjectTypes: []string{ObjectTypeUnstructured},
+		Validator:   ObjectValidator,
+	}, ValidatorInfo{
+		ID:          "operator-group",
+		Aliases:     []string{"operatorgroup"},
+		Description: "Validates the annotations of OperatorGroups",
+		Stage:       StageOptional,
+		ObjectTypes: []string{ObjectTypeOperatorGroup},
+		Validator:   OperatorGroupValidator,
+	}, ValidatorInfo{
+		ID:           "operatorhub",
+		Description:  "Validates bundles against the criteria to publish on OperatorHub.io, superseded by operatorhub-v2, standard-capabilities and standard-categories",
+		Stage:        StageDeprecated,
+		ObjectTypes:  []string{ObjectTypeBundle},
+		OptionalKeys: []string{K8sVersionKey},
+		Validator:    OperatorHubValidator,
+	}, ValidatorInfo{
+		ID:           "community",
+		Description:  "Validates bundles against the criteria to publish in the community operators, moved to https://github.com/redhat-openshift-ecosystem/ocp-olm-catalog-validator",
+		Stage:        StageDeprecated,
+		ObjectTypes:  []string{ObjectTypeBundle},
+		OptionalKeys: []string{IndexImagePathKey},
+		Validator:    CommunityOperatorValidator,
+	})
+}
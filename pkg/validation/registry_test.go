@@ -0,0 +1,165 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/operator-framework/api/pkg/validation/errors"
+	interfaces "github.com/operator-framework/api/pkg/validation/interfaces"
+
+	"github.com/stretchr/testify/require"
+)
+
+var noopValidator = interfaces.ValidatorFunc(func(objs ...interface{}) []errors.ManifestResult { return nil })
+
+func TestRegistryRegister(t *testing.T) {
+	tests := []struct {
+		name    string
+		infos   []ValidatorInfo
+		wantErr string
+	}{
+		{
+			name:  "Valid",
+			infos: []ValidatorInfo{{ID: "a", Validator: noopValidator}, {ID: "b", Validator: noopValidator}},
+		},
+		{
+			name:    "MissingID",
+			infos:   []ValidatorInfo{{Validator: noopValidator}},
+			wantErr: "validator ID must be set",
+		},
+		{
+			name:    "MissingValidator",
+			infos:   []ValidatorInfo{{ID: "a"}},
+			wantErr: `validator "a" must be set`,
+		},
+		{
+			name:    "Duplicate",
+			infos:   []ValidatorInfo{{ID: "a", Validator: noopValidator}, {ID: "a", Validator: noopValidator}},
+			wantErr: `validator "a" is already registered`,
+		},
+		{
+			name:    "DuplicateAlias",
+			infos:   []ValidatorInfo{{ID: "a-b", Aliases: []string{"ab"}, Validator: noopValidator}, {ID: "ab", Validator: noopValidator}},
+			wantErr: `validator "ab" is already registered`,
+		},
+		{
+			name:    "EmptyAlias",
+			infos:   []ValidatorInfo{{ID: "a", Aliases: []string{""}, Validator: noopValidator}},
+			wantErr: `validator "a" has an empty alias`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRegistry(tt.infos...)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, r.List(), len(tt.infos))
+		})
+	}
+}
+
+func TestRegistryMustRegister(t *testing.T) {
+	r := &Registry{}
+	r.MustRegister(ValidatorInfo{ID: "a", Validator: noopValidator}, ValidatorInfo{ID: "b", Validator: noopValidator})
+	require.Len(t, r.List(), 2)
+	require.PanicsWithError(t, `validator "a" is already registered`, func() {
+		r.MustRegister(ValidatorInfo{ID: "a", Validator: noopValidator})
+	})
+}
+
+func TestRegistrySelect(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector string
+		wantIDs  []string
+		wantErr  bool
+	}{
+		{
+			name:     "Stage",
+			selector: "stage=default",
+			wantIDs:  []string{"package-manifest", "csv", "crd", "bundle"},
+		},
+		{
+			name:     "Suite",
+			selector: "suite=operator-framework",
+			wantIDs:  []string{"operatorhub-v2", "standard-capabilities", "standard-categories", "good-practices", "alpha-deprecated-apis"},
+		},
+		{
+			name:     "SuiteAlias",
+			selector: "suite=operatorframework",
+			wantIDs:  []string{"operatorhub-v2", "standard-capabilities", "standard-categories", "good-practices", "alpha-deprecated-apis"},
+		},
+		{
+			name:     "SuiteAliasNotIn",
+			selector: "stage=optional,suite notin (operatorframework)",
+			wantIDs:  []string{"multiarch", "upgrade-graph", "object", "operator-group"},
+		},
+		{
+			name:     "Name",
+			selector: "name=multiarch",
+			wantIDs:  []string{"multiarch"},
+		},
+		{
+			name:     "Deprecated",
+			selector: "stage=deprecated",
+			wantIDs:  []string{"operatorhub", "community"},
+		},
+		{
+			name:     "Invalid",
+			selector: "stage in",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			infos, err := DefaultRegistry.SelectString(tt.selector)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var ids []string
+			for _, info := range infos {
+				ids = append(ids, info.ID)
+			}
+			require.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	seen := map[string]struct{}{}
+	for _, info := range DefaultRegistry.List() {
+		require.NotEmpty(t, info.Description, "validator %q", info.ID)
+		require.NotEmpty(t, info.ObjectTypes, "validator %q", info.ID)
+		require.NotNil(t, info.Validator, "validator %q", info.ID)
+		require.Subset(t, OptionKeys(), info.OptionalKeys, "validator %q", info.ID)
+		_, dup := seen[info.ID]
+		require.False(t, dup, "validator %q registered twice", info.ID)
+		seen[info.ID] = struct{}{}
+	}
+
+	// AllValidators plus the deprecated OperatorHubValidator.
+	require.Len(t, Validators(DefaultRegistry.List()...), len(AllValidators)+1)
+
+	info, ok := DefaultRegistry.Get("alpha-deprecated-apis")
+	require.True(t, ok)
+	require.Equal(t, StageAlpha, info.Stage)
+	require.Equal(t, []string{K8sVersionKey}, info.OptionalKeys)
+
+	// The former IDs of the validators are still accepted.
+	for alias, id := range map[string]string{
+		"operatorhub/v2":       "operatorhub-v2",
+		"operatorhubv2":        "operatorhub-v2",
+		"standardcapabilities": "standard-capabilities",
+		"standardcategories":   "standard-categories",
+		"packagemanifest":      "package-manifest",
+		"operatorgroup":        "operator-group",
+	} {
+		info, ok := DefaultRegistry.Get(alias)
+		require.True(t, ok, alias)
+		require.Equal(t, id, info.ID)
+	}
+}
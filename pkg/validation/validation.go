@@ -5,6 +5,11 @@
 // Each default Validator runs an independent set of validation functions on
 // a set of objects. To run all implemented Validator's, use AllValidators.
 // The Validator will not be run on objects of an inappropriate type.
+// Every Validator is also registered in DefaultRegistry along with its ID,
+// stage and accepted optional keys, so it can be looked up at runtime.
+//
+// When a *manifests.Bundle loaded from manifest files is among the objects,
+// the Validators set the Position of their findings in those files.
 
 package validation
 
@@ -14,44 +19,44 @@ import (
 )
 
 // PackageManifestValidator implements Validator to validate package manifests.
-var PackageManifestValidator = internal.PackageManifestValidator
+var PackageManifestValidator = internal.WithPositions(internal.PackageManifestValidator)
 
 // ClusterServiceVersionValidator implements Validator to validate
 // ClusterServiceVersions.
-var ClusterServiceVersionValidator = internal.CSVValidator
+var ClusterServiceVersionValidator = internal.WithPositions(internal.CSVValidator)
 
 // CustomResourceDefinitionValidator implements Validator to validate
 // CustomResourceDefinitions.
-var CustomResourceDefinitionValidator = internal.CRDValidator
+var CustomResourceDefinitionValidator = internal.WithPositions(internal.CRDValidator)
 
 // BundleValidator implements Validator to validate Bundles.
-var BundleValidator = internal.BundleValidator
+var BundleValidator = internal.WithPositions(internal.BundleValidator)
 
 // OperatorHubValidator implements Validator to validate bundle objects
 // for OperatorHub.io requirements.
 //
 // Deprecated: Use OperatorHubV2Validator, StandardCapabilitiesValidator
 // and StandardCategoriesValidator for equivalent functionality.
-var OperatorHubValidator = internal.OperatorHubValidator
+var OperatorHubValidator = internal.WithPositions(internal.OperatorHubValidator)
 
 // OperatorHubV2Validator implements Validator to validate bundle objects
 // for OperatorHub.io requirements.
-var OperatorHubV2Validator = internal.OperatorHubV2Validator
+var OperatorHubV2Validator = internal.WithPositions(internal.OperatorHubV2Validator)
 
 // StandardCapabilitiesValidator implements Validator to validate bundle objects
 // for OperatorHub.io requirements around UI capability metadata
-var StandardCapabilitiesValidator = internal.StandardCapabilitiesValidator
+var StandardCapabilitiesValidator = internal.WithPositions(internal.StandardCapabilitiesValidator)
 
 // StandardCategoriesValidator implements Validator to validate bundle objects
 // for OperatorHub.io requirements around UI category metadata
-var StandardCategoriesValidator = internal.StandardCategoriesValidator
+var StandardCategoriesValidator = internal.WithPositions(internal.StandardCategoriesValidator)
 
 // Object Validator validates various custom objects in the bundle like PDBs and SCCs.
 // Object validation is optional and not a default-level validation.
-var ObjectValidator = internal.ObjectValidator
+var ObjectValidator = internal.WithPositions(internal.ObjectValidator)
 
 // OperatorGroupValidator implements Validator to validate OperatorGroup manifests
-var OperatorGroupValidator = internal.OperatorGroupValidator
+var OperatorGroupValidator = internal.WithPositions(internal.OperatorGroupValidator)
 
 // CommunityOperatorValidator implements Validator to validate bundle objects
 // for the Community Operator requirements.
@@ -59,7 +64,7 @@ var OperatorGroupValidator = internal.OperatorGroupValidator
 // Deprecated - The checks made for this validator were moved to the external one:
 // https://github.com/redhat-openshift-ecosystem/ocp-olm-catalog-validator.
 // Please no longer use this check it will be removed in the next releases.
-var CommunityOperatorValidator = internal.CommunityOperatorValidator
+var CommunityOperatorValidator = internal.WithPositions(internal.CommunityOperatorValidator)
 
 // AlphaDeprecatedAPIsValidator implements Validator to validate bundle objects
 // for API deprecation requirements.
@@ -69,14 +74,41 @@ var CommunityOperatorValidator = internal.CommunityOperatorValidator
 //
 // This validator only raises an error when the deprecated API found is removed in the specified k8s
 // version informed via the optional key `k8s-version`.
-var AlphaDeprecatedAPIsValidator = internal.AlphaDeprecatedAPIsValidator
+var AlphaDeprecatedAPIsValidator = internal.WithPositions(internal.AlphaDeprecatedAPIsValidator)
 
 // GoodPracticesValidator implements Validator to validate the criteria defined as good practices
-var GoodPracticesValidator = internal.GoodPracticesValidator
+var GoodPracticesValidator = internal.WithPositions(internal.GoodPracticesValidator)
 
 // MultipleArchitecturesValidator implements Validator to validate MultipleArchitectures configuration. For further
 // information check: https://olm.operatorframework.io/docs/advanced-tasks/ship-operator-supporting-multiarch/
-var MultipleArchitecturesValidator = internal.MultipleArchitecturesValidator
+//
+// It implements ContextValidator: the container tool commands inspecting the images are killed once the
+// context passed to ValidateContext is done.
+var MultipleArchitecturesValidator = internal.WithPositions(internal.MultipleArchitecturesValidator)
+
+// UpgradeGraphValidator implements Validator to validate the upgrade graphs of the channels of
+// packages, built from their bundles, package manifest or file-based catalog. It reports cycles,
+// bundles which cannot be upgraded to the head of their channel, channels with several heads,
+// replaces of bundles which are not in the channel and skip ranges which are invalid or match no bundle.
+var UpgradeGraphValidator = internal.WithPositions(internal.UpgradeGraphValidator)
+
+// Options are the typed optional values accepted by the validators. They are passed among the
+// objects to validate, and replace the map[string]string of optional values, which is still accepted.
+// Validators report unknown keys of such maps and invalid values of the options they use as errors.
+type Options = internal.Options
+
+// ParseOptions converts optional values keyed by K8sVersionKey, ContainerToolsKey and IndexImagePathKey,
+// e.g. the key=value pairs given on a command line, to Options. An error is returned for unknown keys
+// and invalid values.
+var ParseOptions = internal.ParseOptions
+
+// OptionKeys returns the keys of the optional values accepted by the validators.
+var OptionKeys = internal.OptionKeys
+
+// AttachPositions sets the Position of the findings of the results of validating a bundle to the
+// file, line and column they were found at in the manifests the bundle was loaded from. The
+// Validators of this package already do so; it is meant for the results of other validators.
+var AttachPositions = internal.AttachPositions
 
 // AllValidators implements Validator to validate all Operator manifest types.
 var AllValidators = interfaces.Validators{
@@ -93,6 +125,7 @@ var AllValidators = interfaces.Validators{
 	AlphaDeprecatedAPIsValidator,
 	GoodPracticesValidator,
 	MultipleArchitecturesValidator,
+	UpgradeGraphValidator,
 }
 
 var DefaultBundleValidators = interfaces.Validators{
@@ -1,10 +1,12 @@
 package validation
 
 import (
+	"context"
 	"testing"
 
 	"github.com/operator-framework/api/pkg/manifests"
 	"github.com/operator-framework/api/pkg/validation/errors"
+	interfaces "github.com/operator-framework/api/pkg/validation/interfaces"
 
 	"github.com/stretchr/testify/require"
 )
@@ -59,5 +61,31 @@ func TestValidatePackageError(t *testing.T) {
 	require.True(t, results[0].HasError())
 	pkgErrs := results[0].Errors
 	require.Equal(t, 1, len(pkgErrs))
-	require.Equal(t, errors.ErrInvalidPackageManifest("packageName empty", pkg.PackageName), pkgErrs[0])
+	require.Equal(t, errors.ErrInvalidPackageManifest("packageName empty", pkg.PackageName).WithCode(errors.RulePackageManifestNameMissing), pkgErrs[0])
+}
+
+func TestRunnerMatchesValidate(t *testing.T) {
+	var sets [][]interface{}
+	for _, dir := range []string{
+		"./testdata/valid_bundle",
+		"./testdata/invalid_bundle",
+		"./internal/testdata/valid_bundle_v1beta1",
+		"./internal/testdata/bundle_with_deprecated_resources",
+	} {
+		bundle, err := manifests.GetBundleFromDir(dir)
+		require.NoError(t, err)
+		sets = append(sets, append(bundle.ObjectsToValidate(), map[string]string{"k8s-version": "1.22"}))
+	}
+
+	validators := append(interfaces.Validators{
+		OperatorHubV2Validator,
+		ObjectValidator,
+		AlphaDeprecatedAPIsValidator,
+		GoodPracticesValidator,
+	}, DefaultBundleValidators...)
+	results := interfaces.Runner{Validators: validators, Workers: 4}.Run(context.Background(), sets...)
+	require.Len(t, results, len(sets))
+	for i, set := range sets {
+		require.Equal(t, validators.Validate(set...), results[i])
+	}
 }